@@ -0,0 +1,161 @@
+// Package amm quotes swaps against automated market maker pools in integer
+// token units, following the arithmetic and rounding of the pool contracts:
+//
+//   - UniswapV2: constant product x·y=k with a fee on the input (Uniswap v2 and forks)
+//   - StableSwap: Curve's stableswap invariant, solved by Newton iteration
+//   - Weighted: Balancer v2 weighted pools, with powers evaluated by LogExpMath
+//
+// Each pool repeats the contracts' integer steps in order, so it rounds where
+// the contracts round.
+//
+// Amounts and balances are *big.Int in base units of each token, e.g. wei or
+// the 6-decimal units of USDC. Quotes never modify a pool or its arguments.
+//
+// Example:
+//
+//	pool := &amm.UniswapV2{Reserve0: usdc, Reserve1: weth}
+//	out, _ := pool.AmountOut(0, 1, amountIn)
+//	impact, _ := amm.PriceImpact(pool, 0, 1, amountIn) // 0.0042 is 0.42%
+package amm
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/morpheum-labs/safem"
+)
+
+var (
+	ErrInsufficientAmount    = errors.New("insufficient amount")
+	ErrInsufficientLiquidity = errors.New("insufficient liquidity")
+	ErrNoConvergence         = errors.New("invariant did not converge")
+	ErrMaxSwapRatio          = errors.New("amount exceeds max swap ratio")
+)
+
+// Precision is the number of decimal places of spot prices and price impacts.
+const Precision = 36
+
+// Pool quotes swaps between the tokens of a pool. Tokens are identified by their
+// index in the pool.
+type Pool interface {
+	// AmountOut returns the amount of token out received for amountIn of token
+	// in, after fees.
+	AmountOut(in, out int, amountIn *big.Int) (*big.Int, error)
+
+	// AmountIn returns the amount of token in to pay, fees included, to receive
+	// amountOut of token out.
+	AmountIn(in, out int, amountOut *big.Int) (*big.Int, error)
+
+	// SpotPrice returns the marginal price of one base unit of token in in base
+	// units of token out, excluding fees.
+	SpotPrice(in, out int) (safem.Decimal, error)
+}
+
+// PriceImpact returns the relative difference between the amount out of a swap
+// of amountIn and the amount amountIn would buy at the spot price:
+//
+//	impact = (amountIn * spot - amountOut) / (amountIn * spot)
+//
+// Since the spot price excludes fees, the impact includes the pool's fee, as in
+// the Uniswap SDK. The result is rounded to Precision decimal places.
+//
+// Example:
+//
+//	// 1 WETH into a 5/10 WETH pool with a 0.3% fee
+//	PriceImpact(pool, 0, 1, oneEther) // output: 0.168751042187760547
+func PriceImpact(pool Pool, in, out int, amountIn *big.Int) (safem.Decimal, error) {
+	amountOut, err := pool.AmountOut(in, out, amountIn)
+	if err != nil {
+		return safem.Decimal{}, err
+	}
+	return priceImpact(pool, in, out, amountIn, amountOut)
+}
+
+// PriceImpactExactOut is PriceImpact for a swap that buys exactly amountOut.
+func PriceImpactExactOut(pool Pool, in, out int, amountOut *big.Int) (safem.Decimal, error) {
+	amountIn, err := pool.AmountIn(in, out, amountOut)
+	if err != nil {
+		return safem.Decimal{}, err
+	}
+	return priceImpact(pool, in, out, amountIn, amountOut)
+}
+
+func priceImpact(pool Pool, in, out int, amountIn, amountOut *big.Int) (safem.Decimal, error) {
+	spot, err := pool.SpotPrice(in, out)
+	if err != nil {
+		return safem.Decimal{}, err
+	}
+	quoted := spot.Mul(safem.NewFromBigInt(amountIn, 0))
+	if quoted.Sign() == 0 {
+		return safem.Decimal{}, fmt.Errorf("cannot compute price impact: zero spot price: %w", ErrInsufficientLiquidity)
+	}
+	return quoted.Sub(safem.NewFromBigInt(amountOut, 0)).DivRound(quoted, Precision), nil
+}
+
+// amount returns ErrInvalidInput when x is nil, ErrNegativeInput when x is
+// negative and ErrInsufficientAmount when x is zero.
+func amount(name string, x *big.Int) error {
+	if x == nil {
+		return fmt.Errorf("cannot quote: %s is nil: %w", name, safem.ErrInvalidInput)
+	}
+	if x.Sign() < 0 {
+		return fmt.Errorf("cannot quote: %s is negative: %w", name, safem.ErrNegativeInput)
+	}
+	if x.Sign() == 0 {
+		return fmt.Errorf("cannot quote: %s is zero: %w", name, ErrInsufficientAmount)
+	}
+	return nil
+}
+
+// balances returns ErrInvalidInput when a balance is nil or there are fewer
+// than two, ErrNegativeInput when one is negative and ErrInsufficientLiquidity
+// when one is zero.
+func balances(xs []*big.Int) error {
+	if len(xs) < 2 {
+		return fmt.Errorf("cannot quote: %d tokens: %w", len(xs), safem.ErrInvalidInput)
+	}
+	for i, x := range xs {
+		if x == nil {
+			return fmt.Errorf("cannot quote: balance %d is nil: %w", i, safem.ErrInvalidInput)
+		}
+		if x.Sign() < 0 {
+			return fmt.Errorf("cannot quote: balance %d is negative: %w", i, safem.ErrNegativeInput)
+		}
+		if x.Sign() == 0 {
+			return fmt.Errorf("cannot quote: balance %d is zero: %w", i, ErrInsufficientLiquidity)
+		}
+	}
+	return nil
+}
+
+// pair returns ErrInvalidInput unless in and out are distinct indexes of a pool
+// of n tokens.
+func pair(in, out, n int) error {
+	if in < 0 || in >= n || out < 0 || out >= n || in == out {
+		return fmt.Errorf("cannot quote token %d for token %d of %d: %w", in, out, n, safem.ErrInvalidInput)
+	}
+	return nil
+}
+
+// pow10 returns 10^n.
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// scales returns 10^(18 - decimals) for each token, the factor that brings its
+// amounts to 18 decimals. Returns ErrInvalidInput when there is not one decimals
+// per token or a token has more than 18.
+func scales(decimals []uint8, n int) ([]*big.Int, error) {
+	if len(decimals) != n {
+		return nil, fmt.Errorf("cannot quote: %d decimals for %d tokens: %w", len(decimals), n, safem.ErrInvalidInput)
+	}
+	s := make([]*big.Int, n)
+	for i, d := range decimals {
+		if d > 18 {
+			return nil, fmt.Errorf("cannot quote: token %d has %d decimals: %w", i, d, safem.ErrInvalidInput)
+		}
+		s[i] = pow10(18 - int(d))
+	}
+	return s, nil
+}
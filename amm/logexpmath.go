@@ -0,0 +1,203 @@
+package amm
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/morpheum-labs/safem"
+)
+
+// This file ports Balancer v2's LogExpMath library (solidity-utils), which
+// evaluates x^y as exp(y * ln(x)) on 18-decimal fixed-point numbers. Every
+// step is integer arithmetic and Solidity's division truncates towards zero, as
+// big.Int's Quo and Rem do, so results match the contract to the wei.
+
+func bigFromString(s string) *big.Int {
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("amm: invalid constant " + s)
+	}
+	return v
+}
+
+var (
+	one20 = pow10(20)
+	one36 = pow10(36)
+
+	maxNaturalExponent = new(big.Int).Mul(big.NewInt(130), one18)
+	minNaturalExponent = new(big.Int).Mul(big.NewInt(-41), one18)
+
+	// ln36LowerBound and ln36UpperBound bound the bases whose logarithm is
+	// computed with 36 decimals, 0.9 and 1.1.
+	ln36LowerBound = new(big.Int).Sub(one18, pow10(17))
+	ln36UpperBound = new(big.Int).Add(one18, pow10(17))
+
+	// mildExponentBound is 2^254 / ONE_20, the bound on y that keeps
+	// y * ln(x) within 256 bits.
+	mildExponentBound = new(big.Int).Quo(new(big.Int).Lsh(big.NewInt(1), 254), one20)
+
+	// x0 and x1 are 2^7 and 2^6 with 18 decimals; a0 and a1 are e^x0 and e^x1
+	// with no decimals.
+	x0 = bigFromString("128000000000000000000")
+	a0 = bigFromString("38877084059945950922200000000000000000000000000000000000")
+	x1 = bigFromString("64000000000000000000")
+	a1 = bigFromString("6235149080811616882910000000")
+
+	// expTerms holds x2 to x11, 2^5 to 2^-4, and their exponentials a2 to a11,
+	// all with 20 decimals.
+	expTerms = []struct{ x, a *big.Int }{
+		{bigFromString("3200000000000000000000"), bigFromString("7896296018268069516100000000000000")},
+		{bigFromString("1600000000000000000000"), bigFromString("888611052050787263676000000")},
+		{bigFromString("800000000000000000000"), bigFromString("298095798704172827474000")},
+		{bigFromString("400000000000000000000"), bigFromString("5459815003314423907810")},
+		{bigFromString("200000000000000000000"), bigFromString("738905609893065022723")},
+		{bigFromString("100000000000000000000"), bigFromString("271828182845904523536")},
+		{bigFromString("50000000000000000000"), bigFromString("164872127070012814685")},
+		{bigFromString("25000000000000000000"), bigFromString("128402541668774148407")},
+		{bigFromString("12500000000000000000"), bigFromString("113314845306682631683")},
+		{bigFromString("6250000000000000000"), bigFromString("106449445891785942956")},
+	}
+)
+
+// logExpPow returns x^y as LogExpMath.pow, for 18-decimal x and y. Returns
+// ErrInvalidInput where the contract reverts: x of 2^255 or more
+// (X_OUT_OF_BOUNDS), y too large (Y_OUT_OF_BOUNDS) or y * ln(x) outside
+// [-41, 130] (PRODUCT_OUT_OF_BOUNDS).
+func logExpPow(x, y *big.Int) (*big.Int, error) {
+	if y.Sign() == 0 {
+		// 0^0 is one
+		return new(big.Int).Set(one18), nil
+	}
+	if x.Sign() == 0 {
+		return new(big.Int), nil
+	}
+	if x.BitLen() > 255 {
+		return nil, fmt.Errorf("cannot compute power: base %s out of bounds: %w", x, safem.ErrInvalidInput)
+	}
+	if y.Cmp(mildExponentBound) >= 0 {
+		return nil, fmt.Errorf("cannot compute power: exponent %s out of bounds: %w", y, safem.ErrInvalidInput)
+	}
+
+	var logxTimesY *big.Int
+	if ln36LowerBound.Cmp(x) < 0 && x.Cmp(ln36UpperBound) < 0 {
+		ln36x := natLog36(x)
+		// two 18-decimal products: the first 18 decimals of ln36x and the rest
+		q, r := new(big.Int).QuoRem(ln36x, one18, new(big.Int))
+		logxTimesY = q.Mul(q, y)
+		r.Mul(r, y).Quo(r, one18)
+		logxTimesY.Add(logxTimesY, r)
+	} else {
+		logxTimesY = natLog(x)
+		logxTimesY.Mul(logxTimesY, y)
+	}
+	logxTimesY.Quo(logxTimesY, one18)
+
+	if logxTimesY.Cmp(minNaturalExponent) < 0 || logxTimesY.Cmp(maxNaturalExponent) > 0 {
+		return nil, fmt.Errorf("cannot compute power: product %s out of bounds: %w", logxTimesY, safem.ErrInvalidInput)
+	}
+	return natExp(logxTimesY), nil
+}
+
+// natExp returns e^x as LogExpMath.exp, for 18-decimal x in [-41, 130].
+func natExp(x *big.Int) *big.Int {
+	if x.Sign() < 0 {
+		// e^(-x) = 1 / e^x
+		r := new(big.Int).Mul(one18, one18)
+		return r.Quo(r, natExp(new(big.Int).Neg(x)))
+	}
+	x = new(big.Int).Set(x)
+
+	firstAN := big.NewInt(1)
+	if x.Cmp(x0) >= 0 {
+		x.Sub(x, x0)
+		firstAN = a0
+	} else if x.Cmp(x1) >= 0 {
+		x.Sub(x, x1)
+		firstAN = a1
+	}
+
+	// 20 decimals from here on
+	x.Mul(x, big.NewInt(100))
+
+	// x10 and x11 are not needed: the series below is precise enough
+	product := new(big.Int).Set(one20)
+	for _, t := range expTerms[:len(expTerms)-2] {
+		if x.Cmp(t.x) >= 0 {
+			x.Sub(x, t.x)
+			product.Mul(product, t.a).Quo(product, one20)
+		}
+	}
+
+	// Taylor series 1 + x + x^2/2! + ... + x^12/12!
+	seriesSum := new(big.Int).Add(one20, x)
+	term := new(big.Int).Set(x)
+	for n := int64(2); n <= 12; n++ {
+		term.Mul(term, x).Quo(term, one20).Quo(term, big.NewInt(n))
+		seriesSum.Add(seriesSum, term)
+	}
+
+	r := product.Mul(product, seriesSum)
+	r.Quo(r, one20).Mul(r, firstAN)
+	return r.Quo(r, big.NewInt(100))
+}
+
+// natLog returns ln(a) as LogExpMath._ln, for 18-decimal a > 0.
+func natLog(a *big.Int) *big.Int {
+	if a.Cmp(one18) < 0 {
+		// ln(a) = -ln(1/a)
+		inv := new(big.Int).Mul(one18, one18)
+		r := natLog(inv.Quo(inv, a))
+		return r.Neg(r)
+	}
+	a = new(big.Int).Set(a)
+
+	// a0 and a1 have no decimals, so these are integer divisions
+	sum := new(big.Int)
+	if a.Cmp(new(big.Int).Mul(a0, one18)) >= 0 {
+		a.Quo(a, a0)
+		sum.Add(sum, x0)
+	}
+	if a.Cmp(new(big.Int).Mul(a1, one18)) >= 0 {
+		a.Quo(a, a1)
+		sum.Add(sum, x1)
+	}
+
+	// 20 decimals from here on
+	sum.Mul(sum, big.NewInt(100))
+	a.Mul(a, big.NewInt(100))
+	for _, t := range expTerms {
+		if a.Cmp(t.a) >= 0 {
+			a.Mul(a, one20).Quo(a, t.a)
+			sum.Add(sum, t.x)
+		}
+	}
+
+	// ln(a) = 2 * (z + z^3/3 + ... + z^11/11) with z = (a - 1) / (a + 1)
+	seriesSum := series(a, one20, 11)
+	return sum.Add(sum, seriesSum).Quo(sum, big.NewInt(100))
+}
+
+// natLog36 returns ln(x) with 36 decimals as LogExpMath._ln_36, for 18-decimal
+// x close to one.
+func natLog36(x *big.Int) *big.Int {
+	// ln(x) = 2 * (z + z^3/3 + ... + z^15/15) with z = (x - 1) / (x + 1)
+	return series(new(big.Int).Mul(x, one18), one36, 15)
+}
+
+// series returns 2 * (z + z^3/3 + ... + z^last/last) for z = (a - one) / (a + one),
+// with a and the result fixed point with unit one.
+func series(a, one *big.Int, last int64) *big.Int {
+	z := new(big.Int).Sub(a, one)
+	z.Mul(z, one).Quo(z, new(big.Int).Add(a, one))
+	zSquared := new(big.Int).Mul(z, z)
+	zSquared.Quo(zSquared, one)
+
+	num := new(big.Int).Set(z)
+	seriesSum := new(big.Int).Set(z)
+	t := new(big.Int)
+	for n := int64(3); n <= last; n += 2 {
+		num.Mul(num, zSquared).Quo(num, one)
+		seriesSum.Add(seriesSum, t.Quo(num, big.NewInt(n)))
+	}
+	return seriesSum.Lsh(seriesSum, 1)
+}
@@ -0,0 +1,104 @@
+package amm
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/morpheum-labs/safem"
+)
+
+func TestLogExpPow(t *testing.T) {
+	// Balancer's own tests only bound the relative error; the wei values below
+	// come from a separate transcription of LogExpMath with exact integer
+	// division, each within 10^-16 of the exact power.
+	tests := []struct {
+		x, y     string
+		expected string
+	}{
+		// exponent zero, base zero and base one, as in Balancer's LogExpMath tests
+		{"0", "0", "1000000000000000000"},
+		{"2000000000000000000", "0", "1000000000000000000"},
+		{"0", "1000000000000000000", "0"},
+		{"1000000000000000000", "500000000000000000", "1000000000000000000"},
+		// ln_36 branch, 0.9 < x < 1.1
+		{"909090909090909091", "250000000000000000", "976454089676310546"},
+		{"1050000000000000000", "1500000000000000000", "1075929830425757829"},
+		{"950000000000000000", "2500000000000000000", "879648189619008993"},
+		// _ln branch
+		{"2000000000000000000", "500000000000000000", "1414213562373095047"},
+		{"500000000000000000", "3000000000000000000", "125000000000000000"},
+		{"123456789000000000000", "750000000000000000", "37037036784259258939"},
+		{"3000000000000000000", "30000000000000000000", "205891132094648997509590755106687"},
+	}
+	for _, tt := range tests {
+		x, _ := new(big.Int).SetString(tt.x, 10)
+		y, _ := new(big.Int).SetString(tt.y, 10)
+		got, err := logExpPow(x, y)
+		if err != nil || got.String() != tt.expected {
+			t.Errorf("logExpPow(%s, %s): Expected %s, got %v %v", tt.x, tt.y, tt.expected, got, err)
+		}
+	}
+}
+
+func TestLogExpPowRelativeError(t *testing.T) {
+	// FixedPoint relies on pow being within MAX_POW_RELATIVE_ERROR, 10^-14
+	bound := safem.New(1, -14)
+	for _, tt := range []struct{ x, y string }{
+		{"0.000001", "0.25"},
+		{"0.3", "1.7"},
+		{"0.99", "0.123456789"},
+		{"1.000000000000000001", "1000"},
+		{"7.5", "0.8"},
+		{"1000000", "3.3"},
+	} {
+		x, y := safem.RequireFromString(tt.x), safem.RequireFromString(tt.y)
+		got, err := logExpPow(x.Shift(18).BigInt(), y.Shift(18).BigInt())
+		if err != nil {
+			t.Errorf("%s^%s: %v", tt.x, tt.y, err)
+			continue
+		}
+		expected, err := x.PowWithPrecision(y, 40)
+		if err != nil {
+			t.Fatal(err)
+		}
+		relErr := safem.NewFromBigInt(got, -18).Sub(expected).Div(expected).Abs()
+		if relErr.GreaterThan(bound) {
+			t.Errorf("%s^%s: Expected %s, got %s", tt.x, tt.y, expected, safem.NewFromBigInt(got, -18))
+		}
+	}
+}
+
+func TestLogExpNaturalFunctions(t *testing.T) {
+	if got := natExp(new(big.Int)); got.Cmp(one18) != 0 {
+		t.Errorf("exp(0): Expected %s, got %s", one18, got)
+	}
+	if got := natLog(one18); got.Sign() != 0 {
+		t.Errorf("ln(1): Expected 0, got %s", got)
+	}
+	if got := natLog36(one18); got.Sign() != 0 {
+		t.Errorf("ln_36(1): Expected 0, got %s", got)
+	}
+	// exp and ln invert each other up to their truncation
+	x := big.NewInt(2_500_000_000_000_000_000)
+	if got := natLog(natExp(x)); new(big.Int).Sub(got, x).CmpAbs(big.NewInt(100)) > 0 {
+		t.Errorf("ln(exp(2.5)): Expected %s, got %s", x, got)
+	}
+}
+
+func TestLogExpPowErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		x, y *big.Int
+	}{
+		{"base out of bounds", new(big.Int).Lsh(big.NewInt(1), 255), one18},
+		{"exponent out of bounds", big.NewInt(2), mildExponentBound},
+		{"product too large", ether(3), ether(1000)},
+		{"product too small", new(big.Int).Rsh(one18, 1), ether(200)},
+	}
+	for _, tt := range tests {
+		if _, err := logExpPow(tt.x, tt.y); !errors.Is(err, safem.ErrInvalidInput) {
+			t.Errorf("%s: Expected ErrInvalidInput, got %v", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,352 @@
+package amm
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/morpheum-labs/safem"
+)
+
+// StableSwapFeeDenominator is the denominator of StableSwap.Fee, 10^10.
+const StableSwapFeeDenominator = 10_000_000_000
+
+// maxIterations bounds the Newton iterations of get_D and get_y, as the
+// contracts' range(255).
+const maxIterations = 255
+
+// StableSwap is a Curve stableswap pool of two or more tokens, quoted as the
+// classic plain pools (3pool and the factory plain pools): balances are scaled
+// to 18 decimals, the invariant D is found with get_D, the new balance of the
+// output token with get_y, and the fee is taken from the output.
+type StableSwap struct {
+	// Balances are the pool's balances in base units of each token.
+	Balances []*big.Int
+
+	// Decimals are the decimals of each token, at most 18.
+	Decimals []uint8
+
+	// A is the amplification coefficient as used by get_D, i.e. A times
+	// APrecision: A_precise() of factory pools, A() of 3pool.
+	A uint64
+
+	// APrecision is the A_PRECISION of the pool: 100 for factory pools, 1 for
+	// 3pool and older pools. Zero means 1.
+	APrecision uint64
+
+	// Fee is the swap fee in units of 1/StableSwapFeeDenominator, e.g. 4000000
+	// for 0.04%.
+	Fee uint64
+}
+
+// stableState is a pool scaled to 18 decimals.
+type stableState struct {
+	xp     []*big.Int
+	scale  []*big.Int
+	n      *big.Int
+	amp    *big.Int
+	aPrec  *big.Int
+	fee    *big.Int
+	feeDen *big.Int
+}
+
+func (p *StableSwap) state(in, out int) (*stableState, error) {
+	if err := balances(p.Balances); err != nil {
+		return nil, err
+	}
+	if err := pair(in, out, len(p.Balances)); err != nil {
+		return nil, err
+	}
+	scale, err := scales(p.Decimals, len(p.Balances))
+	if err != nil {
+		return nil, err
+	}
+	if p.A == 0 {
+		return nil, fmt.Errorf("cannot quote: A is zero: %w", safem.ErrInvalidInput)
+	}
+	if p.Fee >= StableSwapFeeDenominator {
+		return nil, fmt.Errorf("cannot quote: fee %d: %w", p.Fee, safem.ErrInvalidInput)
+	}
+	aPrec := p.APrecision
+	if aPrec == 0 {
+		aPrec = 1
+	}
+
+	s := &stableState{
+		xp:     make([]*big.Int, len(p.Balances)),
+		scale:  scale,
+		n:      big.NewInt(int64(len(p.Balances))),
+		amp:    new(big.Int).SetUint64(p.A),
+		aPrec:  new(big.Int).SetUint64(aPrec),
+		fee:    new(big.Int).SetUint64(p.Fee),
+		feeDen: big.NewInt(StableSwapFeeDenominator),
+	}
+	for i, b := range p.Balances {
+		s.xp[i] = new(big.Int).Mul(b, scale[i])
+	}
+	return s, nil
+}
+
+// getD returns the invariant D of xp,
+//
+//	Ann Σx + D = Ann D + D^(n+1) / (n^n Πx)
+//
+// with Ann = A n / A_PRECISION, by Newton iteration as the contracts' get_D.
+func (s *stableState) getD(xp []*big.Int) (*big.Int, error) {
+	sum := new(big.Int)
+	for _, x := range xp {
+		sum.Add(sum, x)
+	}
+	if sum.Sign() == 0 {
+		return sum, nil
+	}
+
+	ann := new(big.Int).Mul(s.amp, s.n)
+	d := new(big.Int).Set(sum)
+	for range maxIterations {
+		dP := new(big.Int).Set(d)
+		for _, x := range xp {
+			dP.Mul(dP, d).Quo(dP, new(big.Int).Mul(x, s.n))
+		}
+		prev := d
+
+		// (Ann S / A_PRECISION + D_P n) D / ((Ann - A_PRECISION) D / A_PRECISION + (n + 1) D_P)
+		num := new(big.Int).Mul(ann, sum)
+		num.Quo(num, s.aPrec).Add(num, new(big.Int).Mul(dP, s.n)).Mul(num, d)
+		den := new(big.Int).Sub(ann, s.aPrec)
+		den.Mul(den, d).Quo(den, s.aPrec)
+		den.Add(den, new(big.Int).Mul(new(big.Int).Add(s.n, big.NewInt(1)), dP))
+		d = num.Quo(num, den)
+
+		if converged(d, prev) {
+			return d, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot compute D: %w", ErrNoConvergence)
+}
+
+// getY returns the balance of token j that keeps the invariant D of xp when the
+// balance of token i is x.
+func (s *stableState) getY(i, j int, x *big.Int, xp []*big.Int, d *big.Int) (*big.Int, error) {
+	ann := new(big.Int).Mul(s.amp, s.n)
+	c := new(big.Int).Set(d)
+	sum := new(big.Int)
+	for k := range xp {
+		var xk *big.Int
+		switch k {
+		case i:
+			xk = x
+		case j:
+			continue
+		default:
+			xk = xp[k]
+		}
+		sum.Add(sum, xk)
+		c.Mul(c, d).Quo(c, new(big.Int).Mul(xk, s.n))
+	}
+	c.Mul(c, d).Mul(c, s.aPrec).Quo(c, new(big.Int).Mul(ann, s.n))
+	b := new(big.Int).Mul(d, s.aPrec)
+	b.Quo(b, ann).Add(b, sum)
+
+	y := new(big.Int).Set(d)
+	for range maxIterations {
+		prev := y
+		// (y^2 + c) / (2y + b - D)
+		num := new(big.Int).Mul(y, y)
+		num.Add(num, c)
+		den := new(big.Int).Lsh(y, 1)
+		den.Add(den, b).Sub(den, d)
+		if den.Sign() <= 0 {
+			break
+		}
+		y = num.Quo(num, den)
+		if converged(y, prev) {
+			return y, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot compute y: %w", ErrNoConvergence)
+}
+
+// converged reports whether |x - prev| <= 1.
+func converged(x, prev *big.Int) bool {
+	diff := new(big.Int).Sub(x, prev)
+	return diff.CmpAbs(big.NewInt(1)) <= 0
+}
+
+// AmountOut returns the amount of token out received for amountIn of token in,
+// as the pool's exchange:
+//
+//	dy = xp[out] - get_y(in, out, xp[in] + dx) - 1
+//	amountOut = (dy - dy * fee / 10^10) / 10^(18 - decimals[out])
+//
+// Returns ErrInsufficientAmount when the swap is too small to yield a positive
+// dy, where the contract would revert, and ErrNoConvergence when the iteration
+// does not converge.
+func (p *StableSwap) AmountOut(in, out int, amountIn *big.Int) (*big.Int, error) {
+	if err := amount("amount in", amountIn); err != nil {
+		return nil, err
+	}
+	s, err := p.state(in, out)
+	if err != nil {
+		return nil, err
+	}
+	return s.amountOut(in, out, amountIn)
+}
+
+func (s *stableState) amountOut(in, out int, amountIn *big.Int) (*big.Int, error) {
+	d, err := s.getD(s.xp)
+	if err != nil {
+		return nil, err
+	}
+	x := new(big.Int).Mul(amountIn, s.scale[in])
+	x.Add(x, s.xp[in])
+	y, err := s.getY(in, out, x, s.xp, d)
+	if err != nil {
+		return nil, err
+	}
+
+	dy := new(big.Int).Sub(s.xp[out], y)
+	dy.Sub(dy, big.NewInt(1))
+	if dy.Sign() < 0 {
+		return nil, fmt.Errorf("cannot quote: amount in %s: %w", amountIn, ErrInsufficientAmount)
+	}
+	fee := new(big.Int).Mul(dy, s.fee)
+	fee.Quo(fee, s.feeDen)
+	dy.Sub(dy, fee)
+	return dy.Quo(dy, s.scale[out]), nil
+}
+
+// AmountIn returns the smallest amount of token in for which AmountOut is at
+// least amountOut. The classic pools have no get_dx, so the amount is estimated
+// as StableSwap-NG's get_dx and then corrected against AmountOut, which makes a
+// swap of the result never return less than amountOut.
+//
+// Returns ErrInsufficientLiquidity when amountOut is not below the pool's
+// balance of token out.
+func (p *StableSwap) AmountIn(in, out int, amountOut *big.Int) (*big.Int, error) {
+	if err := amount("amount out", amountOut); err != nil {
+		return nil, err
+	}
+	s, err := p.state(in, out)
+	if err != nil {
+		return nil, err
+	}
+	if amountOut.Cmp(p.Balances[out]) >= 0 {
+		return nil, fmt.Errorf("cannot quote: amount out %s, balance %s: %w", amountOut, p.Balances[out], ErrInsufficientLiquidity)
+	}
+
+	// y = xp[out] - (dy + 1) * 10^10 / (10^10 - fee)
+	d, err := s.getD(s.xp)
+	if err != nil {
+		return nil, err
+	}
+	y := new(big.Int).Mul(amountOut, s.scale[out])
+	y.Add(y, big.NewInt(1)).Mul(y, s.feeDen).Quo(y, new(big.Int).Sub(s.feeDen, s.fee))
+	y.Sub(s.xp[out], y)
+	if y.Sign() <= 0 {
+		return nil, fmt.Errorf("cannot quote: amount out %s: %w", amountOut, ErrInsufficientLiquidity)
+	}
+	x, err := s.getY(out, in, y, s.xp, d)
+	if err != nil {
+		return nil, err
+	}
+	estimate := x.Sub(x, s.xp[in])
+	estimate.Quo(estimate, s.scale[in])
+	if estimate.Sign() <= 0 {
+		estimate.SetInt64(1)
+	}
+	return s.searchAmountIn(in, out, amountOut, estimate)
+}
+
+// searchAmountIn returns the smallest amount in whose amount out is at least
+// target, starting from estimate. AmountOut is non-decreasing in the amount in.
+func (s *stableState) searchAmountIn(in, out int, target, estimate *big.Int) (*big.Int, error) {
+	enough := func(amountIn *big.Int) (bool, error) {
+		got, err := s.amountOut(in, out, amountIn)
+		if errors.Is(err, ErrInsufficientAmount) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return got.Cmp(target) >= 0, nil
+	}
+
+	// bracket the answer in (lo, hi] by doubling the distance from estimate
+	lo, hi := new(big.Int), new(big.Int).Set(estimate)
+	ok, err := enough(hi)
+	if err != nil {
+		return nil, err
+	}
+	step := big.NewInt(1)
+	if ok {
+		for {
+			lo.Sub(hi, step)
+			if lo.Sign() <= 0 {
+				lo.SetInt64(0)
+				break
+			}
+			if ok, err = enough(lo); err != nil {
+				return nil, err
+			} else if !ok {
+				break
+			}
+			hi.Set(lo)
+			step.Lsh(step, 1)
+		}
+	} else {
+		for !ok {
+			lo.Set(hi)
+			hi.Add(hi, step)
+			if ok, err = enough(hi); err != nil {
+				return nil, err
+			}
+			step.Lsh(step, 1)
+		}
+	}
+
+	for new(big.Int).Sub(hi, lo).Cmp(big.NewInt(1)) > 0 {
+		mid := new(big.Int).Add(lo, hi)
+		mid.Rsh(mid, 1)
+		if ok, err = enough(mid); err != nil {
+			return nil, err
+		} else if ok {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return hi, nil
+}
+
+// SpotPrice returns the marginal price of token in in units of token out,
+// dxp[out]/dxp[in] on the invariant curve scaled back to base units:
+//
+//	price = xp[out] (Ann xp[in] + Dr) / (xp[in] (Ann xp[out] + Dr)) * 10^(decimals[out] - decimals[in])
+//
+// where Ann = A n / A_PRECISION and Dr = D^(n+1) / (n^n Πxp).
+func (p *StableSwap) SpotPrice(in, out int) (safem.Decimal, error) {
+	s, err := p.state(in, out)
+	if err != nil {
+		return safem.Decimal{}, err
+	}
+	d, err := s.getD(s.xp)
+	if err != nil {
+		return safem.Decimal{}, err
+	}
+
+	ann := new(big.Rat).SetFrac(new(big.Int).Mul(s.amp, s.n), s.aPrec)
+	dr := new(big.Int).Exp(d, new(big.Int).Add(s.n, big.NewInt(1)), nil)
+	prod := new(big.Int).Exp(s.n, s.n, nil)
+	for _, x := range s.xp {
+		prod.Mul(prod, x)
+	}
+	drRat := new(big.Rat).SetFrac(dr, prod)
+
+	xIn, xOut := new(big.Rat).SetInt(s.xp[in]), new(big.Rat).SetInt(s.xp[out])
+	num := new(big.Rat).Mul(ann, xIn)
+	num.Add(num, drRat).Mul(num, xOut).Mul(num, new(big.Rat).SetInt(s.scale[in]))
+	den := new(big.Rat).Mul(ann, xOut)
+	den.Add(den, drRat).Mul(den, xIn).Mul(den, new(big.Rat).SetInt(s.scale[out]))
+	return safem.NewFromBigRat(num.Quo(num, den), Precision), nil
+}
@@ -0,0 +1,189 @@
+package amm
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/morpheum-labs/safem"
+)
+
+// threePool is a 3pool-like pool of DAI, USDC and USDT with A = 2000 and a
+// 0.01% fee.
+func threePool() *StableSwap {
+	return &StableSwap{
+		Balances: []*big.Int{ether(1000000), big.NewInt(1100000_000000), big.NewInt(900000_000000)},
+		Decimals: []uint8{18, 6, 6},
+		A:        2000,
+		Fee:      1000000,
+	}
+}
+
+// factoryPool is a factory plain pool of two 18-decimal tokens with A = 200 and
+// a 0.04% fee.
+func factoryPool() *StableSwap {
+	return &StableSwap{
+		Balances:   []*big.Int{ether(1000), ether(3000)},
+		Decimals:   []uint8{18, 18},
+		A:          20000,
+		APrecision: 100,
+		Fee:        4000000,
+	}
+}
+
+// Expected values are from a line-by-line Python transcription of the Vyper
+// get_D, get_y and exchange of the plain pools, not from Curve's test suite or
+// on-chain calls; TestStableSwapInvariant checks them against the invariant.
+func TestStableSwapAmountOut(t *testing.T) {
+	tests := []struct {
+		pool     *StableSwap
+		in, out  int
+		amountIn *big.Int
+		expected string
+	}{
+		{threePool(), 0, 1, ether(1000), "999945424"},
+		{threePool(), 1, 2, big.NewInt(1000_000000), "999797507"},
+		{threePool(), 2, 0, big.NewInt(500000_000000), "499805902341475102862857"},
+		{factoryPool(), 0, 1, ether(1), "1008413909803769364"},
+		{factoryPool(), 1, 0, ether(500), "490710681880565010281"},
+		{factoryPool(), 0, 1, ether(2500), "2489910681880565010281"},
+	}
+	for _, tt := range tests {
+		got, err := tt.pool.AmountOut(tt.in, tt.out, tt.amountIn)
+		if err != nil || got.String() != tt.expected {
+			t.Errorf("AmountOut(%d, %d, %s): Expected %s, got %v %v", tt.in, tt.out, tt.amountIn, tt.expected, got, err)
+		}
+	}
+}
+
+func TestStableSwapAmountIn(t *testing.T) {
+	tests := []struct {
+		pool      *StableSwap
+		in, out   int
+		amountOut *big.Int
+		expected  string
+	}{
+		{threePool(), 0, 1, big.NewInt(999_000000), "999054523232239042827"},
+		{factoryPool(), 0, 1, ether(1), "991656209185100764"},
+		{factoryPool(), 1, 0, ether(900), "987486651489459382798"},
+	}
+	for _, tt := range tests {
+		got, err := tt.pool.AmountIn(tt.in, tt.out, tt.amountOut)
+		if err != nil || got.String() != tt.expected {
+			t.Errorf("AmountIn(%d, %d, %s): Expected %s, got %v %v", tt.in, tt.out, tt.amountOut, tt.expected, got, err)
+			continue
+		}
+		if out, _ := tt.pool.AmountOut(tt.in, tt.out, got); out.Cmp(tt.amountOut) < 0 {
+			t.Errorf("AmountOut(AmountIn(%s)): Expected at least %s, got %s", tt.amountOut, tt.amountOut, out)
+		}
+		less := new(big.Int).Sub(got, big.NewInt(1))
+		if out, _ := tt.pool.AmountOut(tt.in, tt.out, less); out.Cmp(tt.amountOut) >= 0 {
+			t.Errorf("AmountOut(AmountIn(%s) - 1): Expected less than %s, got %s", tt.amountOut, tt.amountOut, out)
+		}
+	}
+
+	if _, err := factoryPool().AmountIn(1, 0, ether(1000)); !errors.Is(err, ErrInsufficientLiquidity) {
+		t.Errorf("Expected ErrInsufficientLiquidity, got %v", err)
+	}
+}
+
+func TestStableSwapInvariant(t *testing.T) {
+	tests := []struct {
+		pool     *StableSwap
+		in, out  int
+		amountIn *big.Int
+	}{
+		{threePool(), 0, 1, ether(1000)},
+		{threePool(), 2, 0, big.NewInt(500000_000000)},
+		{factoryPool(), 1, 0, ether(500)},
+		{factoryPool(), 0, 1, ether(2500)},
+	}
+	for _, tt := range tests {
+		// without a fee, a swap keeps D up to the rounding of dy in the
+		// pool's favour, at most one unit of token out
+		pool := *tt.pool
+		pool.Fee = 0
+		s, _ := pool.state(tt.in, tt.out)
+		before, _ := s.getD(s.xp)
+		dy, err := pool.AmountOut(tt.in, tt.out, tt.amountIn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		xp := append([]*big.Int(nil), s.xp...)
+		xp[tt.in] = new(big.Int).Add(xp[tt.in], new(big.Int).Mul(tt.amountIn, s.scale[tt.in]))
+		xp[tt.out] = new(big.Int).Sub(xp[tt.out], new(big.Int).Mul(dy, s.scale[tt.out]))
+		after, _ := s.getD(xp)
+		maxGrowth := new(big.Int).Mul(big.NewInt(2), s.scale[tt.out])
+		if growth := new(big.Int).Sub(after, before); growth.Sign() < 0 || growth.Cmp(maxGrowth) > 0 {
+			t.Errorf("AmountOut(%d, %d, %s): Expected D to grow by at most %s, got %s", tt.in, tt.out, tt.amountIn, maxGrowth, growth)
+		}
+
+		// with the fee, the swap returns less
+		if withFee, _ := tt.pool.AmountOut(tt.in, tt.out, tt.amountIn); withFee.Cmp(dy) >= 0 {
+			t.Errorf("AmountOut(%d, %d, %s): Expected less than %s with a fee, got %s", tt.in, tt.out, tt.amountIn, dy, withFee)
+		}
+	}
+}
+
+func TestStableSwapSearchAmountIn(t *testing.T) {
+	s, _ := factoryPool().state(0, 1)
+	target := ether(1)
+	// the search finds the same amount from below and above the answer
+	for _, estimate := range []*big.Int{big.NewInt(1), ether(1000)} {
+		got, err := s.searchAmountIn(0, 1, target, estimate)
+		if err != nil || got.String() != "991656209185100764" {
+			t.Errorf("estimate %s: Expected 991656209185100764, got %v %v", estimate, got, err)
+		}
+	}
+}
+
+func TestStableSwapSpotPrice(t *testing.T) {
+	balanced := &StableSwap{
+		Balances: []*big.Int{ether(1000000), big.NewInt(1000000_000000)},
+		Decimals: []uint8{18, 6},
+		A:        100,
+		Fee:      4000000,
+	}
+	spot, err := balanced.SpotPrice(0, 1)
+	if err != nil || spot.String() != "0.000000000001" {
+		t.Errorf("Expected 0.000000000001, got %s %v", spot, err)
+	}
+
+	// a small swap in a balanced pool pays little more than the fee
+	impact, err := PriceImpact(balanced, 0, 1, ether(1000))
+	if err != nil || impact.LessThan(safem.RequireFromString("0.0004")) || impact.GreaterThan(safem.RequireFromString("0.00041")) {
+		t.Errorf("Expected an impact in [0.0004, 0.00041], got %s %v", impact, err)
+	}
+
+	// the token the pool holds more of is cheaper
+	pool := factoryPool()
+	spot, err = pool.SpotPrice(1, 0)
+	if err != nil || !spot.LessThan(safem.New(1, 0)) {
+		t.Errorf("Expected a price below 1, got %s %v", spot, err)
+	}
+	inverse, _ := pool.SpotPrice(0, 1)
+	if product := spot.Mul(inverse).Round(30); !product.Equal(safem.New(1, 0)) {
+		t.Errorf("Expected reciprocal prices, got %s and %s", spot, inverse)
+	}
+}
+
+func TestStableSwapErrors(t *testing.T) {
+	tests := []struct {
+		pool    *StableSwap
+		in, out int
+		amount  *big.Int
+		err     error
+	}{
+		{threePool(), 0, 3, big.NewInt(1), safem.ErrInvalidInput},
+		{threePool(), 0, 1, big.NewInt(0), ErrInsufficientAmount},
+		{&StableSwap{Balances: []*big.Int{ether(1), ether(1)}, Decimals: []uint8{18}, A: 100}, 0, 1, big.NewInt(1), safem.ErrInvalidInput},
+		{&StableSwap{Balances: []*big.Int{ether(1), ether(1)}, Decimals: []uint8{18, 24}, A: 100}, 0, 1, big.NewInt(1), safem.ErrInvalidInput},
+		{&StableSwap{Balances: []*big.Int{ether(1), ether(1)}, Decimals: []uint8{18, 18}}, 0, 1, big.NewInt(1), safem.ErrInvalidInput},
+		{&StableSwap{Balances: []*big.Int{ether(1), big.NewInt(0)}, Decimals: []uint8{18, 18}, A: 100}, 0, 1, big.NewInt(1), ErrInsufficientLiquidity},
+	}
+	for _, tt := range tests {
+		if _, err := tt.pool.AmountOut(tt.in, tt.out, tt.amount); !errors.Is(err, tt.err) {
+			t.Errorf("AmountOut(%d, %d, %v): Expected %v, got %v", tt.in, tt.out, tt.amount, tt.err, err)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package amm
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/morpheum-labs/safem"
+)
+
+// Fee is a swap fee of Numerator/Denominator of the input amount.
+type Fee struct {
+	Numerator, Denominator uint64
+}
+
+// UniswapV2Fee is the 0.3% fee of Uniswap v2, taken as 997/1000 of the input.
+var UniswapV2Fee = Fee{Numerator: 3, Denominator: 1000}
+
+// UniswapV2 is a constant-product pool of two tokens, quoted as
+// UniswapV2Library.getAmountOut and getAmountIn.
+type UniswapV2 struct {
+	Reserve0, Reserve1 *big.Int
+
+	// Fee is the swap fee. The zero value is UniswapV2Fee; forks set their own,
+	// e.g. Fee{25, 10000} for 0.25%.
+	Fee Fee
+}
+
+// reserves returns the reserves of in and out and the fee.
+func (p *UniswapV2) reserves(in, out int) (reserveIn, reserveOut *big.Int, fee Fee, err error) {
+	if err := pair(in, out, 2); err != nil {
+		return nil, nil, Fee{}, err
+	}
+	if err := balances([]*big.Int{p.Reserve0, p.Reserve1}); err != nil {
+		return nil, nil, Fee{}, err
+	}
+	fee = p.Fee
+	if fee.Denominator == 0 {
+		fee = UniswapV2Fee
+	}
+	if fee.Numerator >= fee.Denominator {
+		return nil, nil, Fee{}, fmt.Errorf("cannot quote: fee %d/%d: %w", fee.Numerator, fee.Denominator, safem.ErrInvalidInput)
+	}
+	if in == 0 {
+		return p.Reserve0, p.Reserve1, fee, nil
+	}
+	return p.Reserve1, p.Reserve0, fee, nil
+}
+
+// AmountOut returns the amount of token out received for amountIn of token in:
+//
+//	amountInWithFee = amountIn * (denominator - numerator)
+//	amountOut = amountInWithFee * reserveOut / (reserveIn * denominator + amountInWithFee)
+//
+// rounded down. Returns ErrInsufficientAmount when amountIn is zero and
+// ErrInsufficientLiquidity when a reserve is zero.
+//
+// Example:
+//
+//	// 1 token into reserves of 5 and 10 tokens, 18 decimals
+//	pool.AmountOut(0, 1, oneEther) // output: 1662497915624478906
+func (p *UniswapV2) AmountOut(in, out int, amountIn *big.Int) (*big.Int, error) {
+	if err := amount("amount in", amountIn); err != nil {
+		return nil, err
+	}
+	reserveIn, reserveOut, fee, err := p.reserves(in, out)
+	if err != nil {
+		return nil, err
+	}
+	amountInWithFee := new(big.Int).Mul(amountIn, new(big.Int).SetUint64(fee.Denominator-fee.Numerator))
+	numerator := new(big.Int).Mul(amountInWithFee, reserveOut)
+	denominator := new(big.Int).Mul(reserveIn, new(big.Int).SetUint64(fee.Denominator))
+	denominator.Add(denominator, amountInWithFee)
+	return numerator.Quo(numerator, denominator), nil
+}
+
+// AmountIn returns the amount of token in to pay for amountOut of token out:
+//
+//	amountIn = reserveIn * amountOut * denominator / ((reserveOut - amountOut) * (denominator - numerator)) + 1
+//
+// The division rounds down and the 1 is always added, as in the library.
+// Returns ErrInsufficientLiquidity when amountOut is not below reserveOut.
+func (p *UniswapV2) AmountIn(in, out int, amountOut *big.Int) (*big.Int, error) {
+	if err := amount("amount out", amountOut); err != nil {
+		return nil, err
+	}
+	reserveIn, reserveOut, fee, err := p.reserves(in, out)
+	if err != nil {
+		return nil, err
+	}
+	if amountOut.Cmp(reserveOut) >= 0 {
+		return nil, fmt.Errorf("cannot quote: amount out %s, reserve %s: %w", amountOut, reserveOut, ErrInsufficientLiquidity)
+	}
+	numerator := new(big.Int).Mul(reserveIn, amountOut)
+	numerator.Mul(numerator, new(big.Int).SetUint64(fee.Denominator))
+	denominator := new(big.Int).Sub(reserveOut, amountOut)
+	denominator.Mul(denominator, new(big.Int).SetUint64(fee.Denominator-fee.Numerator))
+	amountIn := numerator.Quo(numerator, denominator)
+	return amountIn.Add(amountIn, big.NewInt(1)), nil
+}
+
+// SpotPrice returns reserveOut / reserveIn.
+func (p *UniswapV2) SpotPrice(in, out int) (safem.Decimal, error) {
+	reserveIn, reserveOut, _, err := p.reserves(in, out)
+	if err != nil {
+		return safem.Decimal{}, err
+	}
+	return safem.NewFromBigInt(reserveOut, 0).DivRound(safem.NewFromBigInt(reserveIn, 0), Precision), nil
+}
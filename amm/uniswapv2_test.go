@@ -0,0 +1,140 @@
+package amm
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/morpheum-labs/safem"
+)
+
+func ether(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), pow10(18))
+}
+
+// Vectors from Uniswap v2-core test/UniswapV2Pair.spec.ts.
+func TestUniswapV2AmountOut(t *testing.T) {
+	tests := []struct {
+		amountIn, reserve0, reserve1 int64
+		expected                     string
+	}{
+		{1, 5, 10, "1662497915624478906"},
+		{1, 10, 5, "453305446940074565"},
+		{2, 5, 10, "2851015155847869602"},
+		{2, 10, 5, "831248957812239453"},
+		{1, 10, 10, "906610893880149131"},
+		{1, 100, 100, "987158034397061298"},
+		{1, 1000, 1000, "996006981039903216"},
+	}
+	for _, tt := range tests {
+		pool := &UniswapV2{Reserve0: ether(tt.reserve0), Reserve1: ether(tt.reserve1)}
+		got, err := pool.AmountOut(0, 1, ether(tt.amountIn))
+		if err != nil || got.String() != tt.expected {
+			t.Errorf("AmountOut(%d, %d, %d): Expected %s, got %v %v", tt.amountIn, tt.reserve0, tt.reserve1, tt.expected, got, err)
+		}
+
+		// the reverse pool quotes the other direction
+		reverse := &UniswapV2{Reserve0: ether(tt.reserve1), Reserve1: ether(tt.reserve0)}
+		if got, err := reverse.AmountOut(1, 0, ether(tt.amountIn)); err != nil || got.String() != tt.expected {
+			t.Errorf("AmountOut(1, 0): Expected %s, got %v %v", tt.expected, got, err)
+		}
+
+		// paying AmountIn yields at least the amount out, one wei less does not
+		amountOut := bigInt(tt.expected)
+		amountIn, err := pool.AmountIn(0, 1, amountOut)
+		if err != nil {
+			t.Fatalf("AmountIn: %v", err)
+		}
+		if got, _ := pool.AmountOut(0, 1, amountIn); got.Cmp(amountOut) < 0 {
+			t.Errorf("AmountOut(AmountIn(%s)): Expected at least %s, got %s", amountOut, amountOut, got)
+		}
+		if got, _ := pool.AmountOut(0, 1, new(big.Int).Sub(amountIn, big.NewInt(2))); got.Cmp(amountOut) >= 0 {
+			t.Errorf("AmountOut(AmountIn(%s) - 2): Expected less than %s, got %s", amountOut, amountOut, got)
+		}
+	}
+}
+
+// Vectors from Uniswap v2-periphery test/UniswapV2Router01.spec.ts.
+func TestUniswapV2Library(t *testing.T) {
+	pool := &UniswapV2{Reserve0: big.NewInt(100), Reserve1: big.NewInt(100)}
+	if got, err := pool.AmountOut(0, 1, big.NewInt(2)); err != nil || got.Int64() != 1 {
+		t.Errorf("getAmountOut(2, 100, 100): Expected 1, got %v %v", got, err)
+	}
+	if got, err := pool.AmountIn(0, 1, big.NewInt(1)); err != nil || got.Int64() != 2 {
+		t.Errorf("getAmountIn(1, 100, 100): Expected 2, got %v %v", got, err)
+	}
+
+	errTests := []struct {
+		pool    *UniswapV2
+		in, out int
+		amount  *big.Int
+		err     error
+	}{
+		{pool, 0, 1, big.NewInt(0), ErrInsufficientAmount},
+		{pool, 0, 1, big.NewInt(-1), safem.ErrNegativeInput},
+		{pool, 0, 1, nil, safem.ErrInvalidInput},
+		{pool, 0, 0, big.NewInt(1), safem.ErrInvalidInput},
+		{pool, 0, 2, big.NewInt(1), safem.ErrInvalidInput},
+		{&UniswapV2{Reserve0: big.NewInt(0), Reserve1: big.NewInt(100)}, 0, 1, big.NewInt(1), ErrInsufficientLiquidity},
+		{&UniswapV2{Reserve0: big.NewInt(100), Reserve1: big.NewInt(100), Fee: Fee{1000, 1000}}, 0, 1, big.NewInt(1), safem.ErrInvalidInput},
+	}
+	for _, tt := range errTests {
+		if _, err := tt.pool.AmountOut(tt.in, tt.out, tt.amount); !errors.Is(err, tt.err) {
+			t.Errorf("AmountOut(%d, %d, %v): Expected %v, got %v", tt.in, tt.out, tt.amount, tt.err, err)
+		}
+		if _, err := tt.pool.AmountIn(tt.in, tt.out, tt.amount); !errors.Is(err, tt.err) {
+			t.Errorf("AmountIn(%d, %d, %v): Expected %v, got %v", tt.in, tt.out, tt.amount, tt.err, err)
+		}
+	}
+	if _, err := pool.AmountIn(0, 1, big.NewInt(100)); !errors.Is(err, ErrInsufficientLiquidity) {
+		t.Errorf("Expected ErrInsufficientLiquidity, got %v", err)
+	}
+}
+
+func TestUniswapV2Fee(t *testing.T) {
+	// PancakeSwap v2 takes 0.25%: 1e18 * 9975 * 10e18 / (5e18 * 10000 + 1e18 * 9975)
+	pool := &UniswapV2{Reserve0: ether(5), Reserve1: ether(10), Fee: Fee{25, 10000}}
+	got, err := pool.AmountOut(0, 1, ether(1))
+	if err != nil || got.String() != "1663192997082117548" {
+		t.Errorf("Expected 1663192997082117548, got %v %v", got, err)
+	}
+	if got, err := pool.AmountIn(0, 1, ether(1)); err != nil || got.String() != "556947925368978001" {
+		t.Errorf("Expected 556947925368978001, got %v %v", got, err)
+	}
+}
+
+func TestUniswapV2PriceImpact(t *testing.T) {
+	pool := &UniswapV2{Reserve0: ether(5), Reserve1: ether(10)}
+	spot, err := pool.SpotPrice(0, 1)
+	if err != nil || spot.String() != "2" {
+		t.Errorf("Expected 2, got %s %v", spot, err)
+	}
+	if spot, err := pool.SpotPrice(1, 0); err != nil || spot.String() != "0.5" {
+		t.Errorf("Expected 0.5, got %s %v", spot, err)
+	}
+
+	// (2e18 - 1662497915624478906) / 2e18
+	impact, err := PriceImpact(pool, 0, 1, ether(1))
+	if err != nil || impact.String() != "0.168751042187760547" {
+		t.Errorf("Expected 0.168751042187760547, got %s %v", impact, err)
+	}
+
+	// a tiny swap pays the fee and a rounding wei: (1000000 - 996999) / 1000000
+	impact, err = PriceImpact(&UniswapV2{Reserve0: ether(1000000), Reserve1: ether(1000000)}, 0, 1, big.NewInt(1000000))
+	if err != nil || impact.String() != "0.003001" {
+		t.Errorf("Expected 0.003001, got %s %v", impact, err)
+	}
+
+	impact, err = PriceImpactExactOut(pool, 0, 1, bigInt("1662497915624478906"))
+	if err != nil || impact.Cmp(safem.RequireFromString("0.168751042187760547")) > 0 {
+		t.Errorf("Expected at most 0.168751042187760547, got %s %v", impact, err)
+	}
+}
+
+func bigInt(s string) *big.Int {
+	x, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("invalid integer " + s)
+	}
+	return x
+}
@@ -0,0 +1,234 @@
+package amm
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/morpheum-labs/safem"
+	"github.com/morpheum-labs/safem/internal/bigint"
+)
+
+var (
+	one18 = pow10(18)
+
+	// maxSwapRatio is WeightedMath's _MAX_IN_RATIO and _MAX_OUT_RATIO, 0.3.
+	maxSwapRatio = big.NewInt(300_000_000_000_000_000)
+
+	// maxPowRelativeError is FixedPoint's MAX_POW_RELATIVE_ERROR, 10^-14.
+	maxPowRelativeError = big.NewInt(10000)
+)
+
+// Weighted is a Balancer v2 weighted pool, quoted as WeightedMath with the
+// scaling and fee handling of BaseMinimalSwapInfoPool: amounts are scaled to 18
+// decimals, the swap fee is taken from the input, and every step rounds in the
+// pool's favour.
+//
+// Powers are evaluated as FixedPoint.powUp: exactly for exponents 1, 2 and 4,
+// and otherwise with a port of LogExpMath.pow plus FixedPoint's error margin, so
+// quotes match the contracts to the wei.
+type Weighted struct {
+	// Balances are the pool's balances in base units of each token.
+	Balances []*big.Int
+
+	// Decimals are the decimals of each token, at most 18.
+	Decimals []uint8
+
+	// Weights are the normalized weights of the tokens, e.g. 0.8 and 0.2, with at
+	// most 18 decimal places.
+	Weights []safem.Decimal
+
+	// SwapFee is the swap fee percentage, e.g. 0.003 for 0.3%, with at most 18
+	// decimal places.
+	SwapFee safem.Decimal
+}
+
+// weightedState is a pool with weights and fee in 18-decimal fixed point.
+type weightedState struct {
+	scale   []*big.Int
+	weights []*big.Int
+	fee     *big.Int
+}
+
+func (p *Weighted) state(in, out int) (*weightedState, error) {
+	if err := balances(p.Balances); err != nil {
+		return nil, err
+	}
+	if err := pair(in, out, len(p.Balances)); err != nil {
+		return nil, err
+	}
+	scale, err := scales(p.Decimals, len(p.Balances))
+	if err != nil {
+		return nil, err
+	}
+	if len(p.Weights) != len(p.Balances) {
+		return nil, fmt.Errorf("cannot quote: %d weights for %d tokens: %w", len(p.Weights), len(p.Balances), safem.ErrInvalidInput)
+	}
+	s := &weightedState{scale: scale, weights: make([]*big.Int, len(p.Weights))}
+	for i, w := range p.Weights {
+		if s.weights[i], err = fixed18("weight", w); err != nil {
+			return nil, err
+		}
+		if s.weights[i].Sign() == 0 || s.weights[i].Cmp(one18) > 0 {
+			return nil, fmt.Errorf("cannot quote: weight %s: %w", w, safem.ErrInvalidInput)
+		}
+	}
+	if s.fee, err = fixed18("swap fee", p.SwapFee); err != nil {
+		return nil, err
+	}
+	if s.fee.Cmp(one18) >= 0 {
+		return nil, fmt.Errorf("cannot quote: swap fee %s: %w", p.SwapFee, safem.ErrInvalidInput)
+	}
+	return s, nil
+}
+
+// fixed18 returns d as an 18-decimal fixed-point number. Returns
+// ErrNegativeInput when d is negative and ErrPrecisionLoss when it has more than
+// 18 decimal places.
+func fixed18(name string, d safem.Decimal) (*big.Int, error) {
+	if d.Sign() < 0 {
+		return nil, fmt.Errorf("cannot quote: %s %s: %w", name, d, safem.ErrNegativeInput)
+	}
+	v := d.Shift(18)
+	if !v.IsInteger() {
+		return nil, fmt.Errorf("cannot quote: %s %s: %w: more than 18 decimal places", name, d, safem.ErrPrecisionLoss)
+	}
+	return v.BigInt(), nil
+}
+
+// AmountOut returns the amount of token out received for amountIn of token in:
+//
+//	amountIn' = amountIn - amountIn * fee (rounded up)
+//	amountOut = balanceOut * (1 - (balanceIn / (balanceIn + amountIn'))^(weightIn / weightOut))
+//
+// with the rounding of WeightedMath._calcOutGivenIn. Returns ErrMaxSwapRatio
+// when amountIn' exceeds 30% of balanceIn, where the pool reverts with BAL#304.
+//
+// Example:
+//
+//	// 50/50 pool of 1000 and 1000 tokens, no fee
+//	pool.AmountOut(0, 1, hundredTokens) // output: 90909090909090909000
+func (p *Weighted) AmountOut(in, out int, amountIn *big.Int) (*big.Int, error) {
+	if err := amount("amount in", amountIn); err != nil {
+		return nil, err
+	}
+	s, err := p.state(in, out)
+	if err != nil {
+		return nil, err
+	}
+
+	// fees are subtracted before scaling
+	a := new(big.Int).Sub(amountIn, mulUp(amountIn, s.fee))
+	a.Mul(a, s.scale[in])
+	balanceIn := new(big.Int).Mul(p.Balances[in], s.scale[in])
+	balanceOut := new(big.Int).Mul(p.Balances[out], s.scale[out])
+
+	if a.Cmp(mulDown(balanceIn, maxSwapRatio)) > 0 {
+		return nil, fmt.Errorf("cannot quote: amount in %s: %w", amountIn, ErrMaxSwapRatio)
+	}
+	base := divUp(balanceIn, new(big.Int).Add(balanceIn, a))
+	exponent := divDown(s.weights[in], s.weights[out])
+	power, err := powUp(base, exponent)
+	if err != nil {
+		return nil, err
+	}
+	amountOut := mulDown(balanceOut, complement(power))
+
+	// amounts leaving the pool round down
+	return amountOut.Quo(amountOut, s.scale[out]), nil
+}
+
+// AmountIn returns the amount of token in to pay for amountOut of token out:
+//
+//	amountIn' = balanceIn * ((balanceOut / (balanceOut - amountOut))^(weightOut / weightIn) - 1)
+//	amountIn = amountIn' / (1 - fee) (rounded up)
+//
+// with the rounding of WeightedMath._calcInGivenOut. Returns ErrMaxSwapRatio
+// when amountOut exceeds 30% of balanceOut, where the pool reverts with BAL#305.
+func (p *Weighted) AmountIn(in, out int, amountOut *big.Int) (*big.Int, error) {
+	if err := amount("amount out", amountOut); err != nil {
+		return nil, err
+	}
+	s, err := p.state(in, out)
+	if err != nil {
+		return nil, err
+	}
+
+	a := new(big.Int).Mul(amountOut, s.scale[out])
+	balanceIn := new(big.Int).Mul(p.Balances[in], s.scale[in])
+	balanceOut := new(big.Int).Mul(p.Balances[out], s.scale[out])
+
+	if a.Cmp(mulDown(balanceOut, maxSwapRatio)) > 0 {
+		return nil, fmt.Errorf("cannot quote: amount out %s: %w", amountOut, ErrMaxSwapRatio)
+	}
+	base := divUp(balanceOut, new(big.Int).Sub(balanceOut, a))
+	exponent := divUp(s.weights[out], s.weights[in])
+	power, err := powUp(base, exponent)
+	if err != nil {
+		return nil, err
+	}
+	amountIn := mulUp(balanceIn, power.Sub(power, one18))
+
+	// amounts entering the pool round up; fees are added after scaling
+	amountIn = bigint.CeilDiv(amountIn, s.scale[in])
+	return divUp(amountIn, complement(s.fee)), nil
+}
+
+// SpotPrice returns (balanceOut / weightOut) / (balanceIn / weightIn).
+func (p *Weighted) SpotPrice(in, out int) (safem.Decimal, error) {
+	if _, err := p.state(in, out); err != nil {
+		return safem.Decimal{}, err
+	}
+	num := safem.NewFromBigInt(p.Balances[out], 0).Mul(p.Weights[in])
+	den := safem.NewFromBigInt(p.Balances[in], 0).Mul(p.Weights[out])
+	return num.DivRound(den, Precision), nil
+}
+
+// mulDown, mulUp, divDown, divUp and complement are Balancer's FixedPoint
+// operations on 18-decimal numbers.
+
+func mulDown(a, b *big.Int) *big.Int {
+	p := new(big.Int).Mul(a, b)
+	return p.Quo(p, one18)
+}
+
+func mulUp(a, b *big.Int) *big.Int {
+	return bigint.CeilDiv(new(big.Int).Mul(a, b), one18)
+}
+
+func divDown(a, b *big.Int) *big.Int {
+	p := new(big.Int).Mul(a, one18)
+	return p.Quo(p, b)
+}
+
+func divUp(a, b *big.Int) *big.Int {
+	return bigint.CeilDiv(new(big.Int).Mul(a, one18), b)
+}
+
+func complement(x *big.Int) *big.Int {
+	if x.Cmp(one18) >= 0 {
+		return new(big.Int)
+	}
+	return new(big.Int).Sub(one18, x)
+}
+
+// powUp returns x^y rounded up, as FixedPoint.powUp: the power plus its maximum
+// relative error, except for y = 1, 2 and 4, which are computed exactly.
+func powUp(x, y *big.Int) (*big.Int, error) {
+	switch {
+	case y.Cmp(one18) == 0:
+		return new(big.Int).Set(x), nil
+	case y.Cmp(new(big.Int).Lsh(one18, 1)) == 0:
+		return mulUp(x, x), nil
+	case y.Cmp(new(big.Int).Lsh(one18, 2)) == 0:
+		square := mulUp(x, x)
+		return mulUp(square, square), nil
+	}
+
+	raw, err := logExpPow(x, y)
+	if err != nil {
+		return nil, err
+	}
+	maxError := mulUp(raw, maxPowRelativeError)
+	maxError.Add(maxError, big.NewInt(1))
+	return raw.Add(raw, maxError), nil
+}
@@ -0,0 +1,125 @@
+package amm
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/morpheum-labs/safem"
+)
+
+func decimals(ss ...string) []safem.Decimal {
+	ds := make([]safem.Decimal, len(ss))
+	for i, s := range ss {
+		ds[i] = safem.RequireFromString(s)
+	}
+	return ds
+}
+
+// evenPool is a 50/50 pool of 1000 and 1000 18-decimal tokens without a fee.
+func evenPool() *Weighted {
+	return &Weighted{
+		Balances: []*big.Int{ether(1000), ether(1000)},
+		Decimals: []uint8{18, 18},
+		Weights:  decimals("0.5", "0.5"),
+	}
+}
+
+// wethUSDC is an 80/20 pool of 1000 WETH and 500000 USDC with a 0.3% fee, a spot
+// price of 2000 USDC per WETH.
+func wethUSDC() *Weighted {
+	return &Weighted{
+		Balances: []*big.Int{ether(1000), big.NewInt(500000_000000)},
+		Decimals: []uint8{18, 6},
+		Weights:  decimals("0.8", "0.2"),
+		SwapFee:  safem.RequireFromString("0.003"),
+	}
+}
+
+func TestWeightedAmountOut(t *testing.T) {
+	tests := []struct {
+		name     string
+		pool     *Weighted
+		in, out  int
+		amount   *big.Int
+		expected string
+	}{
+		// 1000 * (1 - ⌈1000/1100⌉), exponent 1
+		{"even", evenPool(), 0, 1, ether(100), "90909090909090909000"},
+		// exponent 4, computed exactly
+		{"weth to usdc", wethUSDC(), 0, 1, ether(1), "1989039848"},
+		// exponent 0.25, computed with LogExpMath and powUp's error margin
+		{"usdc to weth", wethUSDC(), 1, 0, big.NewInt(2000_000000), "994522386189847000"},
+	}
+	for _, tt := range tests {
+		got, err := tt.pool.AmountOut(tt.in, tt.out, tt.amount)
+		if err != nil || got.String() != tt.expected {
+			t.Errorf("%s: Expected %s, got %v %v", tt.name, tt.expected, got, err)
+		}
+	}
+}
+
+func TestWeightedAmountIn(t *testing.T) {
+	tests := []struct {
+		name     string
+		pool     *Weighted
+		in, out  int
+		amount   *big.Int
+		expected string
+	}{
+		// 1000 * (⌈1000/900⌉ - 1)
+		{"even", evenPool(), 0, 1, ether(100), "111111111111111112000"},
+		// exponent 0.25
+		{"weth to usdc", wethUSDC(), 0, 1, big.NewInt(2000_000000), "1005524096758452358"},
+		// exponent 4
+		{"usdc to weth", wethUSDC(), 1, 0, ether(1), "2011043148"},
+	}
+	for _, tt := range tests {
+		got, err := tt.pool.AmountIn(tt.in, tt.out, tt.amount)
+		if err != nil || got.String() != tt.expected {
+			t.Errorf("%s: Expected %s, got %v %v", tt.name, tt.expected, got, err)
+		}
+	}
+}
+
+func TestWeightedSpotPrice(t *testing.T) {
+	spot, err := wethUSDC().SpotPrice(0, 1)
+	if err != nil || spot.String() != "0.000000002" {
+		t.Errorf("Expected 0.000000002, got %s %v", spot, err)
+	}
+	spot, err = wethUSDC().SpotPrice(1, 0)
+	if err != nil || spot.String() != "500000000" {
+		t.Errorf("Expected 500000000, got %s %v", spot, err)
+	}
+
+	// 1 - 1989.039848 / 2000
+	impact, err := PriceImpact(wethUSDC(), 0, 1, ether(1))
+	if err != nil || impact.String() != "0.005480076" {
+		t.Errorf("Expected 0.005480076, got %s %v", impact, err)
+	}
+}
+
+func TestWeightedErrors(t *testing.T) {
+	tests := []struct {
+		pool    *Weighted
+		in, out int
+		amount  *big.Int
+		err     error
+	}{
+		{evenPool(), 0, 1, ether(301), ErrMaxSwapRatio},
+		{evenPool(), 0, 1, big.NewInt(0), ErrInsufficientAmount},
+		{evenPool(), 1, 1, big.NewInt(1), safem.ErrInvalidInput},
+		{&Weighted{Balances: evenPool().Balances, Decimals: []uint8{18, 18}, Weights: decimals("0.5")}, 0, 1, big.NewInt(1), safem.ErrInvalidInput},
+		{&Weighted{Balances: evenPool().Balances, Decimals: []uint8{18, 18}, Weights: decimals("0.5", "0")}, 0, 1, big.NewInt(1), safem.ErrInvalidInput},
+		{&Weighted{Balances: evenPool().Balances, Decimals: []uint8{18, 18}, Weights: decimals("0.5", "-0.5")}, 0, 1, big.NewInt(1), safem.ErrNegativeInput},
+		{&Weighted{Balances: evenPool().Balances, Decimals: []uint8{18, 18}, Weights: decimals("0.5", "0.5"), SwapFee: safem.RequireFromString("1e-19")}, 0, 1, big.NewInt(1), safem.ErrPrecisionLoss},
+	}
+	for _, tt := range tests {
+		if _, err := tt.pool.AmountOut(tt.in, tt.out, tt.amount); !errors.Is(err, tt.err) {
+			t.Errorf("AmountOut(%d, %d, %v): Expected %v, got %v", tt.in, tt.out, tt.amount, tt.err, err)
+		}
+	}
+	if _, err := evenPool().AmountIn(0, 1, ether(301)); !errors.Is(err, ErrMaxSwapRatio) {
+		t.Errorf("Expected ErrMaxSwapRatio, got %v", err)
+	}
+}
@@ -1,9 +1,13 @@
 package safem
 
 import (
+	"bytes"
+	"database/sql/driver"
 	"encoding/json"
+	"fmt"
 	"math"
 	"math/big"
+	"reflect"
 	"strconv"
 	"strings"
 	"sync"
@@ -11,89 +15,316 @@ import (
 )
 
 // BigIntPool for reusing big.Int instances to reduce allocations.
+//
+// Deprecated: a *big.Int from BigIntPool carries no record of where it came from,
+// so putting one back while a BigInt or another caller still holds it causes
+// aliasing bugs. Use Scratch for temporaries; BigInt values always own their Int.
 var BigIntPool = sync.Pool{
 	New: func() interface{} { return new(big.Int) },
 }
 
 // BigInt is a wrapper for *big.Int with custom JSON unmarshaling (inspired by go-ethereum/hexutil.Big).
 // This ensures safe conversion from JSON numbers (float64/string) to *big.Int without precision loss.
+//
+// A nil Int is the null value: it is written as JSON null, SQL NULL or empty text, and
+// decoding those leaves Int nil. Use NullBigInt when a separate Valid flag is wanted.
 type BigInt struct {
 	*big.Int
 }
 
-// UnmarshalJSON handles JSON number (float64/string) to *big.Int conversion safely.
-// This prevents overflow issues when large numbers are parsed as float64 from JSON.
+// HexBigInt is a BigInt written to JSON as a quoted 0x-prefixed hex string, e.g.
+// "0x3e8", as used by Ethereum JSON-RPC. Negative values are written as "-0x3e8".
+// It decodes JSON like BigInt, and every other encoding (text, binary, gob, SQL,
+// CBOR, MessagePack and BSON) is the embedded BigInt's:
+//
+//	type tx struct {
+//		Value safem.HexBigInt `json:"value"`
+//	}
+//	t := tx{Value: safem.HexBigInt{*safem.NewBigIntFromInt64(1000)}} // {"value":"0x3e8"}
+type HexBigInt struct {
+	BigInt
+}
+
+// NumberBigInt is a BigInt written to JSON as a bare number, e.g. 1000. Many
+// decoders read numbers as float64 and lose precision above 2^53, so only use it
+// when the consumer is known to decode integers exactly. It decodes JSON like
+// BigInt, and every other encoding is the embedded BigInt's.
+type NumberBigInt struct {
+	BigInt
+}
+
+// BigIntJSONPolicy controls which JSON inputs BigInt.UnmarshalJSON accepts. The
+// zero value is the strictest policy: base 10 integers only, as JSON numbers or
+// strings, and no negative values.
+//
+// Numbers are read from the raw JSON text, never through float64, so integers of
+// any size decode exactly. Values with a non-zero fraction are always rejected.
+type BigIntJSONPolicy struct {
+	// AllowFloat accepts integral values written with a fraction, e.g. 1000.0.
+	AllowFloat bool
+
+	// AllowExponent accepts integral values in exponent notation, e.g. 1e18.
+	AllowExponent bool
+
+	// AllowHex accepts 0x-prefixed hex strings, e.g. "0x3e8".
+	AllowHex bool
+
+	// BareHex reads strings without a 0x prefix as hex, e.g. "3e8". JSON numbers
+	// are still read in base 10.
+	BareHex bool
+
+	// AllowNegative accepts values below zero.
+	AllowNegative bool
+
+	// AllowEmptyString accepts "" as null, leaving the value nil.
+	AllowEmptyString bool
+}
+
+// DefaultBigIntJSONPolicy returns the policy of BigInt.UnmarshalJSON: integral
+// floats, exponents, 0x hex, negative values and empty strings are accepted.
+//
+// This is what the decoder accepted before policies were introduced, with three
+// differences: numbers are read exactly instead of through float64; integral
+// fractions and exponents are also accepted inside strings, e.g. "1e3" and
+// "1000.0"; and a leading plus sign in strings, e.g. "+1", is rejected.
+func DefaultBigIntJSONPolicy() BigIntJSONPolicy {
+	return BigIntJSONPolicy{
+		AllowFloat:       true,
+		AllowExponent:    true,
+		AllowHex:         true,
+		AllowNegative:    true,
+		AllowEmptyString: true,
+	}
+}
+
+// maxBigIntJSONExponent bounds exponent notation so that input like 1e999999999
+// cannot force a huge allocation.
+const maxBigIntJSONExponent = 1000
+
+// UnmarshalJSON decodes a JSON number, string or null using
+// DefaultBigIntJSONPolicy. Use StrictBigInt, BigIntJSONDecoder or
+// BigIntJSONPolicy.Unmarshal to decode with another policy.
+//
+// Rejected input returns a *json.UnmarshalTypeError describing the value and the
+// rule it broke. When decoding a struct, encoding/json fills in the field name,
+// except when built with the jsonv2 experiment:
+//
+//	json: cannot unmarshal number 1.5 (fraction is not zero) into Go struct field Order.amount of type safem.BigInt
 func (b *BigInt) UnmarshalJSON(data []byte) error {
-	start := time.Now()
-	defer func() {
-		// Log timing for performance monitoring
-		duration := time.Since(start)
-		if duration > 10*time.Millisecond {
-			// Log slow unmarshaling for debugging
-			// TODO: Add proper metrics when metrics package is available
-		}
-	}()
+	return DefaultBigIntJSONPolicy().Unmarshal(data, b)
+}
+
+// StrictBigInt is a BigInt decoded from JSON with the zero BigIntJSONPolicy: only
+// non-negative base 10 integers, as JSON numbers or strings, or null. It is
+// written to JSON like BigInt, and every other encoding is the embedded BigInt's.
+//
+// Example:
+//
+//	type order struct {
+//		Amount safem.StrictBigInt `json:"amount"`
+//	}
+//	json.Unmarshal([]byte(`{"amount":-5}`), &o) // error: negative values are not allowed
+type StrictBigInt struct {
+	BigInt
+}
+
+// UnmarshalJSON decodes a JSON number, string or null with the zero policy.
+func (s *StrictBigInt) UnmarshalJSON(data []byte) error {
+	return BigIntJSONPolicy{}.Unmarshal(data, &s.BigInt)
+}
+
+// BigIntJSONDecoder decodes a JSON value into Target with Policy, for a policy
+// chosen at run time.
+//
+// Example:
+//
+//	var v safem.BigInt
+//	err := json.Unmarshal(data, &safem.BigIntJSONDecoder{Policy: policy, Target: &v})
+type BigIntJSONDecoder struct {
+	Policy BigIntJSONPolicy
+	Target *BigInt
+}
 
-	var val interface{}
-	if err := json.Unmarshal(data, &val); err != nil {
+// UnmarshalJSON decodes data into d.Target using d.Policy.
+func (d *BigIntJSONDecoder) UnmarshalJSON(data []byte) error {
+	if d.Target == nil {
+		return fmt.Errorf("cannot decode BigInt: no target: %w", ErrInvalidInput)
+	}
+	return d.Policy.Unmarshal(data, d.Target)
+}
+
+// Unmarshal decodes a JSON number, string or null into b using policy p.
+func (p BigIntJSONPolicy) Unmarshal(data []byte, b *BigInt) error {
+	v, err := p.decode(data)
+	if err != nil {
 		return err
 	}
+	b.Int = v
+	return nil
+}
+
+func (p BigIntJSONPolicy) decode(data []byte) (*big.Int, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, bigIntJSONError("empty input", "")
+	}
 
-	// Get big.Int from pool and initialize
-	b.Int = BigIntPool.Get().(*big.Int)
-	b.Int.SetInt64(0)
+	switch c := data[0]; {
+	case string(data) == "null":
+		return nil, nil
 
-	switch v := val.(type) {
-	case float64:
-		if math.IsNaN(v) || math.IsInf(v, 0) {
-			BigIntPool.Put(b.Int)
-			return strconv.ErrSyntax
+	case c == '"':
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
 		}
-		// Prefer direct int64 for small integers to avoid string conversion
-		if v == math.Trunc(v) && v <= math.MaxInt64 && v >= math.MinInt64 {
-			b.Int.SetInt64(int64(v))
-		} else {
-			// Log warning for float64 usage - should use string in production
-			str := strconv.FormatFloat(v, 'f', -1, 64)
-			_, ok := b.Int.SetString(str, 10)
-			if !ok {
-				BigIntPool.Put(b.Int)
-				return strconv.ErrSyntax
+		desc := "string " + strconv.Quote(s)
+		if s == "" {
+			if !p.AllowEmptyString {
+				return nil, bigIntJSONError(desc, "empty string is not allowed")
 			}
+			return nil, nil
 		}
-	case string:
-		// Pre-validate string to avoid unnecessary SetString calls
-		v = strings.Trim(v, `"`)
-		if len(v) == 0 {
-			BigIntPool.Put(b.Int)
-			b.Int = nil
-			return nil
+		return p.parse(s, desc, true)
+
+	case c == '-' || (c >= '0' && c <= '9'):
+		return p.parse(string(data), "number "+string(data), false)
+	}
+
+	if !json.Valid(data) {
+		return nil, bigIntJSONError("invalid JSON "+string(data), "")
+	}
+	return nil, bigIntJSONError(jsonKind(data), "")
+}
+
+// parse reads s as written in a JSON number or string; desc describes the JSON
+// value for errors.
+func (p BigIntJSONPolicy) parse(s, desc string, quoted bool) (*big.Int, error) {
+	digits, neg := strings.CutPrefix(s, "-")
+	var v *big.Int
+	if hex, ok := strings.CutPrefix(digits, "0x"); ok && quoted {
+		if !p.AllowHex {
+			return nil, bigIntJSONError(desc, "hex is not allowed")
 		}
+		if v = parseHexDigits(hex); v == nil {
+			return nil, bigIntJSONError(desc, "invalid hex")
+		}
+	} else if quoted && p.BareHex {
+		if v = parseHexDigits(digits); v == nil {
+			return nil, bigIntJSONError(desc, "invalid hex")
+		}
+	} else {
+		var reason string
+		if v, reason = p.parseDecimal(digits); v == nil {
+			return nil, bigIntJSONError(desc, reason)
+		}
+	}
 
-		// Try to parse as base 10 first
-		if _, ok := b.Int.SetString(v, 10); ok {
-			return nil
+	if neg {
+		// -0 is zero, not a negative value
+		if !p.AllowNegative && v.Sign() != 0 {
+			return nil, bigIntJSONError(desc, "negative values are not allowed")
 		}
+		v.Neg(v)
+	}
+	return v, nil
+}
 
-		// Try to parse as hex if it starts with 0x
-		if strings.HasPrefix(v, "0x") {
-			if _, ok := b.Int.SetString(v[2:], 16); ok {
-				return nil
-			}
+// parseDecimal reads an unsigned base 10 number in JSON number syntax, except
+// that leading zeros are allowed. On failure it returns a nil value and the reason.
+func (p BigIntJSONPolicy) parseDecimal(s string) (*big.Int, string) {
+	mant, exp, hasExp := strings.Cut(strings.ToLower(s), "e")
+	intPart, frac, hasFrac := strings.Cut(mant, ".")
+	if !isDigits(intPart) || (hasFrac && !isDigits(frac)) {
+		return nil, "not a base 10 integer"
+	}
+	if hasFrac && !p.AllowFloat {
+		return nil, "fractions are not allowed"
+	}
+
+	e := 0
+	if hasExp {
+		if !p.AllowExponent {
+			return nil, "exponent notation is not allowed"
+		}
+		expDigits := strings.TrimPrefix(strings.TrimPrefix(exp, "+"), "-")
+		if !isDigits(expDigits) {
+			return nil, "not a base 10 integer"
+		}
+		n, err := strconv.Atoi(exp)
+		if err != nil || n > maxBigIntJSONExponent || n < -maxBigIntJSONExponent {
+			return nil, "exponent is out of range"
+		}
+		e = n
+	}
+
+	// the value is intPart.frac * 10^e; it is an integer when every digit past
+	// the decimal point is zero
+	digits := intPart + frac
+	point := len(intPart) + e
+	if point < 0 {
+		point = 0
+	}
+	if point < len(digits) {
+		if strings.Trim(digits[point:], "0") != "" {
+			return nil, "fraction is not zero"
 		}
+		digits = digits[:point]
+	}
 
-		BigIntPool.Put(b.Int)
-		return strconv.ErrSyntax
-	case nil:
-		BigIntPool.Put(b.Int)
-		b.Int = nil
+	v := new(big.Int)
+	if digits != "" {
+		v.SetString(digits, 10)
+	}
+	if point > len(digits) {
+		v.Mul(v, new(big.Int).Exp(tenInt, big.NewInt(int64(point-len(digits))), nil))
+	}
+	return v, ""
+}
+
+// parseHexDigits parses unsigned hex digits, returning nil when s is empty or
+// contains anything else.
+func parseHexDigits(s string) *big.Int {
+	if s == "" || s[0] == '+' || s[0] == '-' || strings.Contains(s, "_") {
 		return nil
-	default:
-		BigIntPool.Put(b.Int)
-		return strconv.ErrSyntax
 	}
+	v, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		return nil
+	}
+	return v
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !isDigit(r) {
+			return false
+		}
+	}
+	return true
+}
 
-	return nil
+// jsonKind describes a non-numeric JSON value the way encoding/json does.
+func jsonKind(data []byte) string {
+	switch data[0] {
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	}
+	return "bool"
+}
+
+// bigIntJSONError returns a *json.UnmarshalTypeError for a BigInt, the error type
+// encoding/json adds the struct and field name to.
+func bigIntJSONError(desc, reason string) error {
+	if reason != "" {
+		desc += " (" + reason + ")"
+	}
+	return &json.UnmarshalTypeError{Value: desc, Type: reflect.TypeOf(BigInt{})}
 }
 
 // MarshalJSON outputs as string for Ethereum compatibility.
@@ -111,99 +342,197 @@ func (b BigInt) MarshalJSON() ([]byte, error) {
 	if b.Int == nil {
 		return json.Marshal(nil)
 	}
-	return json.Marshal(b.String())
+	return json.Marshal(b.Int.String())
 }
 
-// Set sets the value of BigInt from a *big.Int
-func (b *BigInt) Set(x *big.Int) *BigInt {
-	if b.Int == nil {
-		b.Int = new(big.Int)
+// MarshalJSON writes h as a quoted 0x-prefixed hex string, or null when nil.
+func (h HexBigInt) MarshalJSON() ([]byte, error) {
+	if h.Int == nil {
+		return json.Marshal(nil)
 	}
-	b.Int.Set(x)
-	return b
+	return json.Marshal(hexString(h.Int))
 }
 
-// SetString sets the value of BigInt from a string
-func (b *BigInt) SetString(s string, base int) (*BigInt, bool) {
+// MarshalJSON writes n as a bare JSON number, or null when nil.
+func (n NumberBigInt) MarshalJSON() ([]byte, error) {
+	if n.Int == nil {
+		return json.Marshal(nil)
+	}
+	return []byte(n.Int.String()), nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface, writing the value in
+// base 10. A nil value is written as empty text.
+func (b BigInt) MarshalText() ([]byte, error) {
 	if b.Int == nil {
-		b.Int = new(big.Int)
+		return []byte{}, nil
 	}
-	_, ok := b.Int.SetString(s, base)
-	return b, ok
+	return []byte(b.Int.String()), nil
 }
 
-// Cmp compares BigInt with another BigInt
-func (b *BigInt) Cmp(x *BigInt) int {
+// UnmarshalText implements the encoding.TextUnmarshaler interface. It accepts base 10
+// and 0x-prefixed hex integers; empty text leaves the value nil.
+func (b *BigInt) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		b.Int = nil
+		return nil
+	}
+	v, ok := parseBigIntText(string(text))
+	if !ok {
+		return fmt.Errorf("cannot parse %q as BigInt: %w", text, ErrInvalidString)
+	}
+	b.Int = v
+	return nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface using the
+// big.Int gob encoding. A nil value is written as empty data.
+func (b BigInt) MarshalBinary() ([]byte, error) {
 	if b.Int == nil {
-		if x.Int == nil {
-			return 0
+		return []byte{}, nil
+	}
+	return b.Int.GobEncode()
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface. Empty data
+// leaves the value nil.
+func (b *BigInt) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		b.Int = nil
+		return nil
+	}
+	v := new(big.Int)
+	if err := v.GobDecode(data); err != nil {
+		return fmt.Errorf("cannot decode binary BigInt: %s", err)
+	}
+	b.Int = v
+	return nil
+}
+
+// GobEncode implements the gob.GobEncoder interface for gob serialization.
+func (b BigInt) GobEncode() ([]byte, error) {
+	return b.MarshalBinary()
+}
+
+// GobDecode implements the gob.GobDecoder interface for gob serialization.
+func (b *BigInt) GobDecode(data []byte) error {
+	return b.UnmarshalBinary(data)
+}
+
+// Scan implements the sql.Scanner interface for database deserialization. It
+// accepts integers, integral floats, and NUMERIC or string columns holding an
+// integer such as "1000", "1000.00" or "0x3e8". NULL leaves the value nil.
+func (b *BigInt) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		b.Int = nil
+		return nil
+
+	case int64:
+		b.Int = big.NewInt(v)
+		return nil
+
+	case uint64:
+		b.Int = new(big.Int).SetUint64(v)
+		return nil
+
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) || v != math.Trunc(v) {
+			return fmt.Errorf("cannot scan %v into BigInt: not an integer", v)
 		}
-		return -1
+		b.Int, _ = new(big.Float).SetFloat64(v).Int(nil)
+		return nil
+
+	case string:
+		return b.scanString(v)
+
+	case []byte:
+		return b.scanString(string(v))
+
+	default:
+		return fmt.Errorf("could not convert value '%+v' to any known type", value)
 	}
-	if x.Int == nil {
-		return 1
+}
+
+func (b *BigInt) scanString(s string) error {
+	s = unquoteIfQuoted(s)
+	if v, ok := parseBigIntText(s); ok {
+		b.Int = v
+		return nil
 	}
-	return b.Int.Cmp(x.Int)
+
+	// NUMERIC columns with a scale come back as "1000.00"
+	d, err := NewFromString(s)
+	if err != nil {
+		return fmt.Errorf("cannot scan %q into BigInt: %w", s, ErrInvalidString)
+	}
+	if !d.IsInteger() {
+		return fmt.Errorf("cannot scan %q into BigInt: not an integer", s)
+	}
+	b.Int = d.BigInt()
+	return nil
 }
 
-// Add adds two BigInt values
-func (b *BigInt) Add(x, y *BigInt) *BigInt {
+// Value implements the driver.Valuer interface for database serialization. The
+// value is written as a base 10 string, which NUMERIC and text columns accept; a nil
+// value is written as NULL.
+func (b BigInt) Value() (driver.Value, error) {
 	if b.Int == nil {
-		b.Int = new(big.Int)
-	}
-	if x.Int == nil {
-		b.Int.Set(y.Int)
-	} else if y.Int == nil {
-		b.Int.Set(x.Int)
-	} else {
-		b.Int.Add(x.Int, y.Int)
+		return nil, nil
 	}
-	return b
+	return b.Int.String(), nil
 }
 
-// Sub subtracts two BigInt values
-func (b *BigInt) Sub(x, y *BigInt) *BigInt {
+// Format implements the fmt.Formatter interface.
+//
+// The integer verbs (%d, %b, %o, %O, %x, %X) are handled by big.Int.Format.
+// %v, %s, %q, %f, %e and %g format the value as an integral Decimal, with the
+// same precision, width and flag handling as Decimal.Format. A nil BigInt
+// prints as "<nil>".
+func (b BigInt) Format(s fmt.State, verb rune) {
 	if b.Int == nil {
-		b.Int = new(big.Int)
+		writePadded(s, "", "<nil>", false)
+		return
 	}
-	if x.Int == nil {
-		if y.Int == nil {
-			b.Int.SetInt64(0)
-		} else {
-			b.Int.Neg(y.Int)
-		}
-	} else if y.Int == nil {
-		b.Int.Set(x.Int)
-	} else {
-		b.Int.Sub(x.Int, y.Int)
+
+	switch verb {
+	case 'd', 'b', 'o', 'O', 'x', 'X':
+		b.Int.Format(s, verb)
+	default:
+		NewFromBigInt(b.Int, 0).Format(s, verb)
 	}
-	return b
 }
 
-// Mul multiplies two BigInt values
-func (b *BigInt) Mul(x, y *BigInt) *BigInt {
+// Set sets the value of BigInt from a *big.Int
+func (b *BigInt) Set(x *big.Int) *BigInt {
 	if b.Int == nil {
 		b.Int = new(big.Int)
 	}
-	if x.Int == nil || y.Int == nil {
-		b.Int.SetInt64(0)
-	} else {
-		b.Int.Mul(x.Int, y.Int)
-	}
+	b.Int.Set(x)
 	return b
 }
 
-// Div divides two BigInt values
-func (b *BigInt) Div(x, y *BigInt) *BigInt {
+// SetString sets the value of BigInt from a string
+func (b *BigInt) SetString(s string, base int) (*BigInt, bool) {
 	if b.Int == nil {
 		b.Int = new(big.Int)
 	}
-	if x.Int == nil || y.Int == nil || y.Int.Sign() == 0 {
-		b.Int.SetInt64(0)
-	} else {
-		b.Int.Div(x.Int, y.Int)
+	_, ok := b.Int.SetString(s, base)
+	return b, ok
+}
+
+// Cmp compares BigInt with another BigInt
+func (b *BigInt) Cmp(x *BigInt) int {
+	if b.Int == nil {
+		if x.Int == nil {
+			return 0
+		}
+		return -1
 	}
-	return b
+	if x.Int == nil {
+		return 1
+	}
+	return b.Int.Cmp(x.Int)
 }
 
 // Int64 returns the int64 representation of BigInt
@@ -263,6 +592,115 @@ func NewBigIntFromUint64(x uint64) *BigInt {
 	return &BigInt{Int: new(big.Int).SetUint64(x)}
 }
 
+// NullBigInt represents a nullable BigInt with compatibility for
+// scanning null values from the database.
+type NullBigInt struct {
+	BigInt BigInt
+	Valid  bool
+}
+
+// NewNullBigInt returns a NullBigInt holding a copy of x, valid unless x is nil.
+func NewNullBigInt(x *big.Int) NullBigInt {
+	return NullBigInt{
+		BigInt: *NewBigInt(x),
+		Valid:  x != nil,
+	}
+}
+
+// Scan implements the sql.Scanner interface for database deserialization.
+func (n *NullBigInt) Scan(value interface{}) error {
+	if value == nil {
+		n.BigInt.Int = nil
+		n.Valid = false
+		return nil
+	}
+	if err := n.BigInt.Scan(value); err != nil {
+		n.Valid = false
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// Value implements the driver.Valuer interface for database serialization.
+func (n NullBigInt) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.BigInt.Value()
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (n *NullBigInt) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		n.BigInt.Int = nil
+		n.Valid = false
+		return nil
+	}
+	if err := n.BigInt.UnmarshalJSON(data); err != nil {
+		n.Valid = false
+		return err
+	}
+	n.Valid = n.BigInt.Int != nil
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (n NullBigInt) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return n.BigInt.MarshalJSON()
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. Empty text
+// is treated as null.
+func (n *NullBigInt) UnmarshalText(text []byte) error {
+	if err := n.BigInt.UnmarshalText(text); err != nil {
+		n.Valid = false
+		return err
+	}
+	n.Valid = n.BigInt.Int != nil
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (n NullBigInt) MarshalText() ([]byte, error) {
+	if !n.Valid {
+		return []byte{}, nil
+	}
+	return n.BigInt.MarshalText()
+}
+
+// parseBigIntText parses a base 10 integer or a 0x-prefixed hex integer, either
+// with an optional leading minus sign.
+func parseBigIntText(s string) (*big.Int, bool) {
+	digits, neg := strings.CutPrefix(s, "-")
+	var v *big.Int
+	if hex, ok := strings.CutPrefix(digits, "0x"); ok {
+		v = parseHexDigits(hex)
+	} else if isDigits(digits) {
+		v, _ = new(big.Int).SetString(digits, 10)
+	}
+	if v == nil {
+		return nil, false
+	}
+	if neg {
+		v.Neg(v)
+	}
+	return v, true
+}
+
+// hexString returns x as a 0x-prefixed hex string, with a leading minus sign
+// for negative values.
+func hexString(x *big.Int) string {
+	s := x.Text(16)
+	if s[0] == '-' {
+		return "-0x" + s[1:]
+	}
+	return "0x" + s
+}
+
 // isNumericString checks if a string is a valid decimal number (basic validation)
 func isNumericString(s string) bool {
 	if len(s) == 0 {
@@ -0,0 +1,370 @@
+package safem
+
+import (
+	"math/big"
+)
+
+// BigIntNilMode selects how BigInt arithmetic treats a nil operand, i.e. a nil
+// *BigInt or a BigInt whose Int is nil. The arithmetic is a method of the mode,
+// so the choice is made explicitly at every call:
+//
+//	NilPropagates.Add(sum, price, fee) // sum.Int is nil when price or fee is nil
+//	NilAsZero.Quo(q, x, y)             // a nil x is 0, a nil y a division by zero
+//
+// As with big.Int, the result is stored in the first argument z, which is also
+// returned; z may alias an operand. A nil z is allocated, and when z.Int is nil
+// and a value is produced a new big.Int is allocated. Exp's modulus is the one
+// exception to the mode: a nil modulus means no modulus, as with big.Int.Exp.
+//
+// The BigInt methods Add, Sub, Mul, Div and CheckedDiv use NilAsZero.
+type BigIntNilMode uint8
+
+const (
+	// NilAsZero treats nil operands as zero, so a nil divisor is a division by zero.
+	NilAsZero BigIntNilMode = iota
+
+	// NilPropagates makes the result nil whenever an operand is nil, like NULL in
+	// SQL. A nil operand is never an error, not even a nil divisor.
+	NilPropagates
+)
+
+// arg returns the value of x, or zero when x is nil and m treats nil as zero. ok
+// is false when x is nil and m propagates nil.
+func (m BigIntNilMode) arg(x *BigInt) (v *big.Int, ok bool) {
+	if x == nil || x.Int == nil {
+		if m == NilPropagates {
+			return nil, false
+		}
+		return zeroInt, true
+	}
+	return x.Int, true
+}
+
+// result returns z, allocating it when nil.
+func result(z *BigInt) *BigInt {
+	if z == nil {
+		return new(BigInt)
+	}
+	return z
+}
+
+// target returns b.Int, allocating it when nil.
+func (b *BigInt) target() *big.Int {
+	if b.Int == nil {
+		b.Int = new(big.Int)
+	}
+	return b.Int
+}
+
+func (m BigIntNilMode) unary(z, x *BigInt, op func(z, x *big.Int) *big.Int) *BigInt {
+	z = result(z)
+	xv, ok := m.arg(x)
+	if !ok {
+		z.Int = nil
+		return z
+	}
+	op(z.target(), xv)
+	return z
+}
+
+func (m BigIntNilMode) binary(z, x, y *BigInt, op func(z, x, y *big.Int) *big.Int) *BigInt {
+	z = result(z)
+	xv, okx := m.arg(x)
+	yv, oky := m.arg(y)
+	if !okx || !oky {
+		z.Int = nil
+		return z
+	}
+	op(z.target(), xv, yv)
+	return z
+}
+
+// division is binary for operations that are undefined for y == 0.
+func (m BigIntNilMode) division(z, x, y *BigInt, op func(z, x, y *big.Int) *big.Int) (*BigInt, error) {
+	z = result(z)
+	xv, okx := m.arg(x)
+	yv, oky := m.arg(y)
+	if !okx || !oky {
+		z.Int = nil
+		return z, nil
+	}
+	if yv.Sign() == 0 {
+		z.target().SetInt64(0)
+		return z, ErrDivisionByZero
+	}
+	op(z.target(), xv, yv)
+	return z, nil
+}
+
+// Add adds two BigInt values, treating nil as zero.
+func (b *BigInt) Add(x, y *BigInt) *BigInt {
+	return NilAsZero.Add(b, x, y)
+}
+
+// Sub subtracts two BigInt values, treating nil as zero.
+func (b *BigInt) Sub(x, y *BigInt) *BigInt {
+	return NilAsZero.Sub(b, x, y)
+}
+
+// Mul multiplies two BigInt values, treating nil as zero.
+func (b *BigInt) Mul(x, y *BigInt) *BigInt {
+	return NilAsZero.Mul(b, x, y)
+}
+
+// Div sets b to the Euclidean quotient x div y, treating nil as zero. Division
+// by zero sets b to 0; see CheckedDiv.
+func (b *BigInt) Div(x, y *BigInt) *BigInt {
+	return NilAsZero.Div(b, x, y)
+}
+
+// CheckedDiv is like Div but returns ErrDivisionByZero when y is zero or nil.
+func (b *BigInt) CheckedDiv(x, y *BigInt) (*BigInt, error) {
+	return NilAsZero.CheckedDiv(b, x, y)
+}
+
+// Add sets z to x + y and returns z.
+func (m BigIntNilMode) Add(z, x, y *BigInt) *BigInt {
+	return m.binary(z, x, y, (*big.Int).Add)
+}
+
+// Sub sets z to x - y and returns z.
+func (m BigIntNilMode) Sub(z, x, y *BigInt) *BigInt {
+	return m.binary(z, x, y, (*big.Int).Sub)
+}
+
+// Mul sets z to x * y and returns z.
+func (m BigIntNilMode) Mul(z, x, y *BigInt) *BigInt {
+	return m.binary(z, x, y, (*big.Int).Mul)
+}
+
+// Div sets z to the Euclidean quotient x div y and returns z. Division by zero
+// sets z to 0; see CheckedDiv.
+func (m BigIntNilMode) Div(z, x, y *BigInt) *BigInt {
+	z, _ = m.division(z, x, y, (*big.Int).Div)
+	return z
+}
+
+// CheckedDiv is like Div but returns ErrDivisionByZero when y is zero.
+func (m BigIntNilMode) CheckedDiv(z, x, y *BigInt) (*BigInt, error) {
+	return m.division(z, x, y, (*big.Int).Div)
+}
+
+// Mod sets z to the Euclidean modulus x mod y, which is never negative, and
+// returns z. Division by zero sets z to 0; see CheckedMod.
+func (m BigIntNilMode) Mod(z, x, y *BigInt) *BigInt {
+	z, _ = m.division(z, x, y, (*big.Int).Mod)
+	return z
+}
+
+// CheckedMod is like Mod but returns ErrDivisionByZero when y is zero.
+func (m BigIntNilMode) CheckedMod(z, x, y *BigInt) (*BigInt, error) {
+	return m.division(z, x, y, (*big.Int).Mod)
+}
+
+// Quo sets z to the quotient x/y truncated towards zero and returns z. Division
+// by zero sets z to 0; see CheckedQuo.
+//
+// Example:
+//
+//	x, y := NewBigIntFromInt64(-7), NewBigIntFromInt64(2)
+//	NilAsZero.Quo(nil, x, y) // output: -3
+//	NilAsZero.Div(nil, x, y) // output: -4
+func (m BigIntNilMode) Quo(z, x, y *BigInt) *BigInt {
+	z, _ = m.division(z, x, y, (*big.Int).Quo)
+	return z
+}
+
+// CheckedQuo is like Quo but returns ErrDivisionByZero when y is zero.
+func (m BigIntNilMode) CheckedQuo(z, x, y *BigInt) (*BigInt, error) {
+	return m.division(z, x, y, (*big.Int).Quo)
+}
+
+// Rem sets z to the remainder x%y, which has the sign of x, and returns z.
+// Division by zero sets z to 0; see CheckedRem.
+func (m BigIntNilMode) Rem(z, x, y *BigInt) *BigInt {
+	z, _ = m.division(z, x, y, (*big.Int).Rem)
+	return z
+}
+
+// CheckedRem is like Rem but returns ErrDivisionByZero when y is zero.
+func (m BigIntNilMode) CheckedRem(z, x, y *BigInt) (*BigInt, error) {
+	return m.division(z, x, y, (*big.Int).Rem)
+}
+
+// QuoRem sets z to the truncated quotient x/y and r to the remainder x%y, and
+// returns the pair (z, r), as Quo and Rem. A nil r is allocated like z. Division
+// by zero sets both to 0; see CheckedQuoRem.
+func (m BigIntNilMode) QuoRem(z, x, y, r *BigInt) (*BigInt, *BigInt) {
+	z, r, _ = m.CheckedQuoRem(z, x, y, r)
+	return z, r
+}
+
+// CheckedQuoRem is like QuoRem but returns ErrDivisionByZero when y is zero.
+func (m BigIntNilMode) CheckedQuoRem(z, x, y, r *BigInt) (*BigInt, *BigInt, error) {
+	return m.pair(z, x, y, r, (*big.Int).QuoRem)
+}
+
+// DivMod sets z to the Euclidean quotient x div y and r to the modulus x mod y,
+// and returns the pair (z, r), as Div and Mod. A nil r is allocated like z.
+// Division by zero sets both to 0; see CheckedDivMod.
+func (m BigIntNilMode) DivMod(z, x, y, r *BigInt) (*BigInt, *BigInt) {
+	z, r, _ = m.CheckedDivMod(z, x, y, r)
+	return z, r
+}
+
+// CheckedDivMod is like DivMod but returns ErrDivisionByZero when y is zero.
+func (m BigIntNilMode) CheckedDivMod(z, x, y, r *BigInt) (*BigInt, *BigInt, error) {
+	return m.pair(z, x, y, r, (*big.Int).DivMod)
+}
+
+func (m BigIntNilMode) pair(z, x, y, r *BigInt, op func(z, x, y, r *big.Int) (*big.Int, *big.Int)) (*BigInt, *BigInt, error) {
+	z, r = result(z), result(r)
+	xv, okx := m.arg(x)
+	yv, oky := m.arg(y)
+	if !okx || !oky {
+		z.Int, r.Int = nil, nil
+		return z, r, nil
+	}
+	if yv.Sign() == 0 {
+		z.target().SetInt64(0)
+		r.target().SetInt64(0)
+		return z, r, ErrDivisionByZero
+	}
+	// the operands may alias either result, so compute into fresh values
+	q, rem := op(new(big.Int), xv, yv, new(big.Int))
+	z.Int, r.Int = q, rem
+	return z, r, nil
+}
+
+// Exp sets z to x**y mod |n| and returns z, as big.Int.Exp. A nil or zero n
+// means no modulus, in either mode. For y <= 0 the result is 1, unless n is set
+// and y < 0, in which case the modular inverse of x is raised to -y; when that
+// inverse does not exist z is set to 0.
+func (m BigIntNilMode) Exp(z, x, y, n *BigInt) *BigInt {
+	z = result(z)
+	xv, okx := m.arg(x)
+	yv, oky := m.arg(y)
+	if !okx || !oky {
+		z.Int = nil
+		return z
+	}
+	var nv *big.Int
+	if n != nil {
+		nv = n.Int
+	}
+	if v := z.target(); v.Exp(xv, yv, nv) == nil {
+		v.SetInt64(0)
+	}
+	return z
+}
+
+// ModInverse sets z to the multiplicative inverse of g in the ring ℤ/nℤ and
+// returns z. When the inverse does not exist or n is zero, z is set to 0; see
+// CheckedModInverse.
+func (m BigIntNilMode) ModInverse(z, g, n *BigInt) *BigInt {
+	z, _ = m.CheckedModInverse(z, g, n)
+	return z
+}
+
+// CheckedModInverse is like ModInverse but returns ErrDivisionByZero when n is
+// zero and ErrNoInverse when g and n are not relatively prime.
+func (m BigIntNilMode) CheckedModInverse(z, g, n *BigInt) (*BigInt, error) {
+	z = result(z)
+	gv, okg := m.arg(g)
+	nv, okn := m.arg(n)
+	if !okg || !okn {
+		z.Int = nil
+		return z, nil
+	}
+	if nv.Sign() == 0 {
+		z.target().SetInt64(0)
+		return z, ErrDivisionByZero
+	}
+	// big.Int.ModInverse leaves its receiver unchanged on failure
+	inv := new(big.Int).ModInverse(gv, nv)
+	if inv == nil {
+		z.target().SetInt64(0)
+		return z, ErrNoInverse
+	}
+	z.target().Set(inv)
+	return z, nil
+}
+
+// Lsh sets z to x << n and returns z.
+func (m BigIntNilMode) Lsh(z, x *BigInt, n uint) *BigInt {
+	return m.unary(z, x, func(z, x *big.Int) *big.Int { return z.Lsh(x, n) })
+}
+
+// Rsh sets z to x >> n and returns z. Like big.Int.Rsh, negative values are
+// shifted arithmetically, rounding towards negative infinity.
+func (m BigIntNilMode) Rsh(z, x *BigInt, n uint) *BigInt {
+	return m.unary(z, x, func(z, x *big.Int) *big.Int { return z.Rsh(x, n) })
+}
+
+// And sets z to x & y and returns z. Negative values use two's complement, as
+// with big.Int.
+func (m BigIntNilMode) And(z, x, y *BigInt) *BigInt {
+	return m.binary(z, x, y, (*big.Int).And)
+}
+
+// Or sets z to x | y and returns z.
+func (m BigIntNilMode) Or(z, x, y *BigInt) *BigInt {
+	return m.binary(z, x, y, (*big.Int).Or)
+}
+
+// Xor sets z to x ^ y and returns z.
+func (m BigIntNilMode) Xor(z, x, y *BigInt) *BigInt {
+	return m.binary(z, x, y, (*big.Int).Xor)
+}
+
+// Not sets z to ^x, which is -x - 1, and returns z.
+func (m BigIntNilMode) Not(z, x *BigInt) *BigInt {
+	return m.unary(z, x, (*big.Int).Not)
+}
+
+// Abs sets z to |x| and returns z.
+func (m BigIntNilMode) Abs(z, x *BigInt) *BigInt {
+	return m.unary(z, x, (*big.Int).Abs)
+}
+
+// Neg sets z to -x and returns z.
+func (m BigIntNilMode) Neg(z, x *BigInt) *BigInt {
+	return m.unary(z, x, (*big.Int).Neg)
+}
+
+// Sqrt sets z to ⌊√x⌋ and returns z. A negative x sets z to 0; see CheckedSqrt.
+func (m BigIntNilMode) Sqrt(z, x *BigInt) *BigInt {
+	z, _ = m.CheckedSqrt(z, x)
+	return z
+}
+
+// CheckedSqrt is like Sqrt but returns ErrNegativeInput when x is negative.
+func (m BigIntNilMode) CheckedSqrt(z, x *BigInt) (*BigInt, error) {
+	z = result(z)
+	xv, ok := m.arg(x)
+	if !ok {
+		z.Int = nil
+		return z, nil
+	}
+	if xv.Sign() < 0 {
+		z.target().SetInt64(0)
+		return z, ErrNegativeInput
+	}
+	z.target().Sqrt(xv)
+	return z, nil
+}
+
+// GCD sets z to the greatest common divisor of |x| and |y| and returns z.
+// GCD(0, 0) is 0.
+func (m BigIntNilMode) GCD(z, x, y *BigInt) *BigInt {
+	return m.binary(z, x, y, func(z, x, y *big.Int) *big.Int { return z.GCD(nil, nil, x, y) })
+}
+
+// BitLen returns the length of the absolute value of b in bits. A nil value has
+// length 0.
+func (b *BigInt) BitLen() int {
+	if b.Int == nil {
+		return 0
+	}
+	return b.Int.BitLen()
+}
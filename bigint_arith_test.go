@@ -0,0 +1,307 @@
+package safem
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// bigIntOrNil returns nil for "nil" and the parsed value otherwise.
+func bigIntOrNil(s string) *BigInt {
+	if s == "nil" {
+		return &BigInt{}
+	}
+	b, ok := NewBigIntFromString(s, 10)
+	if !ok {
+		panic("invalid test value " + s)
+	}
+	return b
+}
+
+func bigIntString(b *BigInt) string {
+	if b.Int == nil {
+		return "nil"
+	}
+	return b.Int.String()
+}
+
+var binaryBigIntOps = []struct {
+	name string
+	op   func(m BigIntNilMode, z, x, y *BigInt) *BigInt
+	ref  func(x, y *big.Int) *big.Int // nil when undefined
+}{
+	{"Add", BigIntNilMode.Add, func(x, y *big.Int) *big.Int { return new(big.Int).Add(x, y) }},
+	{"Sub", BigIntNilMode.Sub, func(x, y *big.Int) *big.Int { return new(big.Int).Sub(x, y) }},
+	{"Mul", BigIntNilMode.Mul, func(x, y *big.Int) *big.Int { return new(big.Int).Mul(x, y) }},
+	{"Div", BigIntNilMode.Div, checkedRef((*big.Int).Div)},
+	{"Mod", BigIntNilMode.Mod, checkedRef((*big.Int).Mod)},
+	{"Quo", BigIntNilMode.Quo, checkedRef((*big.Int).Quo)},
+	{"Rem", BigIntNilMode.Rem, checkedRef((*big.Int).Rem)},
+	{"And", BigIntNilMode.And, func(x, y *big.Int) *big.Int { return new(big.Int).And(x, y) }},
+	{"Or", BigIntNilMode.Or, func(x, y *big.Int) *big.Int { return new(big.Int).Or(x, y) }},
+	{"Xor", BigIntNilMode.Xor, func(x, y *big.Int) *big.Int { return new(big.Int).Xor(x, y) }},
+	{"GCD", BigIntNilMode.GCD, func(x, y *big.Int) *big.Int { return new(big.Int).GCD(nil, nil, x, y) }},
+	{"ModInverse", BigIntNilMode.ModInverse, func(x, y *big.Int) *big.Int {
+		if y.Sign() == 0 {
+			return nil
+		}
+		return new(big.Int).ModInverse(x, y)
+	}},
+	{"Exp", func(m BigIntNilMode, z, x, y *BigInt) *BigInt { return m.Exp(z, x, y, nil) }, func(x, y *big.Int) *big.Int { return new(big.Int).Exp(x, y, nil) }},
+}
+
+func checkedRef(op func(z, x, y *big.Int) *big.Int) func(x, y *big.Int) *big.Int {
+	return func(x, y *big.Int) *big.Int {
+		if y.Sign() == 0 {
+			return nil
+		}
+		return op(new(big.Int), x, y)
+	}
+}
+
+// Every binary operation against every nil combination, in both nil modes.
+// Undefined results are 0.
+func TestBigIntBinaryOpsNilCombinations(t *testing.T) {
+	operands := [][2]string{
+		{"nil", "nil"},
+		{"nil", "7"},
+		{"-12", "nil"},
+		{"-12", "7"},
+		{"12", "5"},
+		{"0", "0"},
+	}
+
+	for _, op := range binaryBigIntOps {
+		for _, mode := range []BigIntNilMode{NilAsZero, NilPropagates} {
+			for _, ops := range operands {
+				x, y := bigIntOrNil(ops[0]), bigIntOrNil(ops[1])
+
+				expected := "nil"
+				if mode == NilAsZero || (x.Int != nil && y.Int != nil) {
+					xv, yv := new(big.Int), new(big.Int)
+					if x.Int != nil {
+						xv.Set(x.Int)
+					}
+					if y.Int != nil {
+						yv.Set(y.Int)
+					}
+					expected = "0"
+					if r := op.ref(xv, yv); r != nil {
+						expected = r.String()
+					}
+				}
+
+				for _, z := range []*BigInt{nil, {}, {Int: big.NewInt(99)}} {
+					got := bigIntString(op.op(mode, z, x, y))
+					if got != expected {
+						t.Errorf("%s(%s, %s) mode %d: Expected %s, got %s", op.name, ops[0], ops[1], mode, expected, got)
+					}
+				}
+				if bigIntString(x) != ops[0] || bigIntString(y) != ops[1] {
+					t.Errorf("%s(%s, %s) modified an operand", op.name, ops[0], ops[1])
+				}
+			}
+		}
+	}
+}
+
+func TestBigIntUnaryOpsNilCombinations(t *testing.T) {
+	ops := []struct {
+		name string
+		op   func(m BigIntNilMode, z, x *BigInt) *BigInt
+		ref  func(x *big.Int) *big.Int
+	}{
+		{"Abs", BigIntNilMode.Abs, func(x *big.Int) *big.Int { return new(big.Int).Abs(x) }},
+		{"Neg", BigIntNilMode.Neg, func(x *big.Int) *big.Int { return new(big.Int).Neg(x) }},
+		{"Not", BigIntNilMode.Not, func(x *big.Int) *big.Int { return new(big.Int).Not(x) }},
+		{"Lsh", func(m BigIntNilMode, z, x *BigInt) *BigInt { return m.Lsh(z, x, 70) }, func(x *big.Int) *big.Int { return new(big.Int).Lsh(x, 70) }},
+		{"Rsh", func(m BigIntNilMode, z, x *BigInt) *BigInt { return m.Rsh(z, x, 2) }, func(x *big.Int) *big.Int { return new(big.Int).Rsh(x, 2) }},
+		{"Sqrt", BigIntNilMode.Sqrt, func(x *big.Int) *big.Int {
+			if x.Sign() < 0 {
+				return big.NewInt(0)
+			}
+			return new(big.Int).Sqrt(x)
+		}},
+	}
+
+	for _, op := range ops {
+		for _, mode := range []BigIntNilMode{NilAsZero, NilPropagates} {
+			for _, s := range []string{"nil", "0", "17", "-17"} {
+				x := bigIntOrNil(s)
+				expected := "nil"
+				if mode == NilAsZero || x.Int != nil {
+					xv := new(big.Int)
+					if x.Int != nil {
+						xv.Set(x.Int)
+					}
+					expected = op.ref(xv).String()
+				}
+
+				got := bigIntString(op.op(mode, new(BigInt), x))
+				if got != expected {
+					t.Errorf("%s(%s) mode %d: Expected %s, got %s", op.name, s, mode, expected, got)
+				}
+			}
+		}
+	}
+}
+
+func TestBigIntPairOpsNilCombinations(t *testing.T) {
+	tests := []struct {
+		mode      BigIntNilMode
+		x, y      string
+		quo, rem  string
+		div, mod  string
+		expectErr bool
+	}{
+		{NilAsZero, "-7", "2", "-3", "-1", "-4", "1", false},
+		{NilAsZero, "7", "-2", "-3", "1", "-3", "1", false},
+		{NilAsZero, "nil", "2", "0", "0", "0", "0", false},
+		{NilAsZero, "7", "nil", "0", "0", "0", "0", true},
+		{NilAsZero, "nil", "nil", "0", "0", "0", "0", true},
+		{NilAsZero, "7", "0", "0", "0", "0", "0", true},
+		{NilPropagates, "-7", "2", "-3", "-1", "-4", "1", false},
+		{NilPropagates, "nil", "2", "nil", "nil", "nil", "nil", false},
+		{NilPropagates, "7", "nil", "nil", "nil", "nil", "nil", false},
+		{NilPropagates, "nil", "nil", "nil", "nil", "nil", "nil", false},
+		{NilPropagates, "7", "0", "0", "0", "0", "0", true},
+	}
+
+	for _, tt := range tests {
+		x, y := bigIntOrNil(tt.x), bigIntOrNil(tt.y)
+
+		q, r, err := tt.mode.CheckedQuoRem(new(BigInt), x, y, new(BigInt))
+		if bigIntString(q) != tt.quo || bigIntString(r) != tt.rem || (err != nil) != tt.expectErr {
+			t.Errorf("QuoRem(%s, %s) mode %d: Expected %s %s, got %s %s %v", tt.x, tt.y, tt.mode, tt.quo, tt.rem, bigIntString(q), bigIntString(r), err)
+		}
+
+		d, m, err := tt.mode.CheckedDivMod(new(BigInt), x, y, new(BigInt))
+		if bigIntString(d) != tt.div || bigIntString(m) != tt.mod || (err != nil) != tt.expectErr {
+			t.Errorf("DivMod(%s, %s) mode %d: Expected %s %s, got %s %s %v", tt.x, tt.y, tt.mode, tt.div, tt.mod, bigIntString(d), bigIntString(m), err)
+		}
+		if err != nil && !errors.Is(err, ErrDivisionByZero) {
+			t.Errorf("Expected ErrDivisionByZero, got %v", err)
+		}
+
+		// nil results are allocated
+		q, r = tt.mode.QuoRem(nil, x, y, nil)
+		if bigIntString(q) != tt.quo || bigIntString(r) != tt.rem {
+			t.Errorf("QuoRem(%s, %s) mode %d: Expected %s %s, got %s %s", tt.x, tt.y, tt.mode, tt.quo, tt.rem, bigIntString(q), bigIntString(r))
+		}
+		d, m = tt.mode.DivMod(nil, x, y, nil)
+		if bigIntString(d) != tt.div || bigIntString(m) != tt.mod {
+			t.Errorf("DivMod(%s, %s) mode %d: Expected %s %s, got %s %s", tt.x, tt.y, tt.mode, tt.div, tt.mod, bigIntString(d), bigIntString(m))
+		}
+	}
+}
+
+func TestBigIntQuoRemAliasing(t *testing.T) {
+	x := NewBigIntFromInt64(17)
+	y := NewBigIntFromInt64(5)
+	q, r := NilAsZero.QuoRem(x, x, y, y)
+	if bigIntString(q) != "3" || bigIntString(r) != "2" {
+		t.Errorf("Expected 3 2, got %s %s", bigIntString(q), bigIntString(r))
+	}
+}
+
+func TestBigIntCheckedErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		op       func() (*BigInt, error)
+		expected string
+		err      error
+	}{
+		{"Div", func() (*BigInt, error) { return new(BigInt).CheckedDiv(bigIntOrNil("7"), bigIntOrNil("0")) }, "0", ErrDivisionByZero},
+		{"Div nil divisor", func() (*BigInt, error) { return new(BigInt).CheckedDiv(bigIntOrNil("7"), nil) }, "0", ErrDivisionByZero},
+		{"Div nil divisor propagates", func() (*BigInt, error) {
+			return NilPropagates.CheckedDiv(nil, bigIntOrNil("7"), nil)
+		}, "nil", nil},
+		{"Mod", func() (*BigInt, error) { return NilAsZero.CheckedMod(nil, bigIntOrNil("-7"), bigIntOrNil("0")) }, "0", ErrDivisionByZero},
+		{"Quo", func() (*BigInt, error) { return NilAsZero.CheckedQuo(nil, bigIntOrNil("-7"), bigIntOrNil("2")) }, "-3", nil},
+		{"Rem", func() (*BigInt, error) { return NilAsZero.CheckedRem(nil, bigIntOrNil("-7"), bigIntOrNil("0")) }, "0", ErrDivisionByZero},
+		{"ModInverse", func() (*BigInt, error) { return NilAsZero.CheckedModInverse(nil, bigIntOrNil("3"), bigIntOrNil("11")) }, "4", nil},
+		{"ModInverse not coprime", func() (*BigInt, error) {
+			return NilAsZero.CheckedModInverse(nil, bigIntOrNil("4"), bigIntOrNil("8"))
+		}, "0", ErrNoInverse},
+		{"ModInverse zero modulus", func() (*BigInt, error) {
+			return NilAsZero.CheckedModInverse(nil, bigIntOrNil("3"), bigIntOrNil("0"))
+		}, "0", ErrDivisionByZero},
+		{"Sqrt", func() (*BigInt, error) { return NilAsZero.CheckedSqrt(nil, bigIntOrNil("99")) }, "9", nil},
+		{"Sqrt negative", func() (*BigInt, error) { return NilAsZero.CheckedSqrt(nil, bigIntOrNil("-4")) }, "0", ErrNegativeInput},
+		{"Sqrt nil propagates", func() (*BigInt, error) { return NilPropagates.CheckedSqrt(nil, nil) }, "nil", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.op()
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Expected error %v, got %v", tt.err, err)
+			}
+			if bigIntString(got) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, bigIntString(got))
+			}
+		})
+	}
+}
+
+func TestBigIntExp(t *testing.T) {
+	tests := []struct {
+		mode     BigIntNilMode
+		x, y, m  string
+		expected string
+	}{
+		{NilAsZero, "3", "5", "nil", "243"},
+		{NilAsZero, "3", "5", "7", "5"},
+		{NilAsZero, "3", "5", "0", "243"},
+		{NilAsZero, "3", "-1", "7", "5"},
+		{NilAsZero, "2", "-1", "4", "0"},
+		{NilAsZero, "nil", "0", "nil", "1"},
+		{NilAsZero, "5", "nil", "nil", "1"},
+		{NilPropagates, "3", "5", "nil", "243"},
+		{NilPropagates, "nil", "5", "7", "nil"},
+		{NilPropagates, "3", "nil", "7", "nil"},
+	}
+
+	for _, tt := range tests {
+		got := tt.mode.Exp(nil, bigIntOrNil(tt.x), bigIntOrNil(tt.y), bigIntOrNil(tt.m))
+		if bigIntString(got) != tt.expected {
+			t.Errorf("Exp(%s, %s, %s) mode %d: Expected %s, got %s", tt.x, tt.y, tt.m, tt.mode, tt.expected, bigIntString(got))
+		}
+	}
+}
+
+func TestBigIntBitLen(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int
+	}{
+		{"nil", 0},
+		{"0", 0},
+		{"255", 8},
+		{"-256", 9},
+		{"115792089237316195423570985008687907853269984665640564039457584007913129639935", 256},
+	}
+
+	for _, tt := range tests {
+		if got := bigIntOrNil(tt.value).BitLen(); got != tt.expected {
+			t.Errorf("BitLen(%s): Expected %d, got %d", tt.value, tt.expected, got)
+		}
+	}
+}
+
+// The big.Int methods without a BigInt counterpart stay promoted unchanged.
+func TestBigIntPromotedMethods(t *testing.T) {
+	b := NewBigIntFromInt64(0)
+	b.Quo(big.NewInt(-7), big.NewInt(2))
+	if b.Int.String() != "-3" {
+		t.Errorf("Expected -3, got %s", b.Int)
+	}
+	b.Exp(big.NewInt(3), big.NewInt(5), big.NewInt(7))
+	if b.Int.String() != "5" {
+		t.Errorf("Expected 5, got %s", b.Int)
+	}
+	q, r := b.QuoRem(big.NewInt(17), big.NewInt(5), new(big.Int))
+	if q.String() != "3" || r.String() != "2" {
+		t.Errorf("Expected 3 2, got %s %s", q, r)
+	}
+}
@@ -0,0 +1,581 @@
+package safem
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+)
+
+func TestBigIntMarshalJSONModes(t *testing.T) {
+	large, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	hex := func(x *big.Int) interface{} { return HexBigInt{BigInt{x}} }
+	number := func(x *big.Int) interface{} { return NumberBigInt{BigInt{x}} }
+	str := func(x *big.Int) interface{} { return BigInt{x} }
+	tests := []struct {
+		name     string
+		value    *big.Int
+		wrap     func(*big.Int) interface{}
+		expected string
+	}{
+		{"string", big.NewInt(1000), str, `"1000"`},
+		{"string negative", big.NewInt(-1000), str, `"-1000"`},
+		{"hex", big.NewInt(1000), hex, `"0x3e8"`},
+		{"hex zero", big.NewInt(0), hex, `"0x0"`},
+		{"hex negative", big.NewInt(-1000), hex, `"-0x3e8"`},
+		{"number", big.NewInt(1000), number, `1000`},
+		{"number 2^53", big.NewInt(1 << 53), number, `9007199254740992`},
+		{"number large", large, number, `123456789012345678901234567890`},
+		{"hex nil", nil, hex, `null`},
+		{"number nil", nil, number, `null`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.wrap(tt.value))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+
+			var back BigInt
+			if err := json.Unmarshal(got, &back); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if back.Cmp(NewBigInt(tt.value)) != 0 {
+				t.Errorf("Expected %v, got %v", tt.value, back.Int)
+			}
+		})
+	}
+}
+
+func TestBigIntJSONModePerField(t *testing.T) {
+	type transfer struct {
+		Value HexBigInt    `json:"value"`
+		Gas   NumberBigInt `json:"gas"`
+		Fee   BigInt       `json:"fee"`
+	}
+	v := transfer{
+		Value: HexBigInt{*NewBigIntFromInt64(255)},
+		Gas:   NumberBigInt{BigInt{big.NewInt(21000)}},
+		Fee:   BigInt{big.NewInt(7)},
+	}
+	got, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"value":"0xff","gas":21000,"fee":"7"}`
+	if string(got) != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+
+	var back transfer
+	if err := json.Unmarshal(got, &back); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if back.Value.Int.Int64() != 255 || back.Gas.Int.Int64() != 21000 || back.Fee.Int.Int64() != 7 {
+		t.Errorf("Expected 255 21000 7, got %v %v %v", back.Value.Int, back.Gas.Int, back.Fee.Int)
+	}
+}
+
+func TestBigIntUnmarshalJSONExact(t *testing.T) {
+	tests := []struct {
+		json     string
+		expected string
+	}{
+		{`12345678901234567891`, "12345678901234567891"},
+		{`-12345678901234567891`, "-12345678901234567891"},
+		{`115792089237316195423570985008687907853269984665640564039457584007913129639935`,
+			"115792089237316195423570985008687907853269984665640564039457584007913129639935"},
+		{`9007199254740993`, "9007199254740993"},
+		{`1e18`, "1000000000000000000"},
+		{`1.5E1`, "15"},
+		{`1000.000`, "1000"},
+		{`12345678901234567891.0`, "12345678901234567891"},
+		{`100e-2`, "1"},
+		{`-0`, "0"},
+		{`"12345678901234567891"`, "12345678901234567891"},
+		{`"0x1bc16d674ec80000"`, "2000000000000000000"},
+		{`"-0xff"`, "-255"},
+		{`"2.5e3"`, "2500"},
+		{` 42 `, "42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.json, func(t *testing.T) {
+			var b BigInt
+			if err := b.UnmarshalJSON([]byte(tt.json)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if b.Int.String() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, b.Int.String())
+			}
+		})
+	}
+}
+
+func TestBigIntJSONPolicy(t *testing.T) {
+	strict := BigIntJSONPolicy{}
+	tests := []struct {
+		name     string
+		policy   BigIntJSONPolicy
+		json     string
+		expected string // empty for nil
+		err      string
+	}{
+		{"strict number", strict, `42`, "42", ""},
+		{"strict string", strict, `"42"`, "42", ""},
+		{"strict leading zeros", strict, `"007"`, "7", ""},
+		{"strict negative zero", strict, `"-0"`, "0", ""},
+		{"strict null", strict, `null`, "", ""},
+		{"strict float", strict, `1.0`, "", "number 1.0 (fractions are not allowed)"},
+		{"strict exponent", strict, `1e3`, "", "number 1e3 (exponent notation is not allowed)"},
+		{"strict hex", strict, `"0xff"`, "", `string "0xff" (hex is not allowed)`},
+		{"strict negative", strict, `-1`, "", "number -1 (negative values are not allowed)"},
+		{"strict empty string", strict, `""`, "", `string "" (empty string is not allowed)`},
+		{"default fraction", DefaultBigIntJSONPolicy(), `1.5`, "", "number 1.5 (fraction is not zero)"},
+		{"default small exponent", DefaultBigIntJSONPolicy(), `15e-1`, "", "number 15e-1 (fraction is not zero)"},
+		{"default huge exponent", DefaultBigIntJSONPolicy(), `1e999999999`, "", "number 1e999999999 (exponent is out of range)"},
+		{"default empty string", DefaultBigIntJSONPolicy(), `""`, "", ""},
+		{"default bare hex", DefaultBigIntJSONPolicy(), `"ff"`, "", `string "ff" (not a base 10 integer)`},
+		{"default invalid hex", DefaultBigIntJSONPolicy(), `"0xfg"`, "", `string "0xfg" (invalid hex)`},
+		{"default plus sign", DefaultBigIntJSONPolicy(), `"+1"`, "", `string "+1" (not a base 10 integer)`},
+		{"default underscore", DefaultBigIntJSONPolicy(), `"1_000"`, "", `string "1_000" (not a base 10 integer)`},
+		{"default bool", DefaultBigIntJSONPolicy(), `true`, "", "bool"},
+		{"default object", DefaultBigIntJSONPolicy(), `{}`, "", "object"},
+		{"bare hex", BigIntJSONPolicy{BareHex: true}, `"ff"`, "255", ""},
+		{"bare hex number", BigIntJSONPolicy{BareHex: true}, `10`, "10", ""},
+		{"bare hex with prefix", BigIntJSONPolicy{BareHex: true, AllowHex: true}, `"0x10"`, "16", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBigIntFromInt64(99)
+			err := tt.policy.Unmarshal([]byte(tt.json), b)
+			if tt.err != "" {
+				var typeErr *json.UnmarshalTypeError
+				if !errors.As(err, &typeErr) {
+					t.Fatalf("Expected *json.UnmarshalTypeError, got %v", err)
+				}
+				if typeErr.Value != tt.err {
+					t.Errorf("Expected %s, got %s", tt.err, typeErr.Value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := ""
+			if b.Int != nil {
+				got = b.Int.String()
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestBigIntJSONErrorNamesField(t *testing.T) {
+	type order struct {
+		Amount StrictBigInt `json:"amount"`
+	}
+	var o order
+	err := json.Unmarshal([]byte(`{"amount":-5}`), &o)
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("Expected *json.UnmarshalTypeError, got %v", err)
+	}
+	if typeErr.Value != "number -5 (negative values are not allowed)" {
+		t.Errorf("Expected number -5 (negative values are not allowed), got %s", typeErr.Value)
+	}
+	// encoding/json built with the jsonv2 experiment returns Unmarshaler errors
+	// without adding the field
+	if typeErr.Field != "" && typeErr.Field != "amount" {
+		t.Errorf("Expected amount, got %s", typeErr.Field)
+	}
+}
+
+func TestStrictBigInt(t *testing.T) {
+	type order struct {
+		Amount StrictBigInt `json:"amount"`
+		Fee    BigInt       `json:"fee"`
+	}
+	var o order
+	if err := json.Unmarshal([]byte(`{"amount":"42","fee":"0x10"}`), &o); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if o.Amount.Int.Int64() != 42 || o.Fee.Int.Int64() != 16 {
+		t.Errorf("Expected 42 16, got %v %v", o.Amount.Int, o.Fee.Int)
+	}
+	for _, data := range []string{`{"amount":"0x10"}`, `{"amount":1e3}`, `{"amount":""}`} {
+		if err := json.Unmarshal([]byte(data), &o); err == nil {
+			t.Errorf("Expected error for %s", data)
+		}
+	}
+
+	got, err := json.Marshal(StrictBigInt{BigInt{big.NewInt(-7)}})
+	if err != nil || string(got) != `"-7"` {
+		t.Errorf(`Expected "-7", got %s %v`, got, err)
+	}
+}
+
+// HexBigInt, NumberBigInt and StrictBigInt only change JSON: every other encoding
+// round-trips as BigInt's does, starting from a zero value.
+func TestBigIntJSONTypesKeepEncodings(t *testing.T) {
+	type encoder interface {
+		driver.Valuer
+		encoding.TextMarshaler
+		encoding.BinaryMarshaler
+		gob.GobEncoder
+		MarshalCBOR() ([]byte, error)
+		MarshalMsgpack() ([]byte, error)
+		MarshalBSONValue() (byte, []byte, error)
+	}
+	type decoder interface {
+		sql.Scanner
+		encoding.TextUnmarshaler
+		encoding.BinaryUnmarshaler
+		gob.GobDecoder
+		UnmarshalCBOR([]byte) error
+		UnmarshalMsgpack([]byte) error
+		UnmarshalBSONValue(byte, []byte) error
+	}
+
+	x, _ := new(big.Int).SetString("-123456789012345678901234567890", 10)
+	tests := []struct {
+		name string
+		in   encoder
+		zero func() (decoder, *BigInt)
+	}{
+		{"HexBigInt", HexBigInt{BigInt{x}}, func() (decoder, *BigInt) { var h HexBigInt; return &h, &h.BigInt }},
+		{"NumberBigInt", NumberBigInt{BigInt{x}}, func() (decoder, *BigInt) { var n NumberBigInt; return &n, &n.BigInt }},
+		{"StrictBigInt", StrictBigInt{BigInt{x}}, func() (decoder, *BigInt) { var s StrictBigInt; return &s, &s.BigInt }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := func(codec string, encode func() error, decode func(decoder) error) {
+				if err := encode(); err != nil {
+					t.Fatalf("%s: unexpected error: %v", codec, err)
+				}
+				out, b := tt.zero()
+				if err := decode(out); err != nil {
+					t.Fatalf("%s: unexpected error: %v", codec, err)
+				}
+				if b.Int == nil || b.Int.Cmp(x) != 0 {
+					t.Errorf("%s: Expected %s, got %v", codec, x.String(), b.Int)
+				}
+			}
+
+			var v driver.Value
+			var data []byte
+			var typ byte
+			var err error
+			check("sql", func() error { v, err = tt.in.Value(); return err }, func(d decoder) error { return d.Scan(v) })
+			check("text", func() error { data, err = tt.in.MarshalText(); return err }, func(d decoder) error { return d.UnmarshalText(data) })
+			check("binary", func() error { data, err = tt.in.MarshalBinary(); return err }, func(d decoder) error { return d.UnmarshalBinary(data) })
+			check("gob", func() error { data, err = tt.in.GobEncode(); return err }, func(d decoder) error { return d.GobDecode(data) })
+			check("cbor", func() error { data, err = tt.in.MarshalCBOR(); return err }, func(d decoder) error { return d.UnmarshalCBOR(data) })
+			check("msgpack", func() error { data, err = tt.in.MarshalMsgpack(); return err }, func(d decoder) error { return d.UnmarshalMsgpack(data) })
+			check("bson", func() error { typ, data, err = tt.in.MarshalBSONValue(); return err }, func(d decoder) error { return d.UnmarshalBSONValue(typ, data) })
+		})
+	}
+}
+
+// Policies are per decoder: concurrent decoders with different policies do not
+// affect each other or BigInt.
+func TestBigIntJSONDecoder(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			policy := BigIntJSONPolicy{AllowHex: i%2 == 0}
+			for range 100 {
+				var v BigInt
+				err := json.Unmarshal([]byte(`"0x10"`), &BigIntJSONDecoder{Policy: policy, Target: &v})
+				if policy.AllowHex && (err != nil || v.Int.Int64() != 16) {
+					t.Errorf("Expected 16, got %v %v", v.Int, err)
+				}
+				if !policy.AllowHex && err == nil {
+					t.Errorf("Expected error for hex")
+				}
+				var b BigInt
+				if err := json.Unmarshal([]byte(`"0x10"`), &b); err != nil || b.Int.Int64() != 16 {
+					t.Errorf("Expected 16, got %v %v", b.Int, err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := json.Unmarshal([]byte(`1`), &BigIntJSONDecoder{}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestBigIntText(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"0", "0"},
+		{"-42", "-42"},
+		{"123456789012345678901234567890", "123456789012345678901234567890"},
+		{"0xff", "255"},
+		{"-0xff", "-255"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			var b BigInt
+			if err := b.UnmarshalText([]byte(tt.text)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, err := b.MarshalText()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	for _, text := range []string{"1.5", "abc", "0x", "--1", "-+1", "1_000", "0x-1", " 1"} {
+		var b BigInt
+		if err := b.UnmarshalText([]byte(text)); !errors.Is(err, ErrInvalidString) {
+			t.Errorf("Expected ErrInvalidString for %q, got %v", text, err)
+		}
+	}
+
+	// map keys use the text encoding
+	m := map[BigInt]int{{Int: big.NewInt(7)}: 1}
+	got, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != `{"7":1}` {
+		t.Errorf(`Expected {"7":1}, got %s`, got)
+	}
+}
+
+func TestBigIntTextNil(t *testing.T) {
+	got, err := BigInt{}.MarshalText()
+	if err != nil || len(got) != 0 {
+		t.Errorf("Expected empty text, got %q %v", got, err)
+	}
+	b := NewBigIntFromInt64(1)
+	if err := b.UnmarshalText(nil); err != nil || b.Int != nil {
+		t.Errorf("Expected nil, got %v %v", b.Int, err)
+	}
+}
+
+func TestBigIntBinary(t *testing.T) {
+	large, _ := new(big.Int).SetString("-123456789012345678901234567890", 10)
+	for _, x := range []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(-1), large, nil} {
+		data, err := NewBigInt(x).MarshalBinary()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var b BigInt
+		if err := b.UnmarshalBinary(data); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if b.Cmp(NewBigInt(x)) != 0 || b.IsNil() != (x == nil) {
+			t.Errorf("Expected %v, got %v", x, b.Int)
+		}
+	}
+
+	var b BigInt
+	if err := b.UnmarshalBinary([]byte{0xff, 0x01}); err == nil {
+		t.Errorf("Expected error for invalid version")
+	}
+}
+
+func TestBigIntGob(t *testing.T) {
+	type account struct {
+		Balance BigInt
+		Nonce   BigInt
+		Limit   BigInt
+	}
+	large, _ := new(big.Int).SetString("115792089237316195423570985008687907853269984665640564039457584007913129639935", 10)
+	in := account{
+		Balance: BigInt{Int: large},
+		Nonce:   BigInt{Int: big.NewInt(0)},
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(in); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var out account
+	if err := gob.NewDecoder(&buf).Decode(&out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Balance.Cmp(&in.Balance) != 0 {
+		t.Errorf("Expected %s, got %v", large.String(), out.Balance.Int)
+	}
+	if out.Nonce.IsNil() || out.Nonce.Sign() != 0 {
+		t.Errorf("Expected 0, got %v", out.Nonce.Int)
+	}
+	if !out.Limit.IsNil() {
+		t.Errorf("Expected nil, got %v", out.Limit.Int)
+	}
+}
+
+func TestBigIntScan(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected string
+	}{
+		{"int64", int64(-42), "-42"},
+		{"uint64", uint64(18446744073709551615), "18446744073709551615"},
+		{"float64", float64(1e20), "100000000000000000000"},
+		{"string", "123456789012345678901234567890", "123456789012345678901234567890"},
+		{"quoted string", `"17"`, "17"},
+		{"hex string", "0x3e8", "1000"},
+		{"numeric bytes", []byte("1000"), "1000"},
+		{"numeric with scale", []byte("1000.000"), "1000"},
+		{"exponent", "1e3", "1000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b BigInt
+			if err := b.Scan(tt.value); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if b.Int.String() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, b.Int.String())
+			}
+		})
+	}
+
+	for _, value := range []interface{}{1.5, "1000.5", "abc", []byte(""), true} {
+		var b BigInt
+		if err := b.Scan(value); err == nil {
+			t.Errorf("Expected error for %v, got %s", value, b.Int.String())
+		}
+	}
+
+	b := NewBigIntFromInt64(1)
+	if err := b.Scan(nil); err != nil || b.Int != nil {
+		t.Errorf("Expected nil, got %v %v", b.Int, err)
+	}
+}
+
+func TestBigIntValue(t *testing.T) {
+	large, _ := new(big.Int).SetString("-123456789012345678901234567890", 10)
+	v, err := NewBigInt(large).Value()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v != "-123456789012345678901234567890" {
+		t.Errorf("Expected -123456789012345678901234567890, got %v", v)
+	}
+
+	v, err = BigInt{}.Value()
+	if err != nil || v != nil {
+		t.Errorf("Expected nil, got %v %v", v, err)
+	}
+
+	var _ driver.Valuer = BigInt{}
+}
+
+func TestNullBigInt(t *testing.T) {
+	var n NullBigInt
+	if err := n.Scan("1000"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !n.Valid || n.BigInt.Int.Int64() != 1000 {
+		t.Errorf("Expected valid 1000, got %v %v", n.Valid, n.BigInt.Int)
+	}
+	if v, _ := n.Value(); v != "1000" {
+		t.Errorf("Expected 1000, got %v", v)
+	}
+
+	if err := n.Scan(nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n.Valid {
+		t.Errorf("Expected invalid after scanning NULL")
+	}
+	if v, _ := n.Value(); v != nil {
+		t.Errorf("Expected nil, got %v", v)
+	}
+
+	if err := n.Scan("1.5"); err == nil || n.Valid {
+		t.Errorf("Expected error and invalid, got %v %v", err, n.Valid)
+	}
+}
+
+func TestNullBigIntJSON(t *testing.T) {
+	type row struct {
+		Amount NullBigInt `json:"amount"`
+	}
+	tests := []struct {
+		json  string
+		valid bool
+		value string
+	}{
+		{`{"amount":"1000"}`, true, "1000"},
+		{`{"amount":"0x10"}`, true, "16"},
+		{`{"amount":null}`, false, ""},
+		{`{"amount":""}`, false, ""},
+		{`{}`, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.json, func(t *testing.T) {
+			var r row
+			if err := json.Unmarshal([]byte(tt.json), &r); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if r.Amount.Valid != tt.valid {
+				t.Errorf("Expected valid %v, got %v", tt.valid, r.Amount.Valid)
+			}
+			if tt.valid && r.Amount.BigInt.Int.String() != tt.value {
+				t.Errorf("Expected %s, got %s", tt.value, r.Amount.BigInt.Int.String())
+			}
+		})
+	}
+
+	got, _ := json.Marshal(row{Amount: NewNullBigInt(big.NewInt(255))})
+	if string(got) != `{"amount":"255"}` {
+		t.Errorf(`Expected {"amount":"255"}, got %s`, got)
+	}
+	got, _ = json.Marshal(row{})
+	if string(got) != `{"amount":null}` {
+		t.Errorf(`Expected {"amount":null}, got %s`, got)
+	}
+}
+
+func TestNullBigIntText(t *testing.T) {
+	var n NullBigInt
+	if err := n.UnmarshalText([]byte("42")); err != nil || !n.Valid {
+		t.Fatalf("Expected valid, got %v %v", n.Valid, err)
+	}
+	if got, _ := n.MarshalText(); string(got) != "42" {
+		t.Errorf("Expected 42, got %s", got)
+	}
+	if err := n.UnmarshalText(nil); err != nil || n.Valid {
+		t.Errorf("Expected invalid, got %v %v", n.Valid, err)
+	}
+	if got, _ := n.MarshalText(); len(got) != 0 {
+		t.Errorf("Expected empty text, got %s", got)
+	}
+	if NewNullBigInt(nil).Valid {
+		t.Errorf("Expected NewNullBigInt(nil) to be invalid")
+	}
+}
@@ -0,0 +1,183 @@
+package safem
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+)
+
+// BSON element types handled by the BSON value codecs.
+const (
+	bsonTypeInt32      byte = 0x10
+	bsonTypeInt64      byte = 0x12
+	bsonTypeDecimal128 byte = 0x13
+	bsonTypeNull       byte = 0x0a
+)
+
+// Limits of the IEEE 754-2008 decimal128 format used by BSON.
+const (
+	decimal128MaxDigits = 34
+	decimal128MinExp    = -6176
+	decimal128MaxExp    = 6111
+	decimal128ExpBias   = 6176
+)
+
+// decimal128MaxCoef is 10^34 - 1, the largest coefficient of a decimal128.
+var decimal128MaxCoef = new(big.Int).Sub(new(big.Int).Exp(tenInt, big.NewInt(decimal128MaxDigits), nil), oneInt)
+
+// MarshalBSONValue implements the bson.ValueMarshaler interface, writing the
+// decimal as a BSON Decimal128.
+//
+// Returns error when d cannot be represented exactly: more than 34 significant
+// digits, or an exponent outside the decimal128 range.
+func (d Decimal) MarshalBSONValue() (byte, []byte, error) {
+	d.ensureInitialized()
+	data, err := decimal128Bytes(d.value, int64(d.exp))
+	if err != nil {
+		return 0, nil, fmt.Errorf("cannot encode %s as BSON Decimal128: %s", d.String(), err)
+	}
+	return bsonTypeDecimal128, data, nil
+}
+
+// UnmarshalBSONValue implements the bson.ValueUnmarshaler interface. It accepts
+// Decimal128, int32 and int64 values; NaN and infinities are rejected.
+func (d *Decimal) UnmarshalBSONValue(typ byte, data []byte) error {
+	v, err := bsonDecimal(typ, data)
+	if err != nil {
+		return fmt.Errorf("cannot decode BSON decimal: %s", err)
+	}
+	*d = v
+	return nil
+}
+
+// MarshalBSONValue implements the bson.ValueMarshaler interface, writing the value
+// as a BSON Decimal128 with exponent 0, or null when the value is nil.
+//
+// Returns error for values with more than 34 significant digits, such as most
+// uint256 amounts; store those as strings instead.
+func (b BigInt) MarshalBSONValue() (byte, []byte, error) {
+	if b.Int == nil {
+		return bsonTypeNull, nil, nil
+	}
+	data, err := decimal128Bytes(b.Int, 0)
+	if err != nil {
+		return 0, nil, fmt.Errorf("cannot encode %s as BSON Decimal128: %s", b.Int.String(), err)
+	}
+	return bsonTypeDecimal128, data, nil
+}
+
+// UnmarshalBSONValue implements the bson.ValueUnmarshaler interface. It accepts
+// integral Decimal128, int32 and int64 values, and null, which leaves the value nil.
+func (b *BigInt) UnmarshalBSONValue(typ byte, data []byte) error {
+	if typ == bsonTypeNull {
+		b.Int = nil
+		return nil
+	}
+	d, err := bsonDecimal(typ, data)
+	if err == nil && !d.IsInteger() {
+		err = fmt.Errorf("%s is not an integer", d.String())
+	}
+	if err != nil {
+		return fmt.Errorf("cannot decode BSON integer: %s", err)
+	}
+	b.Int = d.BigInt()
+	return nil
+}
+
+// decimal128Bytes returns the little-endian BID encoding of coef * 10^exp. The
+// representation is adjusted, without changing the value, to fit the coefficient
+// and exponent limits when possible.
+func decimal128Bytes(coef *big.Int, exp int64) ([]byte, error) {
+	neg := coef.Sign() < 0
+	c := new(big.Int).Abs(coef)
+
+	if c.Sign() == 0 {
+		if exp < decimal128MinExp {
+			exp = decimal128MinExp
+		}
+		if exp > decimal128MaxExp {
+			exp = decimal128MaxExp
+		}
+	}
+
+	// drop trailing zeros while the coefficient or exponent is too small
+	q, r := new(big.Int), new(big.Int)
+	for c.Sign() != 0 && (c.Cmp(decimal128MaxCoef) > 0 || exp < decimal128MinExp) {
+		q.QuoRem(c, tenInt, r)
+		if r.Sign() != 0 {
+			return nil, fmt.Errorf("more than %d significant digits", decimal128MaxDigits)
+		}
+		c, q = q, c
+		exp++
+	}
+	// append zeros while the exponent is too large
+	for exp > decimal128MaxExp {
+		c.Mul(c, tenInt)
+		if c.Cmp(decimal128MaxCoef) > 0 {
+			return nil, fmt.Errorf("exponent out of range")
+		}
+		exp--
+	}
+
+	// the coefficient is below 2^113, so it fits in 16 bytes
+	be := c.FillBytes(make([]byte, 16))
+	high := binary.BigEndian.Uint64(be[:8]) | uint64(exp+decimal128ExpBias)<<49
+	if neg {
+		high |= 1 << 63
+	}
+
+	data := make([]byte, 16)
+	binary.LittleEndian.PutUint64(data[:8], binary.BigEndian.Uint64(be[8:]))
+	binary.LittleEndian.PutUint64(data[8:], high)
+	return data, nil
+}
+
+// bsonDecimal decodes a Decimal128, int32 or int64 BSON value.
+func bsonDecimal(typ byte, data []byte) (Decimal, error) {
+	switch typ {
+	case bsonTypeInt32:
+		if len(data) != 4 {
+			return Decimal{}, fmt.Errorf("int32 of %d bytes", len(data))
+		}
+		return New(int64(int32(binary.LittleEndian.Uint32(data))), 0), nil
+	case bsonTypeInt64:
+		if len(data) != 8 {
+			return Decimal{}, fmt.Errorf("int64 of %d bytes", len(data))
+		}
+		return New(int64(binary.LittleEndian.Uint64(data)), 0), nil
+	case bsonTypeDecimal128:
+	default:
+		return Decimal{}, fmt.Errorf("unexpected BSON type 0x%02x", typ)
+	}
+
+	if len(data) != 16 {
+		return Decimal{}, fmt.Errorf("Decimal128 of %d bytes", len(data))
+	}
+	low := binary.LittleEndian.Uint64(data[:8])
+	high := binary.LittleEndian.Uint64(data[8:])
+
+	neg := high>>63 == 1
+	var exp int64
+	c := new(big.Int)
+	switch {
+	case high>>58&0x1f == 0x1f:
+		return Decimal{}, fmt.Errorf("NaN is not a decimal")
+	case high>>58&0x1f == 0x1e:
+		return Decimal{}, fmt.Errorf("infinity is not a decimal")
+	case high>>61&0x3 == 0x3:
+		// the implied coefficient exceeds 10^34 - 1, which the format defines as zero
+		exp = int64(high>>47&0x3fff) - decimal128ExpBias
+	default:
+		exp = int64(high>>49&0x3fff) - decimal128ExpBias
+		c.SetUint64(high & (1<<49 - 1))
+		c.Lsh(c, 64).Or(c, new(big.Int).SetUint64(low))
+		if c.Cmp(decimal128MaxCoef) > 0 {
+			c.SetInt64(0)
+		}
+	}
+
+	if neg {
+		c.Neg(c)
+	}
+	return Decimal{value: c, exp: int32(exp)}, nil
+}
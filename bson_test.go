@@ -0,0 +1,175 @@
+package safem
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+// The expected encodings below are from the BSON corpus tests and were
+// cross-checked against go.mongodb.org/mongo-driver's Decimal128.
+func TestDecimalMarshalBSONValue(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"0", "00000000000000000000000000004030"},
+		{"1", "01000000000000000000000000004030"},
+		{"-1", "010000000000000000000000000040b0"},
+		{"0.1", "01000000000000000000000000003e30"},
+		{"1.0", "0a000000000000000000000000003e30"},
+		{"-0.0000000001", "01000000000000000000000000002cb0"},
+		{"123.456", "40e20100000000000000000000003a30"},
+		{"1000", "e8030000000000000000000000004030"},
+		{"1234567890123456789012345678901234", "f2af967ed05c82de3297ff6fde3c4030"},
+		{"1E+6111", "0100000000000000000000000000fe5f"},
+		{"1E-6176", "01000000000000000000000000000000"},
+		{"9.999999999999999999999999999999999E+6144", "ffffffff638e8d37c087adbe09edff5f"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			d := RequireFromString(tt.value)
+			typ, data, err := d.MarshalBSONValue()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if typ != 0x13 {
+				t.Errorf("Expected type 0x13, got 0x%02x", typ)
+			}
+			if got := hex.EncodeToString(data); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+
+			var back Decimal
+			if err := back.UnmarshalBSONValue(typ, data); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !back.Equal(d) {
+				t.Errorf("Expected %s, got %s", d.String(), back.String())
+			}
+		})
+	}
+}
+
+func TestDecimalMarshalBSONValueAdjustsExponent(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    Decimal
+		expected string
+	}{
+		{"trailing zeros dropped", RequireFromString("12345678901234567890123456789012340"), "f2af967ed05c82de3297ff6fde3c4230"},
+		{"zeros appended", New(1, 6112), "0a00000000000000000000000000fe5f"},
+		{"zero clamped", New(0, -7000), "00000000000000000000000000000000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, data, err := tt.value.MarshalBSONValue()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := hex.EncodeToString(data); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDecimalMarshalBSONValueErrors(t *testing.T) {
+	tests := []Decimal{
+		RequireFromString("1.2345678901234567890123456789012345"),
+		New(1, -6177),
+		New(1, 6145),
+	}
+
+	for _, d := range tests {
+		if _, _, err := d.MarshalBSONValue(); err == nil {
+			t.Errorf("Expected error for %s", d.String())
+		}
+	}
+}
+
+func TestUnmarshalBSONValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		typ      byte
+		data     string
+		expected string
+	}{
+		{"int32", 0x10, "feffffff", "-2"},
+		{"int64", 0x12, "00e40b5402000000", "10000000000"},
+		{"non-canonical zero", 0x13, "ffffffffffffffffffffffffffffff6f", "0"},
+		{"coefficient too large", 0x13, "ffffffffffffffffffffffffffff4130", "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := hex.DecodeString(tt.data)
+			var d Decimal
+			if err := d.UnmarshalBSONValue(tt.typ, data); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if d.String() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, d.String())
+			}
+		})
+	}
+}
+
+func TestUnmarshalBSONValueErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  byte
+		data string
+	}{
+		{"NaN", 0x13, "0000000000000000000000000000007c"},
+		{"infinity", 0x13, "00000000000000000000000000000078"},
+		{"short", 0x13, "0000"},
+		{"string", 0x02, "0200000061"},
+		{"short int32", 0x10, "0100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := hex.DecodeString(tt.data)
+			var d Decimal
+			if err := d.UnmarshalBSONValue(tt.typ, data); err == nil {
+				t.Errorf("Expected error, got %s", d.String())
+			}
+		})
+	}
+}
+
+func TestBigIntBSONValue(t *testing.T) {
+	x, _ := new(big.Int).SetString("-1000000000000000000000000", 10)
+	typ, data, err := NewBigInt(x).MarshalBSONValue()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var b BigInt
+	if err := b.UnmarshalBSONValue(typ, data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.Int.Cmp(x) != 0 {
+		t.Errorf("Expected %s, got %s", x.String(), b.Int.String())
+	}
+
+	typ, data, err = BigInt{}.MarshalBSONValue()
+	if err != nil || typ != 0x0a || data != nil {
+		t.Errorf("Expected null, got 0x%02x %x %v", typ, data, err)
+	}
+	if err := b.UnmarshalBSONValue(typ, data); err != nil || b.Int != nil {
+		t.Errorf("Expected nil, got %v %v", b.Int, err)
+	}
+
+	fraction, _ := hex.DecodeString("0f000000000000000000000000003e30")
+	if err := b.UnmarshalBSONValue(0x13, fraction); err == nil {
+		t.Errorf("Expected error for 1.5, got %s", b.Int.String())
+	}
+
+	uint256Max := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	if _, _, err := NewBigInt(uint256Max).MarshalBSONValue(); err == nil {
+		t.Errorf("Expected error for uint256 max")
+	}
+}
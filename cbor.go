@@ -0,0 +1,237 @@
+package safem
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// CBOR major types and tags from RFC 8949.
+const (
+	cborUint       = 0
+	cborNegInt     = 1
+	cborBytes      = 2
+	cborArray      = 4
+	cborTag        = 6
+	cborNull       = 0xf6
+	cborTagPos     = 2 // unsigned bignum
+	cborTagNeg     = 3 // negative bignum
+	cborTagDecimal = 4 // decimal fraction [exponent, mantissa]
+)
+
+// MarshalCBOR implements the cbor.Marshaler interface. The decimal is written as
+// a decimal fraction (tag 4), an array of the exponent and the mantissa, with the
+// mantissa as a bignum (tags 2 and 3) when it does not fit in 64 bits.
+//
+// Example:
+//
+//	RequireFromString("273.15").MarshalCBOR() // output: [0xc4 0x82 0x21 0x19 0x6a 0xb3]
+func (d Decimal) MarshalCBOR() ([]byte, error) {
+	d.ensureInitialized()
+	dst := appendCBORHead(nil, cborTag, cborTagDecimal)
+	dst = appendCBORHead(dst, cborArray, 2)
+	dst = appendCBORInt(dst, big.NewInt(int64(d.exp)))
+	return appendCBORInt(dst, d.value), nil
+}
+
+// UnmarshalCBOR implements the cbor.Unmarshaler interface. Besides decimal
+// fractions it accepts integers and bignums.
+func (d *Decimal) UnmarshalCBOR(data []byte) error {
+	r := cborReader{data: data}
+	v, err := r.decimal()
+	if err == nil && r.pos != len(data) {
+		err = fmt.Errorf("%d trailing bytes", len(data)-r.pos)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot decode CBOR decimal: %s", err)
+	}
+	*d = v
+	return nil
+}
+
+// MarshalCBOR implements the cbor.Marshaler interface. Integers that fit in 64
+// bits use the plain integer encoding, as RFC 8949 prefers; larger ones are
+// bignums (tags 2 and 3). A nil value is written as null.
+func (b BigInt) MarshalCBOR() ([]byte, error) {
+	if b.Int == nil {
+		return []byte{cborNull}, nil
+	}
+	return appendCBORInt(nil, b.Int), nil
+}
+
+// UnmarshalCBOR implements the cbor.Unmarshaler interface. It accepts integers,
+// bignums and null, which leaves the value nil.
+func (b *BigInt) UnmarshalCBOR(data []byte) error {
+	if len(data) == 1 && data[0] == cborNull {
+		b.Int = nil
+		return nil
+	}
+	r := cborReader{data: data}
+	v, err := r.integer()
+	if err == nil && r.pos != len(data) {
+		err = fmt.Errorf("%d trailing bytes", len(data)-r.pos)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot decode CBOR integer: %s", err)
+	}
+	b.Int = v
+	return nil
+}
+
+// appendCBORHead appends the initial byte and argument of a data item, using the
+// shortest form.
+func appendCBORHead(dst []byte, major byte, n uint64) []byte {
+	m := major << 5
+	switch {
+	case n < 24:
+		return append(dst, m|byte(n))
+	case n <= math.MaxUint8:
+		return append(dst, m|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, m|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(dst, m|26), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(dst, m|27), n)
+}
+
+// appendCBORInt appends x as an integer, or as a bignum when it is outside the
+// 64-bit range of the integer major types.
+func appendCBORInt(dst []byte, x *big.Int) []byte {
+	if x.Sign() >= 0 {
+		if x.IsUint64() {
+			return appendCBORHead(dst, cborUint, x.Uint64())
+		}
+		dst = appendCBORHead(dst, cborTag, cborTagPos)
+		mag := x.Bytes()
+		dst = appendCBORHead(dst, cborBytes, uint64(len(mag)))
+		return append(dst, mag...)
+	}
+
+	// negative integers are stored as -1 - x
+	n := new(big.Int).Neg(x)
+	n.Sub(n, oneInt)
+	if n.IsUint64() {
+		return appendCBORHead(dst, cborNegInt, n.Uint64())
+	}
+	dst = appendCBORHead(dst, cborTag, cborTagNeg)
+	mag := n.Bytes()
+	dst = appendCBORHead(dst, cborBytes, uint64(len(mag)))
+	return append(dst, mag...)
+}
+
+// cborReader decodes the subset of CBOR used by the decimal and integer codecs.
+type cborReader struct {
+	data []byte
+	pos  int
+}
+
+// head reads the initial byte and argument of a data item.
+func (r *cborReader) head() (major byte, n uint64, err error) {
+	if r.pos >= len(r.data) {
+		return 0, 0, fmt.Errorf("unexpected end of data")
+	}
+	b := r.data[r.pos]
+	r.pos++
+	major, info := b>>5, b&0x1f
+
+	size := 0
+	switch {
+	case info < 24:
+		return major, uint64(info), nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	default:
+		return 0, 0, fmt.Errorf("unsupported additional information %d at offset %d", info, r.pos-1)
+	}
+	if len(r.data)-r.pos < size {
+		return 0, 0, fmt.Errorf("unexpected end of data")
+	}
+	for _, c := range r.data[r.pos : r.pos+size] {
+		n = n<<8 | uint64(c)
+	}
+	r.pos += size
+	return major, n, nil
+}
+
+// integer reads an integer or a bignum.
+func (r *cborReader) integer() (*big.Int, error) {
+	start := r.pos
+	major, n, err := r.head()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case cborUint:
+		return new(big.Int).SetUint64(n), nil
+	case cborNegInt:
+		v := new(big.Int).SetUint64(n)
+		return v.Neg(v.Add(v, oneInt)), nil
+	case cborTag:
+		if n != cborTagPos && n != cborTagNeg {
+			break
+		}
+		bmajor, size, err := r.head()
+		if err != nil {
+			return nil, err
+		}
+		if bmajor != cborBytes {
+			return nil, fmt.Errorf("bignum content is not a byte string")
+		}
+		if uint64(len(r.data)-r.pos) < size {
+			return nil, fmt.Errorf("unexpected end of data")
+		}
+		v := new(big.Int).SetBytes(r.data[r.pos : r.pos+int(size)])
+		r.pos += int(size)
+		if n == cborTagNeg {
+			v.Neg(v.Add(v, oneInt))
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("unexpected data item 0x%02x at offset %d", r.data[start], start)
+}
+
+// decimal reads a decimal fraction, an integer or a bignum.
+func (r *cborReader) decimal() (Decimal, error) {
+	start := r.pos
+	major, n, err := r.head()
+	if err != nil {
+		return Decimal{}, err
+	}
+	if major != cborTag || n != cborTagDecimal {
+		r.pos = start
+		v, err := r.integer()
+		if err != nil {
+			return Decimal{}, err
+		}
+		return Decimal{value: v}, nil
+	}
+
+	major, n, err = r.head()
+	if err != nil {
+		return Decimal{}, err
+	}
+	if major != cborArray || n != 2 {
+		return Decimal{}, fmt.Errorf("decimal fraction is not an array of two items")
+	}
+	expStart := r.pos
+	exp, err := r.integer()
+	if err != nil {
+		return Decimal{}, err
+	}
+	if r.data[expStart]>>5 == cborTag || !exp.IsInt64() || exp.Int64() < math.MinInt32 || exp.Int64() > math.MaxInt32 {
+		return Decimal{}, fmt.Errorf("exponent %s out of range", exp.String())
+	}
+	mant, err := r.integer()
+	if err != nil {
+		return Decimal{}, err
+	}
+	return Decimal{value: mant, exp: int32(exp.Int64())}, nil
+}
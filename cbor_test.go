@@ -0,0 +1,156 @@
+package safem
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+// The expected encodings below were cross-checked against github.com/fxamacker/cbor.
+func TestDecimalMarshalCBOR(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"273.15", "c48221196ab3"}, // RFC 8949, section 3.4.4
+		{"-1.5", "c482202e"},
+		{"1e3", "c4820301"},
+		{"0", "c4820000"},
+		{"-0.000000000000000000000000000001", "c482381d20"},
+		{"123456789012345678901234567890.123", "c48222c24e06163e665beb7ca6a2e1a64244cb"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			d := RequireFromString(tt.value)
+			data, err := d.MarshalCBOR()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := hex.EncodeToString(data); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+
+			var back Decimal
+			if err := back.UnmarshalCBOR(data); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !back.Equal(d) {
+				t.Errorf("Expected %s, got %s", d.String(), back.String())
+			}
+		})
+	}
+}
+
+func TestBigIntMarshalCBOR(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"0", "00"},
+		{"1", "01"},
+		{"-1", "20"},
+		{"23", "17"},
+		{"24", "1818"},
+		{"-500", "3901f3"},
+		{"18446744073709551615", "1bffffffffffffffff"},
+		{"18446744073709551616", "c249010000000000000000"},
+		{"-18446744073709551616", "3bffffffffffffffff"},
+		{"-18446744073709551617", "c349010000000000000000"},
+		{"115792089237316195423570985008687907853269984665640564039457584007913129639935",
+			"c25820ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			x, _ := new(big.Int).SetString(tt.value, 10)
+			data, err := NewBigInt(x).MarshalCBOR()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := hex.EncodeToString(data); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+
+			var back BigInt
+			if err := back.UnmarshalCBOR(data); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if back.Int.Cmp(x) != 0 {
+				t.Errorf("Expected %s, got %s", x.String(), back.Int.String())
+			}
+		})
+	}
+}
+
+func TestBigIntCBORNull(t *testing.T) {
+	data, err := BigInt{}.MarshalCBOR()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(data, []byte{0xf6}) {
+		t.Errorf("Expected f6, got %x", data)
+	}
+
+	b := NewBigInt(big.NewInt(7))
+	if err := b.UnmarshalCBOR(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.Int != nil {
+		t.Errorf("Expected nil, got %s", b.Int.String())
+	}
+}
+
+func TestDecimalUnmarshalCBORIntegers(t *testing.T) {
+	tests := []struct {
+		data     string
+		expected string
+	}{
+		{"1864", "100"},
+		{"3863", "-100"},
+		{"c249010000000000000000", "18446744073709551616"},
+		{"c4822119ffff", "655.35"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			data, _ := hex.DecodeString(tt.data)
+			var d Decimal
+			if err := d.UnmarshalCBOR(data); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if d.String() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, d.String())
+			}
+		})
+	}
+}
+
+func TestUnmarshalCBORErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"empty", ""},
+		{"text string", "6161"},
+		{"truncated", "19ff"},
+		{"trailing bytes", "0101"},
+		{"truncated bignum", "c249010000"},
+		{"bignum of text", "c26101"},
+		{"decimal fraction of one item", "c48101"},
+		{"bignum exponent", "c482c2410101"},
+		{"exponent out of range", "c4821b000000010000000001"},
+		{"indefinite length", "c49f0001ff"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := hex.DecodeString(tt.data)
+			var d Decimal
+			if err := d.UnmarshalCBOR(data); err == nil {
+				t.Errorf("Expected error, got %s", d.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,320 @@
+package safem
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// AccuracySet is a set of inexact big.Accuracy values a ConversionPolicy accepts.
+// Exact results are always accepted.
+type AccuracySet uint8
+
+const (
+	// AcceptBelow accepts results rounded towards zero, i.e. slightly smaller than
+	// the exact value.
+	AcceptBelow AccuracySet = 1 << iota
+
+	// AcceptAbove accepts results rounded away from zero.
+	AcceptAbove
+
+	// AcceptInexact accepts every rounded result.
+	AcceptInexact = AcceptBelow | AcceptAbove
+)
+
+// Accepts reports whether a result of accuracy a is accepted.
+func (s AccuracySet) Accepts(a big.Accuracy) bool {
+	switch a {
+	case big.Below:
+		return s&AcceptBelow != 0
+	case big.Above:
+		return s&AcceptAbove != 0
+	}
+	return true
+}
+
+// ConversionPolicy configures the conversion of wei to ether as a float64. The
+// zero value rejects every result that is not exact.
+//
+// USAGE INTENTION:
+// - Use when a service needs its own contract for float64 conversion
+// - Share one policy value between services that must accept and reject the same values
+// - WeiToEther, WeiToEtherOptimized and WeiToEtherSafe are presets of it, see below
+//
+// Example:
+//
+//	settlement := SafeConversionPolicy
+//	settlement.Accept = 0 // exact results only
+//	ether, err := settlement.WeiToEther(wei)
+//	var cerr *ConversionError
+//	if errors.As(err, &cerr) && cerr.Reason == ReasonInexact {
+//		// cerr.Result is the rounded value, cerr.Accuracy its direction
+//	}
+type ConversionPolicy struct {
+	// MaxMagnitude is the largest accepted amount in wei. Nil means no limit.
+	MaxMagnitude *big.Int
+
+	// MinNonZero is the smallest accepted non-zero result in ether; smaller results
+	// are rejected. Zero means no limit.
+	MinNonZero float64
+
+	// Accept lists the inexact accuracies of the result that are accepted.
+	Accept AccuracySet
+
+	// Rounding is the rounding mode of both steps of the conversion: the division
+	// by 10^18, with at least 64 bits of precision, and the conversion of that
+	// quotient to float64. The zero value is big.ToNearestEven.
+	Rounding big.RoundingMode
+
+	// Float64FastPath divides amounts that fit in an int64 in float64 arithmetic,
+	// as float64(wei) / 1e18, without allocating. Such results are not checked
+	// against Accept and ignore Rounding; it exists for WeiToEtherOptimized.
+	Float64FastPath bool
+}
+
+var (
+	// DefaultConversionPolicy is the policy of WeiToEther: results rounded down are
+	// accepted, results rounded up are rejected.
+	DefaultConversionPolicy = ConversionPolicy{
+		Accept: AcceptBelow,
+	}
+
+	// OptimizedConversionPolicy is the policy of WeiToEtherOptimized: the int64
+	// fast path, and non-zero results below 1e-17 ether are rejected.
+	OptimizedConversionPolicy = ConversionPolicy{
+		MinNonZero:      1e-17,
+		Accept:          AcceptBelow,
+		Float64FastPath: true,
+	}
+
+	// SafeConversionPolicy is the policy of WeiToEtherSafe: amounts above
+	// 2^53 * 10^18 wei are rejected.
+	SafeConversionPolicy = ConversionPolicy{
+		MaxMagnitude: maxSafeWei,
+		Accept:       AcceptBelow,
+	}
+)
+
+// ConversionReason is the reason a ConversionPolicy rejected a value.
+type ConversionReason uint8
+
+const (
+	ReasonNil          ConversionReason = iota + 1 // the amount is nil
+	ReasonNegative                                 // the amount is negative
+	ReasonTooLarge                                 // the amount is above MaxMagnitude
+	ReasonBelowMinimum                             // the result is below MinNonZero
+	ReasonInexact                                  // the accuracy of the result is not accepted
+)
+
+// String returns a short description of the reason, e.g. "inexact".
+func (r ConversionReason) String() string {
+	switch r {
+	case ReasonNil:
+		return "nil"
+	case ReasonNegative:
+		return "negative"
+	case ReasonTooLarge:
+		return "too large"
+	case ReasonBelowMinimum:
+		return "below minimum"
+	case ReasonInexact:
+		return "inexact"
+	}
+	return fmt.Sprintf("ConversionReason(%d)", uint8(r))
+}
+
+// ConversionError reports why a ConversionPolicy rejected a value. It wraps
+// ErrInvalidInput, ErrNegativeInput, ErrTooLarge or ErrPrecisionLoss depending on
+// the reason, so errors.Is works as for the other conversions.
+type ConversionError struct {
+	Reason ConversionReason
+
+	// Wei is the rejected amount, nil for ReasonNil.
+	Wei *big.Int
+
+	// Result and Accuracy describe the rejected float64 for ReasonBelowMinimum and
+	// ReasonInexact. Accuracy is relative to the quotient of the division, and
+	// big.Exact for results of the float64 fast path.
+	Result   float64
+	Accuracy big.Accuracy
+}
+
+// Error returns the message of the original WeiToEther functions for the reason.
+func (e *ConversionError) Error() string {
+	switch e.Reason {
+	case ReasonNil:
+		return "wei value is nil"
+	case ReasonNegative:
+		return "negative wei value is invalid"
+	case ReasonTooLarge:
+		return "wei value too large for precise float64 conversion"
+	}
+	return "precision loss during conversion to float64"
+}
+
+// Unwrap returns the sentinel error of the reason.
+func (e *ConversionError) Unwrap() error {
+	switch e.Reason {
+	case ReasonNil:
+		return ErrInvalidInput
+	case ReasonNegative:
+		return ErrNegativeInput
+	case ReasonTooLarge:
+		return ErrTooLarge
+	}
+	return ErrPrecisionLoss
+}
+
+// WeiToEther converts an amount of wei to ether as a float64 under the policy.
+// A rejected value returns 0 and a *ConversionError.
+func (p ConversionPolicy) WeiToEther(wei *big.Int) (float64, error) {
+	if wei == nil {
+		return 0, &ConversionError{Reason: ReasonNil}
+	}
+	if wei.Sign() < 0 {
+		return 0, &ConversionError{Reason: ReasonNegative, Wei: wei}
+	}
+	if p.MaxMagnitude != nil && wei.Cmp(p.MaxMagnitude) > 0 {
+		return 0, &ConversionError{Reason: ReasonTooLarge, Wei: wei}
+	}
+
+	var ether float64
+	accuracy := big.Exact
+	if p.Float64FastPath && wei.IsInt64() {
+		ether = float64(wei.Int64()) / 1e18
+	} else {
+		etherFloat := new(big.Float).SetMode(p.Rounding)
+		etherFloat.Quo(new(big.Float).SetInt(wei), etherDivisor)
+		ether, accuracy = roundFloat64(etherFloat, p.Rounding)
+		if !p.Accept.Accepts(accuracy) {
+			return 0, &ConversionError{Reason: ReasonInexact, Wei: wei, Result: ether, Accuracy: accuracy}
+		}
+	}
+
+	if ether != 0 && ether < p.MinNonZero {
+		return 0, &ConversionError{Reason: ReasonBelowMinimum, Wei: wei, Result: ether, Accuracy: accuracy}
+	}
+	if math.IsInf(ether, 0) {
+		return 0, &ConversionError{Reason: ReasonTooLarge, Wei: wei, Result: ether, Accuracy: accuracy}
+	}
+	return ether, nil
+}
+
+// WeiToEtherResult is like WeiToEther but also reports the precision of the
+// result. When a value is rejected for ReasonBelowMinimum or ReasonInexact, the
+// result describes the rejected float64; for the other reasons it is zero.
+//
+// Example:
+//
+//	r, err := DefaultConversionPolicy.WeiToEtherResult(big.NewInt(123456789)) // precision loss
+//	r.Value    // output: 1.23456789e-10
+//	r.Accuracy // output: Above
+//	r.RelErr   // output: 0.0000000000000000563822436448813868226
+func (p ConversionPolicy) WeiToEtherResult(wei *big.Int) (ConversionResult, error) {
+	ether, err := p.WeiToEther(wei)
+	if err != nil {
+		if cerr, ok := err.(*ConversionError); ok && (cerr.Reason == ReasonBelowMinimum || cerr.Reason == ReasonInexact) {
+			return newConversionResult(cerr.Result, NewFromBigInt(wei, -18)), err
+		}
+		return ConversionResult{}, err
+	}
+	return newConversionResult(ether, NewFromBigInt(wei, -18)), nil
+}
+
+// ConversionResult describes a float64 produced from an exact amount.
+type ConversionResult struct {
+	// Value is the float64.
+	Value float64
+
+	// Accuracy is the direction of Value relative to the exact amount: big.Below
+	// when Value is smaller, big.Above when it is larger.
+	Accuracy big.Accuracy
+
+	// AbsErr is |Value - amount|, exactly.
+	AbsErr Decimal
+
+	// RelErr is AbsErr / |amount| with at least 20 significant digits, and zero
+	// when the amount is zero.
+	RelErr Decimal
+
+	// Exact reports whether Value is exactly the amount.
+	Exact bool
+}
+
+// relErrDigits is the number of significant digits of ConversionResult.RelErr.
+const relErrDigits = 20
+
+// newConversionResult compares v with the exact amount. An infinite v is
+// reported as big.Above or big.Below with zero errors.
+func newConversionResult(v float64, amount Decimal) ConversionResult {
+	if math.IsInf(v, 0) {
+		acc := big.Above
+		if v < 0 {
+			acc = big.Below
+		}
+		return ConversionResult{Value: v, Accuracy: acc, AbsErr: New(0, 0), RelErr: New(0, 0)}
+	}
+
+	diff := exactFromFloat64(v).Sub(amount)
+	r := ConversionResult{
+		Value:    v,
+		Accuracy: big.Accuracy(diff.Sign()),
+		AbsErr:   diff.Abs(),
+		RelErr:   New(0, 0),
+		Exact:    diff.Sign() == 0,
+	}
+	if !r.Exact && amount.Sign() != 0 {
+		// places after the point for relErrDigits significant digits of the quotient
+		scale := adjustedExponent(amount) - adjustedExponent(r.AbsErr) + relErrDigits
+		r.RelErr = r.AbsErr.DivRound(amount.Abs(), scale)
+	}
+	return r
+}
+
+// adjustedExponent returns the exponent of the most significant digit of d.
+func adjustedExponent(d Decimal) int32 {
+	return d.Exponent() + int32(d.NumDigits()) - 1
+}
+
+// exactFromFloat64 returns the exact value of a finite float64, which always has
+// a terminating decimal expansion.
+func exactFromFloat64(f float64) Decimal {
+	frac, exp := math.Frexp(f)
+	// frac is in [0.5, 1), so frac * 2^53 is an integer
+	mant := new(big.Int).SetInt64(int64(frac * (1 << 53)))
+	exp -= 53
+	if exp >= 0 {
+		return NewFromBigInt(mant.Lsh(mant, uint(exp)), 0)
+	}
+	// m * 2^exp = m * 5^-exp * 10^exp
+	five := new(big.Int).Exp(fiveInt, big.NewInt(int64(-exp)), nil)
+	return NewFromBigInt(mant.Mul(mant, five), int32(exp))
+}
+
+// roundFloat64 returns the float64 nearest to the non-negative x in the given
+// rounding mode, and its accuracy. big.Float.Float64 always rounds to nearest
+// even; the other modes step to the neighbouring float64 where they differ.
+func roundFloat64(x *big.Float, mode big.RoundingMode) (float64, big.Accuracy) {
+	f, acc := x.Float64()
+	switch mode {
+	case big.ToNearestAway:
+		if acc == big.Below {
+			// rounded down, which for a tie means x is the midpoint of f and next
+			next := math.Nextafter(f, math.Inf(1))
+			mid := new(big.Float).SetPrec(64).SetFloat64(f)
+			mid.Add(mid, new(big.Float).SetFloat64(next))
+			if mid.SetMantExp(mid, -1).Cmp(x) == 0 {
+				return next, big.Above
+			}
+		}
+	case big.ToZero, big.ToNegativeInf:
+		if acc == big.Above {
+			return math.Nextafter(f, 0), big.Below
+		}
+	case big.AwayFromZero, big.ToPositiveInf:
+		if acc == big.Below {
+			return math.Nextafter(f, math.Inf(1)), big.Above
+		}
+	}
+	return f, acc
+}
@@ -0,0 +1,351 @@
+package safem
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// The WeiToEther functions as they were before ConversionPolicy, kept to show the
+// presets preserve their behavior.
+
+func legacyWeiToEther(wei *big.Int) (float64, error) {
+	if wei == nil {
+		return 0, errors.New("wei value is nil")
+	}
+	if wei.Sign() < 0 {
+		return 0, errors.New("negative wei value is invalid")
+	}
+	weiFloat := new(big.Float).SetInt(wei)
+	etherFloat := new(big.Float).Quo(weiFloat, big.NewFloat(1e18))
+	ether, accuracy := etherFloat.Float64()
+	if accuracy == big.Above {
+		return 0, errors.New("precision loss during conversion to float64")
+	}
+	return ether, nil
+}
+
+func legacyWeiToEtherOptimized(wei *big.Int) (float64, error) {
+	if wei == nil {
+		return 0, errors.New("wei value is nil")
+	}
+	if wei.Sign() < 0 {
+		return 0, errors.New("negative wei value is invalid")
+	}
+	if wei.IsInt64() {
+		weiInt64 := wei.Int64()
+		maxSafeInt64 := int64(9.223372036854775807e18)
+		if weiInt64 <= maxSafeInt64 {
+			result := float64(weiInt64) / 1e18
+			if result != 0 && result < 1e-17 {
+				return 0, errors.New("precision loss during conversion to float64")
+			}
+			return result, nil
+		}
+	}
+	weiFloat := new(big.Float).SetInt(wei)
+	etherFloat := new(big.Float).Quo(weiFloat, etherDivisor)
+	ether, accuracy := etherFloat.Float64()
+	if accuracy == big.Above {
+		return 0, errors.New("precision loss during conversion to float64")
+	}
+	return ether, nil
+}
+
+func legacyWeiToEtherSafe(wei *big.Int) (float64, error) {
+	if wei == nil {
+		return 0, errors.New("wei value is nil")
+	}
+	if wei.Sign() < 0 {
+		return 0, errors.New("negative wei value is invalid")
+	}
+	if wei.Cmp(maxSafeWei) > 0 {
+		return 0, errors.New("wei value too large for precise float64 conversion")
+	}
+	weiFloat := new(big.Float).SetInt(wei)
+	etherFloat := new(big.Float).Quo(weiFloat, etherDivisor)
+	ether, accuracy := etherFloat.Float64()
+	if accuracy == big.Above {
+		return 0, errors.New("precision loss during conversion to float64")
+	}
+	return ether, nil
+}
+
+// conversionCorpus returns boundary values and random values of every magnitude.
+func conversionCorpus() []*big.Int {
+	values := []*big.Int{
+		nil,
+		big.NewInt(-1),
+		big.NewInt(0),
+		big.NewInt(1),
+		big.NewInt(9),
+		big.NewInt(10),
+		big.NewInt(11),
+		big.NewInt(123456),
+		big.NewInt(1e18),
+		big.NewInt(math.MaxInt64),
+		new(big.Int).Lsh(oneInt, 53),
+		new(big.Int).Lsh(oneInt, 54),
+		new(big.Int).Add(big.NewInt(math.MaxInt64), oneInt),
+		new(big.Int).Sub(maxSafeWei, oneInt),
+		new(big.Int).Set(maxSafeWei),
+		new(big.Int).Add(maxSafeWei, oneInt),
+		new(big.Int).Exp(tenInt, big.NewInt(326), nil),
+		new(big.Int).Exp(tenInt, big.NewInt(400), nil),
+	}
+
+	r := rand.New(rand.NewSource(43))
+	for bits := 1; bits <= 120; bits++ {
+		for i := 0; i < 40; i++ {
+			v := new(big.Int).Rand(r, new(big.Int).Lsh(oneInt, uint(bits)))
+			values = append(values, v, new(big.Int).Neg(v))
+		}
+	}
+	return values
+}
+
+func TestConversionPresetsPreserveBehavior(t *testing.T) {
+	tests := []struct {
+		name   string
+		got    func(*big.Int) (float64, error)
+		legacy func(*big.Int) (float64, error)
+	}{
+		{"WeiToEther", WeiToEther, legacyWeiToEther},
+		{"WeiToEtherOptimized", WeiToEtherOptimized, legacyWeiToEtherOptimized},
+		{"WeiToEtherSafe", WeiToEtherSafe, legacyWeiToEtherSafe},
+	}
+
+	for _, tt := range tests {
+		for _, wei := range conversionCorpus() {
+			got, err := tt.got(wei)
+			expected, expectedErr := tt.legacy(wei)
+			if math.Float64bits(got) != math.Float64bits(expected) {
+				t.Errorf("%s(%v): Expected %g, got %g", tt.name, wei, expected, got)
+			}
+			if (err == nil) != (expectedErr == nil) || err != nil && err.Error() != expectedErr.Error() {
+				t.Errorf("%s(%v): Expected error %v, got %v", tt.name, wei, expectedErr, err)
+			}
+		}
+	}
+}
+
+func TestConversionResultMatchesPresets(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  func(*big.Int) (float64, error)
+		result func(*big.Int) (ConversionResult, error)
+	}{
+		{"WeiToEther", WeiToEther, WeiToEtherResult},
+		{"WeiToEtherOptimized", WeiToEtherOptimized, WeiToEtherOptimizedResult},
+		{"WeiToEtherSafe", WeiToEtherSafe, WeiToEtherSafeResult},
+	}
+
+	for _, tt := range tests {
+		for _, wei := range conversionCorpus() {
+			value, err := tt.value(wei)
+			r, rerr := tt.result(wei)
+			if (err == nil) != (rerr == nil) || err != nil && err.Error() != rerr.Error() {
+				t.Errorf("%s(%v): Expected error %v, got %v", tt.name, wei, err, rerr)
+				continue
+			}
+			if err == nil && math.Float64bits(r.Value) != math.Float64bits(value) {
+				t.Errorf("%s(%v): Expected %g, got %g", tt.name, wei, value, r.Value)
+			}
+			if err != nil || math.IsInf(r.Value, 0) {
+				continue
+			}
+			if r.AbsErr.Sign() < 0 || r.RelErr.Sign() < 0 || r.Exact != (r.Accuracy == big.Exact) {
+				t.Errorf("%s(%v): inconsistent result %+v", tt.name, wei, r)
+			}
+			// Value is the amount plus or minus AbsErr, in the direction of Accuracy
+			amount := NewFromBigInt(wei, -18)
+			if !exactFromFloat64(r.Value).Equal(amount.Add(r.AbsErr.Mul(New(int64(r.Accuracy), 0)))) {
+				t.Errorf("%s(%v): %g is not %s %s by %s", tt.name, wei, r.Value, amount, r.Accuracy, r.AbsErr)
+			}
+		}
+	}
+}
+
+func TestConversionResultRejected(t *testing.T) {
+	// the rejected float64 is still described
+	r, err := WeiToEtherOptimizedResult(big.NewInt(9))
+	if !errors.Is(err, ErrPrecisionLoss) || r.Value != 9e-18 || r.Accuracy != big.Below || r.Exact {
+		t.Errorf("Expected 9e-18 Below with ErrPrecisionLoss, got %g %s %v", r.Value, r.Accuracy, err)
+	}
+	if r.RelErr.String() != "0.00000000000000001405446236258855785" {
+		t.Errorf("Expected RelErr 0.00000000000000001405446236258855785, got %s", r.RelErr.String())
+	}
+
+	r, err = WeiToEtherSafeResult(new(big.Int).Add(maxSafeWei, oneInt))
+	if !errors.Is(err, ErrTooLarge) || r.Value != 0 || !r.AbsErr.IsZero() {
+		t.Errorf("Expected zero result with ErrTooLarge, got %+v %v", r, err)
+	}
+}
+
+func TestExactFromFloat64(t *testing.T) {
+	tests := []struct {
+		f        float64
+		expected string
+	}{
+		{0, "0"},
+		{1, "1"},
+		{-2.5, "-2.5"},
+		{0.1, "0.1000000000000000055511151231257827021181583404541015625"},
+		{1e22, "10000000000000000000000"},
+		{1e23, "99999999999999991611392"},
+	}
+	for _, tt := range tests {
+		if got := exactFromFloat64(tt.f).String(); got != tt.expected {
+			t.Errorf("Expected %s, got %s", tt.expected, got)
+		}
+	}
+
+	// every float64 has a terminating expansion; check the extremes round-trip
+	for _, f := range []float64{math.SmallestNonzeroFloat64, math.MaxFloat64, 2.2250738585072014e-308} {
+		if got, _ := exactFromFloat64(f).Float64(); got != f {
+			t.Errorf("Expected %g, got %g", f, got)
+		}
+	}
+}
+
+func TestConversionPolicyReasons(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   ConversionPolicy
+		wei      *big.Int
+		reason   ConversionReason
+		sentinel error
+	}{
+		{"nil", DefaultConversionPolicy, nil, ReasonNil, ErrInvalidInput},
+		{"negative", DefaultConversionPolicy, big.NewInt(-1), ReasonNegative, ErrNegativeInput},
+		{"too large", SafeConversionPolicy, new(big.Int).Add(maxSafeWei, oneInt), ReasonTooLarge, ErrTooLarge},
+		{"below minimum", OptimizedConversionPolicy, big.NewInt(9), ReasonBelowMinimum, ErrPrecisionLoss},
+		{"rounded up", DefaultConversionPolicy, big.NewInt(1), ReasonInexact, ErrPrecisionLoss},
+		{"rounded down", ConversionPolicy{}, big.NewInt(3), ReasonInexact, ErrPrecisionLoss},
+		{"overflow", ConversionPolicy{Accept: AcceptInexact}, new(big.Int).Exp(tenInt, big.NewInt(400), nil), ReasonTooLarge, ErrTooLarge},
+	}
+
+	for _, tt := range tests {
+		_, err := tt.policy.WeiToEther(tt.wei)
+		var cerr *ConversionError
+		if !errors.As(err, &cerr) {
+			t.Errorf("%s: Expected *ConversionError, got %v", tt.name, err)
+			continue
+		}
+		if cerr.Reason != tt.reason {
+			t.Errorf("%s: Expected reason %s, got %s", tt.name, tt.reason, cerr.Reason)
+		}
+		if !errors.Is(err, tt.sentinel) {
+			t.Errorf("%s: Expected %v, got %v", tt.name, tt.sentinel, err)
+		}
+	}
+
+	// the report carries the rejected result
+	_, err := DefaultConversionPolicy.WeiToEther(big.NewInt(1))
+	var cerr *ConversionError
+	if errors.As(err, &cerr) && (cerr.Result != 1e-18 || cerr.Accuracy != big.Above || cerr.Wei.Int64() != 1) {
+		t.Errorf("Expected 1e-18 Above for 1 wei, got %g %s %v", cerr.Result, cerr.Accuracy, cerr.Wei)
+	}
+}
+
+func TestConversionPolicyOptions(t *testing.T) {
+	one := big.NewInt(1)
+
+	// exact results are accepted by the zero policy
+	if got, err := (ConversionPolicy{}).WeiToEther(big.NewInt(5e17)); err != nil || got != 0.5 {
+		t.Errorf("Expected 0.5, got %g %v", got, err)
+	}
+
+	// 1 wei rounds up to nearest, so accepting it needs AcceptAbove or rounding down
+	if got, err := (ConversionPolicy{Accept: AcceptInexact}).WeiToEther(one); err != nil || got != 1e-18 {
+		t.Errorf("Expected 1e-18, got %g %v", got, err)
+	}
+	got, err := (ConversionPolicy{Accept: AcceptBelow, Rounding: big.ToZero}).WeiToEther(one)
+	if err != nil || got >= 1e-18 || got < math.Nextafter(1e-18, 0) {
+		t.Errorf("Expected the float64 below 1e-18, got %g %v", got, err)
+	}
+	if _, err := (ConversionPolicy{Accept: AcceptBelow, Rounding: big.AwayFromZero}).WeiToEther(big.NewInt(3)); err == nil {
+		t.Errorf("Expected error for rounding away from zero")
+	}
+
+	policy := ConversionPolicy{Accept: AcceptInexact, MinNonZero: 1e-9, MaxMagnitude: big.NewInt(1e18)}
+	if _, err := policy.WeiToEther(big.NewInt(999999999)); !errors.Is(err, ErrPrecisionLoss) {
+		t.Errorf("Expected ErrPrecisionLoss, got %v", err)
+	}
+	if got, err := policy.WeiToEther(big.NewInt(1e9)); err != nil || got != 1e-9 {
+		t.Errorf("Expected 1e-9, got %g %v", got, err)
+	}
+	if got, err := policy.WeiToEther(big.NewInt(0)); err != nil || got != 0 {
+		t.Errorf("Expected 0, got %g %v", got, err)
+	}
+	if _, err := policy.WeiToEther(big.NewInt(1e18 + 1)); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Expected ErrTooLarge, got %v", err)
+	}
+}
+
+func TestAccuracySet(t *testing.T) {
+	tests := []struct {
+		set                 AccuracySet
+		below, exact, above bool
+	}{
+		{0, false, true, false},
+		{AcceptBelow, true, true, false},
+		{AcceptAbove, false, true, true},
+		{AcceptInexact, true, true, true},
+	}
+	for _, tt := range tests {
+		if tt.set.Accepts(big.Below) != tt.below || tt.set.Accepts(big.Exact) != tt.exact || tt.set.Accepts(big.Above) != tt.above {
+			t.Errorf("AccuracySet(%d): Expected %v %v %v", tt.set, tt.below, tt.exact, tt.above)
+		}
+	}
+}
+
+func BenchmarkConversionPolicy(b *testing.B) {
+	wei := big.NewInt(123456789012345678)
+	b.ReportAllocs()
+	b.Run("Optimized", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			OptimizedConversionPolicy.WeiToEther(wei)
+		}
+	})
+	b.Run("Default", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			DefaultConversionPolicy.WeiToEther(wei)
+		}
+	})
+}
+
+func TestRoundFloat64(t *testing.T) {
+	tie := new(big.Float).SetMantExp(new(big.Float).SetInt64(1<<53+1), -53) // 1 + 2^-53
+	above := math.Nextafter(1, 2)
+	third := new(big.Float).SetPrec(200).Quo(big.NewFloat(1), big.NewFloat(3))
+	thirdUp := math.Nextafter(1.0/3, 1) // 1/3 rounds down to nearest
+
+	tests := []struct {
+		x        *big.Float
+		mode     big.RoundingMode
+		expected float64
+		accuracy big.Accuracy
+	}{
+		{tie, big.ToNearestEven, 1, big.Below},
+		{tie, big.ToNearestAway, above, big.Above},
+		{tie, big.ToZero, 1, big.Below},
+		{tie, big.AwayFromZero, above, big.Above},
+		{third, big.ToNearestEven, 1.0 / 3, big.Below},
+		{third, big.ToNearestAway, 1.0 / 3, big.Below},
+		{third, big.ToNegativeInf, 1.0 / 3, big.Below},
+		{third, big.ToPositiveInf, thirdUp, big.Above},
+		{big.NewFloat(0.5), big.AwayFromZero, 0.5, big.Exact},
+		{new(big.Float).SetMantExp(big.NewFloat(1), -1080), big.ToPositiveInf, math.SmallestNonzeroFloat64, big.Above},
+		{new(big.Float).SetMantExp(big.NewFloat(1), 1100), big.ToZero, math.MaxFloat64, big.Below},
+	}
+
+	for _, tt := range tests {
+		got, acc := roundFloat64(tt.x, tt.mode)
+		if got != tt.expected || acc != tt.accuracy {
+			t.Errorf("%s %s: Expected %g %s, got %g %s", tt.x.Text('g', 20), tt.mode, tt.expected, tt.accuracy, got, acc)
+		}
+	}
+}
@@ -16,7 +16,11 @@ import (
 )
 
 var (
-	ln10 = newConstApproximation(strLn10)
+	ln10  = newConstApproximation(strLn10)
+	ln2   = newConstApproximation(strLn2)
+	pi    = newConstApproximation(strPi)
+	euler = newConstApproximation(strE)
+	sqrt2 = newConstApproximation(strSqrt2)
 )
 
 type constApproximation struct {
@@ -106,87 +110,148 @@ func (a *decimal) Assign(v uint64) {
 	a.dp = a.nd
 	a.neg = false
 	a.trunc = false
+	trim(a)
 }
 
-// Shift shifts the decimal left or right by the given amount.
+// maxShift is the largest binary shift done in one step; digits shifted by it
+// must not overflow a uint.
+const maxShift = 64 - 4
+
+// Shift multiplies the decimal by 2^k, or divides it by 2^-k when k is negative.
 func (a *decimal) Shift(k int) {
 	switch {
 	case a.nd == 0:
 		// nothing to do: a == 0
 	case k > 0:
-		for k > 0 {
-			var r byte
-			if a.nd > 0 {
-				r = a.d[0] % 10
-			}
-			a.ShiftLeft(1)
-			if r != 0 {
-				a.trunc = true
-			}
-			k--
+		for k > maxShift {
+			a.leftShift(maxShift)
+			k -= maxShift
 		}
+		a.leftShift(uint(k))
 	case k < 0:
-		for k < 0 {
-			if a.nd == 0 {
-				a.dp = 0
-				return
-			}
-			a.ShiftRight(1)
-			k++
+		for k < -maxShift {
+			a.rightShift(maxShift)
+			k += maxShift
 		}
+		a.rightShift(uint(-k))
 	}
 }
 
-// ShiftLeft shifts the decimal left by k digits.
-func (a *decimal) ShiftLeft(k int) {
-	if a.nd == 0 {
-		return
+// leftShift multiplies the decimal by 2^k, working from the last digit to the
+// first.
+func (a *decimal) leftShift(k uint) {
+	// a * 2^k has at most floor(k*log10(2)) + 1 more digits than a
+	end := a.nd + int(k*30103/100000) + 1
+	r, w := a.nd, end
+	var n uint
+	for r--; r >= 0; r-- {
+		n += (uint(a.d[r]) - '0') << k
+		quo := n / 10
+		w--
+		a.put(w, byte(n-10*quo))
+		n = quo
 	}
-	if k <= 0 {
-		return
+	for n > 0 {
+		quo := n / 10
+		w--
+		a.put(w, byte(n-10*quo))
+		n = quo
 	}
-	if a.nd+k > cap(a.d) {
-		// overflow
-		a.nd = 0
-		a.dp = 0
-		return
+
+	// the digits are in d[w:end], move them to the front
+	a.dp += end - w - a.nd
+	if end > len(a.d) {
+		end = len(a.d)
 	}
-	copy(a.d[k:], a.d[:a.nd])
-	for i := 0; i < k; i++ {
-		a.d[i] = '0'
+	a.nd = copy(a.d[:], a.d[w:end])
+	trim(a)
+}
+
+// put stores digit v at position i, or records a truncation when i is past the
+// end of the buffer.
+func (a *decimal) put(i int, v byte) {
+	if i < len(a.d) {
+		a.d[i] = v + '0'
+	} else if v != 0 {
+		a.trunc = true
 	}
-	a.nd += k
-	a.dp += k
 }
 
-// ShiftRight shifts the decimal right by k digits.
-func (a *decimal) ShiftRight(k int) {
-	if a.nd == 0 {
-		return
+// rightShift divides the decimal by 2^k, working from the first digit to the
+// last.
+func (a *decimal) rightShift(k uint) {
+	r, w := 0, 0
+	var n uint
+
+	// pick up enough leading digits to cover the first shift
+	for ; n>>k == 0; r++ {
+		if r >= a.nd {
+			if n == 0 {
+				// a == 0; shouldn't happen, but handle anyway
+				a.nd = 0
+				return
+			}
+			for n>>k == 0 {
+				n *= 10
+				r++
+			}
+			break
+		}
+		n = n*10 + uint(a.d[r]) - '0'
 	}
-	if k <= 0 {
-		return
+	a.dp -= r - 1
+
+	mask := uint(1)<<k - 1
+	for ; r < a.nd; r++ {
+		dig := n >> k
+		n &= mask
+		a.d[w] = byte(dig) + '0'
+		w++
+		n = n*10 + uint(a.d[r]) - '0'
+	}
+	for n > 0 {
+		dig := n >> k
+		n &= mask
+		if w < len(a.d) {
+			a.d[w] = byte(dig) + '0'
+			w++
+		} else if dig > 0 {
+			a.trunc = true
+		}
+		n *= 10
+	}
+	a.nd = w
+	trim(a)
+}
+
+// trim removes trailing zeros.
+func trim(a *decimal) {
+	for a.nd > 0 && a.d[a.nd-1] == '0' {
+		a.nd--
 	}
-	if k >= a.nd {
-		a.nd = 0
+	if a.nd == 0 {
 		a.dp = 0
-		return
 	}
-	copy(a.d[:a.nd-k], a.d[k:a.nd])
-	a.nd -= k
-	a.dp -= k
+}
+
+// shouldRoundUp reports whether the decimal should be rounded up when it is cut
+// to nd digits. Exact halves round to even.
+func (a *decimal) shouldRoundUp(nd int) bool {
+	if a.d[nd] == '5' && nd+1 == a.nd {
+		if a.trunc {
+			return true
+		}
+		return nd > 0 && (a.d[nd-1]-'0')%2 == 1
+	}
+	return a.d[nd] >= '5'
 }
 
 // Round rounds the decimal to the given number of digits.
 func (a *decimal) Round(nd int) {
-	if nd >= a.nd || nd < 0 {
-		return
-	}
-	if nd == 0 {
-		a.nd = 0
+	if nd < 0 || nd >= a.nd {
 		return
 	}
-	if a.d[nd] >= '5' {
+	if a.shouldRoundUp(nd) {
 		a.RoundUp(nd)
 	} else {
 		a.RoundDown(nd)
@@ -213,10 +278,11 @@ func (a *decimal) RoundUp(nd int) {
 
 // RoundDown rounds the decimal down to the given number of digits.
 func (a *decimal) RoundDown(nd int) {
-	if nd >= a.nd {
+	if nd < 0 || nd >= a.nd {
 		return
 	}
 	a.nd = nd
+	trim(a)
 }
 
 // roundShortest rounds d (= mant * 2^exp) to the shortest number of digits
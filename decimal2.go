@@ -258,6 +258,8 @@ func NewFromString(value string) (Decimal, error) {
 // NewFromFormattedString returns a new Decimal from a formatted string representation.
 // The second argument - replRegexp, is a regular expression that is used to find characters that should be
 // removed from given decimal string representation. All matched characters will be replaced with an empty string.
+// The regexp cannot tell a group separator from a decimal mark; use NewFromLocaleString or a Parser for
+// numbers formatted for a locale.
 //
 // Example:
 //
@@ -1817,6 +1819,8 @@ func (d *Decimal) UnmarshalBinary(data []byte) error {
 }
 
 // MarshalBinary implements the encoding.BinaryMarshaler interface.
+// Equal values with different exponents encode differently; see MarshalCanonical for a
+// canonical encoding and MarshalKey for one that sorts in numeric order.
 func (d Decimal) MarshalBinary() (data []byte, err error) {
 	// exp is written first, but encode value first to know output size
 	var valueData []byte
@@ -2112,6 +2116,7 @@ func (d NullDecimal) MarshalText() (text []byte, err error) {
 // Trig functions
 
 // Atan returns the arctangent, in radians, of x.
+// The result has roughly float64 accuracy; use AtanPrec for a chosen precision.
 func (d Decimal) Atan() Decimal {
 	if d.Equal(NewFromFloat(0.0)) {
 		return d
@@ -2168,6 +2173,7 @@ var _sin = [...]Decimal{
 }
 
 // Sin returns the sine of the radian argument x.
+// The result has roughly float64 accuracy; use SinPrec for a chosen precision.
 func (d Decimal) Sin() Decimal {
 	PI4A := NewFromFloat(7.85398125648498535156e-1)                             // 0x3fe921fb40000000, Pi/4 split into three parts
 	PI4B := NewFromFloat(3.77489470793079817668e-8)                             // 0x3e64442d00000000,
@@ -2224,6 +2230,7 @@ var _cos = [...]Decimal{
 }
 
 // Cos returns the cosine of the radian argument x.
+// The result has roughly float64 accuracy; use CosPrec for a chosen precision.
 func (d Decimal) Cos() Decimal {
 
 	PI4A := NewFromFloat(7.85398125648498535156e-1)                             // 0x3fe921fb40000000, Pi/4 split into three parts
@@ -2284,6 +2291,7 @@ var _tanQ = [...]Decimal{
 }
 
 // Tan returns the tangent of the radian argument x.
+// The result has roughly float64 accuracy; use TanPrec for a chosen precision.
 func (d Decimal) Tan() Decimal {
 
 	PI4A := NewFromFloat(7.85398125648498535156e-1)                             // 0x3fe921fb40000000, Pi/4 split into three parts
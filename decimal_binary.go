@@ -0,0 +1,406 @@
+package safem
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// canonicalVersion is the first byte of every canonical encoding.
+const canonicalVersion = 1
+
+// Sign bytes of the canonical encoding. canonicalInline marks a coefficient that
+// fits in a uint64 and is written as a uvarint instead of length-prefixed bytes.
+const (
+	canonicalZero     = 0x00
+	canonicalPositive = 0x01
+	canonicalNegative = 0x02
+	canonicalInline   = 0x04
+)
+
+// Tag bytes of the key encoding, ordered so that negatives sort before zero and
+// zero before positives.
+const (
+	keyNegative = 0x01
+	keyZero     = 0x02
+	keyPositive = 0x03
+)
+
+// Exponents in [-keyExpSmall, keyExpSmall] take a single byte in the key encoding.
+const keyExpSmall = 109
+
+// MarshalCanonical returns the canonical binary encoding of d: a version byte, a
+// sign byte, the exponent as a zigzag varint and the coefficient as a uvarint, or
+// as length-prefixed big-endian bytes when it does not fit in 64 bits.
+//
+// Trailing zeros of the coefficient are removed first, so numerically equal
+// decimals such as 1.0 and 1.00 always encode to the same bytes, and small values
+// take only a few bytes. Unlike MarshalBinary the encoding is identical across
+// versions of Go.
+//
+// Example:
+//
+//	RequireFromString("1.50").MarshalCanonical() // output: [0x01 0x05 0x01 0x0f]
+func (d Decimal) MarshalCanonical() []byte {
+	return d.AppendCanonical(nil)
+}
+
+// AppendCanonical appends the canonical binary encoding of d to dst, see MarshalCanonical.
+func (d Decimal) AppendCanonical(dst []byte) []byte {
+	dst = append(dst, canonicalVersion)
+	d.ensureInitialized()
+	if d.value.Sign() == 0 {
+		return append(dst, canonicalZero)
+	}
+
+	coef, exp := d.normalized()
+
+	sign := byte(canonicalPositive)
+	if d.value.Sign() < 0 {
+		sign = canonicalNegative
+	}
+	if coef.IsUint64() {
+		sign |= canonicalInline
+	}
+
+	dst = append(dst, sign)
+	dst = binary.AppendVarint(dst, exp)
+	if coef.IsUint64() {
+		return binary.AppendUvarint(dst, coef.Uint64())
+	}
+	mag := coef.Bytes()
+	dst = binary.AppendUvarint(dst, uint64(len(mag)))
+	return append(dst, mag...)
+}
+
+// UnmarshalCanonical decodes a decimal written by MarshalCanonical.
+//
+// Returns error for an unknown version, truncated or trailing data, and any
+// encoding that is not the canonical one for its value.
+func (d *Decimal) UnmarshalCanonical(data []byte) error {
+	if len(data) < 2 {
+		return fmt.Errorf("cannot decode canonical decimal: expected at least 2 bytes, got %d", len(data))
+	}
+	if data[0] != canonicalVersion {
+		return fmt.Errorf("cannot decode canonical decimal: unknown version %d", data[0])
+	}
+
+	sign := data[1]
+	rest := data[2:]
+	if sign == canonicalZero {
+		if len(rest) != 0 {
+			return fmt.Errorf("cannot decode canonical decimal: %d trailing bytes after zero", len(rest))
+		}
+		*d = New(0, 0)
+		return nil
+	}
+	if s := sign &^ canonicalInline; s != canonicalPositive && s != canonicalNegative {
+		return fmt.Errorf("cannot decode canonical decimal: invalid sign byte %#x", sign)
+	}
+
+	exp, n := binary.Varint(rest)
+	if n <= 0 {
+		return fmt.Errorf("cannot decode canonical decimal: invalid exponent")
+	}
+	rest = rest[n:]
+
+	coef := new(big.Int)
+	if sign&canonicalInline != 0 {
+		u, n := binary.Uvarint(rest)
+		if n <= 0 {
+			return fmt.Errorf("cannot decode canonical decimal: invalid coefficient")
+		}
+		coef.SetUint64(u)
+		rest = rest[n:]
+	} else {
+		size, n := binary.Uvarint(rest)
+		if n <= 0 || size > uint64(len(rest)-n) {
+			return fmt.Errorf("cannot decode canonical decimal: invalid coefficient length")
+		}
+		coef.SetBytes(rest[n : n+int(size)])
+		rest = rest[n+int(size):]
+	}
+	if len(rest) != 0 {
+		return fmt.Errorf("cannot decode canonical decimal: %d trailing bytes", len(rest))
+	}
+	if sign&^canonicalInline == canonicalNegative {
+		coef.Neg(coef)
+	}
+
+	v, err := decimalFromNormalized(coef, exp)
+	if err != nil {
+		return fmt.Errorf("cannot decode canonical decimal: %s", err)
+	}
+	// rejecting anything that does not re-encode to the same bytes rules out
+	// non-minimal varints, leading zero bytes and unstripped trailing zeros at once
+	if !bytes.Equal(v.MarshalCanonical(), data) {
+		return fmt.Errorf("cannot decode canonical decimal: encoding is not canonical")
+	}
+
+	*d = v
+	return nil
+}
+
+// NewFromCanonical returns a new Decimal from its canonical binary encoding, see
+// MarshalCanonical.
+func NewFromCanonical(data []byte) (Decimal, error) {
+	var d Decimal
+	err := d.UnmarshalCanonical(data)
+	return d, err
+}
+
+// MarshalKey returns an order-preserving encoding of d: for any two decimals the
+// byte-lexicographic order of their keys equals their numeric order, so keys can
+// be used directly in sorted key-value stores such as LevelDB or Pebble.
+//
+// Numerically equal decimals have equal keys. Keys are self-delimiting, so they
+// can be concatenated with other key parts and read back with NewFromKey.
+//
+// Example:
+//
+//	a := RequireFromString("-2").MarshalKey()
+//	b := RequireFromString("1.5").MarshalKey()
+//	bytes.Compare(a, b) // output: -1
+func (d Decimal) MarshalKey() []byte {
+	return d.AppendKey(nil)
+}
+
+// AppendKey appends the order-preserving encoding of d to dst, see MarshalKey.
+//
+// The layout is a tag byte (negative, zero or positive), followed for nonzero
+// values by the decimal exponent E of 0.ddd × 10^E and the digits in pairs, each
+// pair p stored as p+1 and ended by 0x00. Bytes after the tag are inverted for
+// negative values so that larger magnitudes sort first.
+func (d Decimal) AppendKey(dst []byte) []byte {
+	d.ensureInitialized()
+	if d.value.Sign() == 0 {
+		return append(dst, keyZero)
+	}
+
+	coef, exp := d.normalized()
+	digits := coef.String()
+	e := exp + int64(len(digits))
+
+	tag := byte(keyPositive)
+	if d.value.Sign() < 0 {
+		tag = keyNegative
+	}
+	dst = append(dst, tag)
+	start := len(dst)
+
+	dst = appendKeyExponent(dst, e)
+	if len(digits)%2 == 1 {
+		digits += "0"
+	}
+	for i := 0; i < len(digits); i += 2 {
+		pair := (digits[i]-'0')*10 + digits[i+1] - '0'
+		dst = append(dst, pair+1)
+	}
+	dst = append(dst, 0x00)
+
+	if tag == keyNegative {
+		for i := start; i < len(dst); i++ {
+			dst[i] = ^dst[i]
+		}
+	}
+	return dst
+}
+
+// NewFromKey decodes the decimal at the start of key, written by MarshalKey, and
+// returns it together with the remaining bytes.
+//
+// Returns error for truncated or malformed keys.
+func NewFromKey(key []byte) (Decimal, []byte, error) {
+	if len(key) == 0 {
+		return Decimal{}, nil, fmt.Errorf("cannot decode decimal key: empty key")
+	}
+
+	tag := key[0]
+	switch tag {
+	case keyZero:
+		return New(0, 0), key[1:], nil
+	case keyPositive, keyNegative:
+	default:
+		return Decimal{}, nil, fmt.Errorf("cannot decode decimal key: invalid tag %#x", tag)
+	}
+
+	// the exponent is read from a copy so negative keys can share the decoding
+	head := append([]byte(nil), key[1:min(len(key), 10)]...)
+	if tag == keyNegative {
+		for i := range head {
+			head[i] = ^head[i]
+		}
+	}
+	e, n, err := readKeyExponent(head)
+	if err != nil {
+		return Decimal{}, nil, err
+	}
+
+	terminator := byte(0x00)
+	if tag == keyNegative {
+		terminator = 0xff
+	}
+	body := key[1+n:]
+	end := bytes.IndexByte(body, terminator)
+	if end < 0 {
+		return Decimal{}, nil, fmt.Errorf("cannot decode decimal key: missing terminator")
+	}
+	pairs, rest := body[:end], body[end+1:]
+	if len(pairs) == 0 {
+		return Decimal{}, nil, fmt.Errorf("cannot decode decimal key: missing digits")
+	}
+
+	var sb strings.Builder
+	for i, b := range pairs {
+		if tag == keyNegative {
+			b = ^b
+		}
+		if b < 1 || b > 100 {
+			return Decimal{}, nil, fmt.Errorf("cannot decode decimal key: invalid digit pair %#x", b)
+		}
+		p := b - 1
+		sb.WriteByte('0' + p/10)
+		if i < len(pairs)-1 || p%10 != 0 {
+			sb.WriteByte('0' + p%10)
+		}
+	}
+	digits := sb.String()
+	if digits[0] == '0' || digits[len(digits)-1] == '0' {
+		return Decimal{}, nil, fmt.Errorf("cannot decode decimal key: digits are not normalized")
+	}
+
+	coef, _ := new(big.Int).SetString(digits, 10)
+	if tag == keyNegative {
+		coef.Neg(coef)
+	}
+	d, err := decimalFromNormalized(coef, e-int64(len(digits)))
+	if err != nil {
+		return Decimal{}, nil, fmt.Errorf("cannot decode decimal key: %s", err)
+	}
+	return d, rest, nil
+}
+
+// normalized returns the absolute coefficient of d without trailing zeros and
+// the matching exponent.
+func (d Decimal) normalized() (*big.Int, int64) {
+	coef := new(big.Int).Abs(d.value)
+	exp := int64(d.exp)
+
+	q, r := new(big.Int), new(big.Int)
+	for {
+		q.QuoRem(coef, tenInt, r)
+		if r.Sign() != 0 {
+			return coef, exp
+		}
+		coef, q = q, coef
+		exp++
+	}
+}
+
+// decimalFromNormalized builds a decimal from a coefficient and an exponent that
+// may exceed the int32 range of Decimal; large exponents are brought back into
+// range by appending zeros to the coefficient.
+func decimalFromNormalized(coef *big.Int, exp int64) (Decimal, error) {
+	if exp < math.MinInt32 {
+		return Decimal{}, fmt.Errorf("exponent %d out of range", exp)
+	}
+	if exp > math.MaxInt32 {
+		shift := exp - math.MaxInt32
+		if shift > 1<<20 {
+			return Decimal{}, fmt.Errorf("exponent %d out of range", exp)
+		}
+		coef.Mul(coef, new(big.Int).Exp(tenInt, big.NewInt(shift), nil))
+		exp = math.MaxInt32
+	}
+	return Decimal{value: coef, exp: int32(exp)}, nil
+}
+
+// appendKeyExponent appends an order-preserving variable-length encoding of e.
+// Small exponents take one byte; larger ones a length byte and 1 to 8 bytes, with
+// the length byte chosen so longer encodings sort outside shorter ones.
+func appendKeyExponent(dst []byte, e int64) []byte {
+	switch {
+	case e >= -keyExpSmall && e <= keyExpSmall:
+		return append(dst, byte(0x80+e))
+	case e > keyExpSmall:
+		u := uint64(e - keyExpSmall - 1)
+		n := byteLen(u)
+		dst = append(dst, byte(0x80+keyExpSmall+n))
+		return appendBigEndian(dst, u, n)
+	default:
+		// e is bounded by the int32 exponent plus the digit count, so this cannot overflow
+		u := uint64(-keyExpSmall - 1 - e)
+		n := byteLen(u)
+		dst = append(dst, byte(0x80-keyExpSmall-n))
+		return appendBigEndian(dst, ^u, n)
+	}
+}
+
+// readKeyExponent decodes an exponent written by appendKeyExponent and returns
+// it with the number of bytes read.
+func readKeyExponent(b []byte) (int64, int, error) {
+	if len(b) == 0 {
+		return 0, 0, fmt.Errorf("cannot decode decimal key: missing exponent")
+	}
+	c := int(b[0])
+	switch {
+	case c >= 0x80-keyExpSmall && c <= 0x80+keyExpSmall:
+		return int64(c - 0x80), 1, nil
+	case c > 0x80+keyExpSmall && c <= 0x80+keyExpSmall+8:
+		n := c - 0x80 - keyExpSmall
+		u, err := readBigEndian(b[1:], n)
+		if err != nil {
+			return 0, 0, err
+		}
+		if u > math.MaxInt64-keyExpSmall-1 || byteLen(u) != n {
+			return 0, 0, fmt.Errorf("cannot decode decimal key: invalid exponent")
+		}
+		return int64(u) + keyExpSmall + 1, 1 + n, nil
+	case c < 0x80-keyExpSmall && c >= 0x80-keyExpSmall-8:
+		n := 0x80 - keyExpSmall - c
+		u, err := readBigEndian(b[1:], n)
+		if err != nil {
+			return 0, 0, err
+		}
+		u = ^u
+		if n < 8 {
+			u &= 1<<(8*uint(n)) - 1
+		}
+		if u > math.MaxInt64-keyExpSmall-1 || byteLen(u) != n {
+			return 0, 0, fmt.Errorf("cannot decode decimal key: invalid exponent")
+		}
+		return -keyExpSmall - 1 - int64(u), 1 + n, nil
+	}
+	return 0, 0, fmt.Errorf("cannot decode decimal key: invalid exponent byte %#x", c)
+}
+
+// byteLen returns the number of bytes needed to hold u, at least 1.
+func byteLen(u uint64) int {
+	n := 1
+	for u > 0xff {
+		u >>= 8
+		n++
+	}
+	return n
+}
+
+func appendBigEndian(dst []byte, u uint64, n int) []byte {
+	for i := n - 1; i >= 0; i-- {
+		dst = append(dst, byte(u>>(8*uint(i))))
+	}
+	return dst
+}
+
+func readBigEndian(b []byte, n int) (uint64, error) {
+	if len(b) < n {
+		return 0, fmt.Errorf("cannot decode decimal key: truncated exponent")
+	}
+	var u uint64
+	for _, c := range b[:n] {
+		u = u<<8 | uint64(c)
+	}
+	return u, nil
+}
@@ -0,0 +1,289 @@
+package safem
+
+import (
+	"bytes"
+	"math"
+	"math/big"
+	"sort"
+	"testing"
+)
+
+func TestDecimalMarshalCanonical(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    Decimal
+		expected []byte
+	}{
+		{"zero", New(0, 0), []byte{0x01, 0x00}},
+		{"zero with exponent", New(0, -5), []byte{0x01, 0x00}},
+		{"one", New(1, 0), []byte{0x01, 0x05, 0x00, 0x01}},
+		{"one with trailing zeros", RequireFromString("1.000"), []byte{0x01, 0x05, 0x00, 0x01}},
+		{"one and a half", RequireFromString("1.50"), []byte{0x01, 0x05, 0x01, 0x0f}},
+		{"negative", RequireFromString("-0.25"), []byte{0x01, 0x06, 0x03, 0x19}},
+		{"thousand", New(1000, 0), []byte{0x01, 0x05, 0x06, 0x01}},
+		{"uint64 max", NewFromBigInt(new(big.Int).SetUint64(math.MaxUint64), 0),
+			[]byte{0x01, 0x05, 0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}},
+		{"beyond uint64", NewFromBigInt(new(big.Int).Lsh(big.NewInt(1), 64), 0),
+			[]byte{0x01, 0x01, 0x00, 0x09, 0x01, 0, 0, 0, 0, 0, 0, 0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.value.MarshalCanonical()
+			if !bytes.Equal(got, tt.expected) {
+				t.Errorf("Expected %x, got %x", tt.expected, got)
+			}
+			d, err := NewFromCanonical(got)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !d.Equal(tt.value) {
+				t.Errorf("Expected %s, got %s", tt.value.String(), d.String())
+			}
+		})
+	}
+}
+
+func TestDecimalUnmarshalCanonicalRejectsNonCanonical(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"unknown version", []byte{0x02, 0x00}},
+		{"zero with trailing bytes", []byte{0x01, 0x00, 0x00}},
+		{"invalid sign", []byte{0x01, 0x03, 0x00, 0x01}},
+		{"trailing zero in coefficient", []byte{0x01, 0x05, 0x00, 0x0a}},
+		{"inline zero coefficient", []byte{0x01, 0x05, 0x00, 0x00}},
+		{"non-minimal varint", []byte{0x01, 0x05, 0x80, 0x00, 0x01}},
+		{"small value not inline", []byte{0x01, 0x01, 0x00, 0x01, 0x07}},
+		{"leading zero byte", []byte{0x01, 0x01, 0x00, 0x0a, 0x00, 0x01, 0, 0, 0, 0, 0, 0, 0, 0}},
+		{"truncated coefficient", []byte{0x01, 0x01, 0x00, 0x09, 0x01}},
+		{"trailing bytes", []byte{0x01, 0x05, 0x00, 0x01, 0x00}},
+		{"exponent out of range", []byte{0x01, 0x05, 0xff, 0xff, 0xff, 0xff, 0x7f, 0x01}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d, err := NewFromCanonical(tt.data); err == nil {
+				t.Errorf("Expected error, got %s", d.String())
+			}
+		})
+	}
+}
+
+func TestDecimalCanonicalExponentLimits(t *testing.T) {
+	// 10 * 10^MaxInt32 normalizes to an exponent just beyond int32
+	for _, d := range []Decimal{New(10, math.MaxInt32), New(7, math.MinInt32), New(-120, math.MaxInt32-1)} {
+		got, err := NewFromCanonical(d.MarshalCanonical())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.Cmp(d) != 0 {
+			t.Errorf("canonical round trip changed the value of %de%d", d.value, d.exp)
+		}
+		key, rest, err := NewFromKey(d.MarshalKey())
+		if err != nil || len(rest) != 0 {
+			t.Fatalf("unexpected key error: %v", err)
+		}
+		if key.Cmp(d) != 0 {
+			t.Errorf("key round trip changed the value of %de%d", d.value, d.exp)
+		}
+	}
+}
+
+func TestDecimalMarshalKeyOrder(t *testing.T) {
+	values := []string{
+		"-1e200", "-123456789012345678901234567890", "-1000", "-999.99", "-10", "-9.5", "-1.01", "-1",
+		"-0.99", "-0.1", "-0.0999", "-0.01", "-1e-150", "0", "1e-150", "0.00001", "0.0001", "0.01", "0.0101",
+		"0.1", "0.12", "0.123", "0.2", "1", "1.000001", "1.1", "9", "10", "11", "99", "100", "101",
+		"1234.5", "1e109", "1e110", "1e111", "1e300", "1e1000",
+	}
+
+	var keys [][]byte
+	for _, v := range values {
+		keys = append(keys, RequireFromString(v).MarshalKey())
+	}
+	for i := 1; i < len(keys); i++ {
+		if bytes.Compare(keys[i-1], keys[i]) >= 0 {
+			t.Errorf("key of %s does not sort before key of %s: %x >= %x", values[i-1], values[i], keys[i-1], keys[i])
+		}
+	}
+
+	shuffled := append([][]byte(nil), keys...)
+	sort.Slice(shuffled, func(i, j int) bool { return bytes.Compare(shuffled[i], shuffled[j]) > 0 })
+	sort.Slice(shuffled, func(i, j int) bool { return bytes.Compare(shuffled[i], shuffled[j]) < 0 })
+	for i := range keys {
+		d, _, err := NewFromKey(shuffled[i])
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !d.Equal(RequireFromString(values[i])) {
+			t.Errorf("Expected %s, got %s", values[i], d.String())
+		}
+	}
+
+	if !bytes.Equal(RequireFromString("1.50").MarshalKey(), RequireFromString("1.5").MarshalKey()) {
+		t.Errorf("equal decimals have different keys")
+	}
+}
+
+func TestNewFromKeyComposite(t *testing.T) {
+	key := RequireFromString("-12.5").AppendKey([]byte("price/"))
+	key = RequireFromString("300").AppendKey(key)
+	key = append(key, "/order-7"...)
+
+	d1, rest, err := NewFromKey(key[len("price/"):])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	d2, rest, err := NewFromKey(rest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d1.String() != "-12.5" || d2.String() != "300" || string(rest) != "/order-7" {
+		t.Errorf("unexpected decoding: %s, %s, %q", d1.String(), d2.String(), rest)
+	}
+}
+
+func TestNewFromKeyRejectsMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		key  []byte
+	}{
+		{"empty", nil},
+		{"invalid tag", []byte{0x04}},
+		{"missing exponent", []byte{keyPositive}},
+		{"missing terminator", []byte{keyPositive, 0x80, 0x02}},
+		{"missing digits", []byte{keyPositive, 0x80, 0x00}},
+		{"invalid digit pair", []byte{keyPositive, 0x80, 0x66, 0x00}},
+		{"leading zero digit", []byte{keyPositive, 0x80, 0x02, 0x00}},
+		{"trailing zero digit", []byte{keyPositive, 0x80, 0x0c, 0x01, 0x00}},
+		{"non-minimal exponent", []byte{keyPositive, 0xef, 0x00, 0x05, 0x02, 0x00}},
+		{"truncated exponent", []byte{keyPositive, 0xef, 0x05}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d, _, err := NewFromKey(tt.key); err == nil {
+				t.Errorf("Expected error, got %s", d.String())
+			}
+		})
+	}
+}
+
+// fuzzDecimal builds a decimal with a bounded exponent from fuzzer input, so that
+// comparisons stay cheap.
+func fuzzDecimal(mag []byte, neg bool, exp int16) Decimal {
+	v := new(big.Int).SetBytes(mag)
+	if neg {
+		v.Neg(v)
+	}
+	return Decimal{value: v, exp: int32(exp)}
+}
+
+func FuzzDecimalCanonicalRoundTrip(f *testing.F) {
+	f.Add([]byte{0x01}, false, int16(0))
+	f.Add([]byte{0x0f}, true, int16(-1))
+	f.Add([]byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, false, int16(5))
+	f.Add([]byte{}, true, int16(-3))
+
+	f.Fuzz(func(t *testing.T, mag []byte, neg bool, exp int16) {
+		d := fuzzDecimal(mag, neg, exp)
+		data := d.MarshalCanonical()
+
+		got, err := NewFromCanonical(data)
+		if err != nil {
+			t.Fatalf("cannot decode %x: %v", data, err)
+		}
+		if !got.Equal(d) {
+			t.Fatalf("Expected %s, got %s", d.String(), got.String())
+		}
+
+		// an equal value with extra trailing zeros encodes identically
+		padded := Decimal{value: new(big.Int).Mul(d.value, big.NewInt(100)), exp: d.exp - 2}
+		if !bytes.Equal(padded.MarshalCanonical(), data) {
+			t.Fatalf("encoding of %s is not canonical", d.String())
+		}
+	})
+}
+
+func FuzzDecimalCanonicalDecode(f *testing.F) {
+	f.Add([]byte{0x01, 0x05, 0x01, 0x0f})
+	f.Add([]byte{0x01, 0x01, 0x00, 0x09, 0x01, 0, 0, 0, 0, 0, 0, 0, 0})
+	f.Add([]byte{0x01, 0x00})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		d, err := NewFromCanonical(data)
+		if err != nil {
+			return
+		}
+		if !bytes.Equal(d.MarshalCanonical(), data) {
+			t.Fatalf("accepted non-canonical encoding %x", data)
+		}
+	})
+}
+
+func FuzzDecimalKeyOrder(f *testing.F) {
+	f.Add([]byte{0x01}, false, int16(0), []byte{0x02}, true, int16(-2))
+	f.Add([]byte{0x0f}, true, int16(120), []byte{0x0f}, true, int16(-120))
+	f.Add([]byte{0x99, 0x99}, false, int16(-200), []byte{0x01, 0x00}, false, int16(300))
+
+	f.Fuzz(func(t *testing.T, mag1 []byte, neg1 bool, exp1 int16, mag2 []byte, neg2 bool, exp2 int16) {
+		a := fuzzDecimal(mag1, neg1, exp1)
+		b := fuzzDecimal(mag2, neg2, exp2)
+		ka, kb := a.MarshalKey(), b.MarshalKey()
+
+		if got, expected := bytes.Compare(ka, kb), a.Cmp(b); got != expected {
+			t.Fatalf("Compare(key(%s), key(%s)) = %d, expected %d", a.String(), b.String(), got, expected)
+		}
+
+		got, rest, err := NewFromKey(append(ka, kb...))
+		if err != nil {
+			t.Fatalf("cannot decode %x: %v", ka, err)
+		}
+		if !got.Equal(a) || !bytes.Equal(rest, kb) {
+			t.Fatalf("Expected %s with %x remaining, got %s with %x", a.String(), kb, got.String(), rest)
+		}
+	})
+}
+
+func FuzzDecimalKeyDecode(f *testing.F) {
+	f.Add([]byte{keyPositive, 0x81, 0x02, 0x00})
+	f.Add([]byte{keyNegative, 0x7e, 0xf0, 0xff})
+	f.Add([]byte{keyZero})
+
+	f.Fuzz(func(t *testing.T, key []byte) {
+		d, rest, err := NewFromKey(key)
+		if err != nil {
+			return
+		}
+		consumed := key[:len(key)-len(rest)]
+		if !bytes.Equal(d.MarshalKey(), consumed) {
+			t.Fatalf("accepted non-canonical key %x", consumed)
+		}
+	})
+}
+
+func BenchmarkDecimalMarshalCanonical(b *testing.B) {
+	d := RequireFromString("-1234567.891")
+	buf := make([]byte, 0, 32)
+	for i := 0; i < b.N; i++ {
+		buf = d.AppendCanonical(buf[:0])
+	}
+}
+
+func BenchmarkDecimalMarshalBinary(b *testing.B) {
+	d := RequireFromString("-1234567.891")
+	for i := 0; i < b.N; i++ {
+		_, _ = d.MarshalBinary()
+	}
+}
+
+func BenchmarkDecimalMarshalKey(b *testing.B) {
+	d := RequireFromString("-1234567.891")
+	buf := make([]byte, 0, 32)
+	for i := 0; i < b.N; i++ {
+		buf = d.AppendKey(buf[:0])
+	}
+}
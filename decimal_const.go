@@ -0,0 +1,228 @@
+package safem
+
+import (
+	"math"
+	"math/big"
+	"sync"
+)
+
+// constGuardDigits is the number of extra digits carried when a constant has to
+// be computed beyond its stored length.
+const constGuardDigits = 10
+
+var (
+	piConst    = newMathConst(pi, computePi)
+	eConst     = newMathConst(euler, computeE)
+	ln2Const   = newMathConst(ln2, computeLn2)
+	sqrt2Const = newMathConst(sqrt2, computeSqrt2)
+)
+
+// Pi returns π rounded to precision digits after the decimal point.
+//
+// The first 3000 digits come from a stored table; higher precisions are computed
+// with the Chudnovsky series using binary splitting. The highest precision computed
+// so far is cached and lower ones are rounded from it. Pi is safe to call
+// concurrently.
+//
+// Example:
+//
+//	Pi(10).String() // output: "3.1415926536"
+func Pi(precision int32) Decimal {
+	return piConst.value(precision)
+}
+
+// E returns Euler's number e rounded to precision digits after the decimal point.
+//
+// The first 3000 digits come from a stored table; higher precisions are computed
+// from the factorial series using binary splitting. The highest precision computed
+// so far is cached and lower ones are rounded from it. E is safe to call
+// concurrently.
+//
+// Example:
+//
+//	E(10).String() // output: "2.7182818285"
+func E(precision int32) Decimal {
+	return eConst.value(precision)
+}
+
+// Ln2 returns the natural logarithm of 2 rounded to precision digits after the
+// decimal point.
+//
+// The first 3000 digits come from a stored table; higher precisions are computed
+// from the series ln(2) = 2 * atanh(1/3). The highest precision computed so far is
+// cached and lower ones are rounded from it. Ln2 is safe to call concurrently.
+//
+// Example:
+//
+//	Ln2(10).String() // output: "0.6931471806"
+func Ln2(precision int32) Decimal {
+	return ln2Const.value(precision)
+}
+
+// Sqrt2 returns the square root of 2 rounded to precision digits after the
+// decimal point.
+//
+// The first 3000 digits come from a stored table; higher precisions are computed
+// with Decimal.Sqrt. The highest precision computed so far is cached and lower ones
+// are rounded from it. Sqrt2 is safe to call concurrently.
+//
+// Example:
+//
+//	Sqrt2(10).String() // output: "1.4142135624"
+func Sqrt2(precision int32) Decimal {
+	return sqrt2Const.value(precision)
+}
+
+// mathConst is a mathematical constant available at any precision.
+type mathConst struct {
+	stored  constApproximation
+	digits  int32
+	compute func(precision int32) Decimal
+
+	// high is the constant computed to highDigits places, constGuardDigits more
+	// than the highest precision requested beyond the stored digits. Caching one
+	// value keeps memory bounded however many precisions are requested.
+	mu         sync.RWMutex
+	high       Decimal
+	highDigits int32
+}
+
+func newMathConst(stored constApproximation, compute func(precision int32) Decimal) *mathConst {
+	return &mathConst{
+		stored:  stored,
+		digits:  -stored.exact.exp,
+		compute: compute,
+	}
+}
+
+// value returns the constant rounded to precision places. The returned decimal
+// never shares memory with the cache, so callers cannot corrupt it.
+func (c *mathConst) value(precision int32) Decimal {
+	if precision < c.digits {
+		// Truncating to one extra digit and rounding half up gives the same result
+		// as rounding the exact value: truncation never crosses a rounding midpoint.
+		return c.stored.exact.Truncate(precision + 1).Round(precision)
+	}
+
+	want := precision + constGuardDigits
+	c.mu.RLock()
+	high, highDigits := c.high, c.highDigits
+	c.mu.RUnlock()
+
+	if highDigits < want {
+		high = c.compute(want)
+		c.mu.Lock()
+		if c.highDigits < want {
+			c.high, c.highDigits = high, want
+		}
+		c.mu.Unlock()
+	}
+
+	// the guard digits keep rounding high twice from differing from rounding the
+	// exact value, as they do inside compute
+	return high.Round(precision).Copy()
+}
+
+// computePi evaluates the Chudnovsky series
+//
+//	1/π = 12 Σ (-1)^k (6k)! (13591409 + 545140134k) / ((3k)! (k!)^3 640320^(3k+3/2))
+//
+// using binary splitting, each term contributing about 14 digits.
+func computePi(precision int32) Decimal {
+	work := int64(precision) + constGuardDigits
+	c3over24 := new(big.Int).Exp(big.NewInt(640320), big.NewInt(3), nil)
+	c3over24.Quo(c3over24, big.NewInt(24))
+
+	var split func(a, b int64) (p, q, t *big.Int)
+	split = func(a, b int64) (p, q, t *big.Int) {
+		if b-a == 1 {
+			if a == 0 {
+				p, q = big.NewInt(1), big.NewInt(1)
+			} else {
+				p = big.NewInt(6*a - 5)
+				p.Mul(p, big.NewInt(2*a-1))
+				p.Mul(p, big.NewInt(6*a-1))
+				q = big.NewInt(a)
+				q.Mul(q, q).Mul(q, big.NewInt(a))
+				q.Mul(q, c3over24)
+			}
+			t = new(big.Int).Mul(p, big.NewInt(13591409+545140134*a))
+			if a&1 == 1 {
+				t.Neg(t)
+			}
+			return p, q, t
+		}
+		m := (a + b) / 2
+		p1, q1, t1 := split(a, m)
+		p2, q2, t2 := split(m, b)
+		t = new(big.Int).Mul(q2, t1)
+		t.Add(t, new(big.Int).Mul(p1, t2))
+		return p1.Mul(p1, p2), q1.Mul(q1, q2), t
+	}
+
+	_, q, t := split(0, work/14+2)
+
+	scale := new(big.Int).Exp(tenInt, big.NewInt(work), nil)
+	sqrtC := new(big.Int).Mul(big.NewInt(10005), scale)
+	sqrtC.Mul(sqrtC, scale).Sqrt(sqrtC)
+
+	v := new(big.Int).Mul(big.NewInt(426880), sqrtC)
+	v.Mul(v, q).Quo(v, t)
+
+	return Decimal{value: v, exp: -int32(work)}.Round(precision)
+}
+
+// computeE evaluates e = Σ 1/k! using binary splitting of the partial sums
+// P(a, b) / Q(a, b) = Σ_{k=a+1}^{b} 1 / ((a+1)(a+2)...k).
+func computeE(precision int32) Decimal {
+	work := int64(precision) + constGuardDigits
+
+	// smallest n with log10(n!) > work
+	n := int64(1)
+	for logFact := 0.0; logFact <= float64(work); n++ {
+		logFact += math.Log10(float64(n + 1))
+	}
+
+	var split func(a, b int64) (p, q *big.Int)
+	split = func(a, b int64) (p, q *big.Int) {
+		if b-a == 1 {
+			return big.NewInt(1), big.NewInt(b)
+		}
+		m := (a + b) / 2
+		p1, q1 := split(a, m)
+		p2, q2 := split(m, b)
+		p1.Mul(p1, q2).Add(p1, p2)
+		return p1, q1.Mul(q1, q2)
+	}
+
+	p, q := split(0, n+1)
+
+	v := new(big.Int).Exp(tenInt, big.NewInt(work), nil)
+	v.Mul(v, p.Add(p, q)).Quo(v, q)
+
+	return Decimal{value: v, exp: -int32(work)}.Round(precision)
+}
+
+// computeLn2 evaluates ln(2) = 2 * atanh(1/3) = Σ 2 / ((2k+1) 3^(2k+1)) in fixed point.
+func computeLn2(precision int32) Decimal {
+	work := int64(precision) + constGuardDigits
+
+	term := new(big.Int).Exp(tenInt, big.NewInt(work), nil)
+	term.Lsh(term, 1).Quo(term, big.NewInt(3))
+
+	sum := new(big.Int)
+	nine := big.NewInt(9)
+	tmp := new(big.Int)
+	for k := int64(0); term.Sign() > 0; k++ {
+		sum.Add(sum, tmp.Quo(term, big.NewInt(2*k+1)))
+		term.Quo(term, nine)
+	}
+
+	return Decimal{value: sum, exp: -int32(work)}.Round(precision)
+}
+
+func computeSqrt2(precision int32) Decimal {
+	// Sqrt is already correctly rounded, so no guard digits are needed
+	r, _ := New(2, 0).Sqrt(precision)
+	return r
+}
@@ -0,0 +1,113 @@
+package safem
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestMathConstants(t *testing.T) {
+	tests := []struct {
+		name      string
+		fn        func(int32) Decimal
+		precision int32
+		expected  string
+	}{
+		{"pi", Pi, 10, "3.1415926536"},
+		{"pi zero places", Pi, 0, "3"},
+		{"pi negative places", Pi, -1, "0"},
+		{"e", E, 10, "2.7182818285"},
+		{"e 30 places", E, 30, "2.718281828459045235360287471353"},
+		{"ln2", Ln2, 10, "0.6931471806"},
+		{"ln2 30 places", Ln2, 30, "0.693147180559945309417232121458"},
+		{"sqrt2", Sqrt2, 10, "1.4142135624"},
+		{"sqrt2 30 places", Sqrt2, 30, "1.414213562373095048801688724210"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.fn(tt.precision)
+			if !result.Equal(RequireFromString(tt.expected)) {
+				t.Errorf("Expected %s, got %s", tt.expected, result.String())
+			}
+		})
+	}
+}
+
+func TestMathConstantsComputedMatchStored(t *testing.T) {
+	tests := []struct {
+		name    string
+		compute func(int32) Decimal
+		stored  constApproximation
+	}{
+		{"pi", computePi, pi},
+		{"e", computeE, euler},
+		{"ln2", computeLn2, ln2},
+		{"sqrt2", computeSqrt2, sqrt2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, precision := range []int32{1, 17, 250, 2990} {
+				expected := tt.stored.exact.Truncate(precision + 1).Round(precision)
+				if result := tt.compute(precision); !result.Equal(expected) {
+					t.Fatalf("precision %d: computed value differs from stored digits", precision)
+				}
+			}
+		})
+	}
+}
+
+func TestMathConstantsBeyondStoredDigits(t *testing.T) {
+	long := Pi(3100)
+	if got := long.Truncate(2999); !got.Equal(pi.exact.Truncate(2999)) {
+		t.Errorf("Pi(3100) does not extend the stored digits")
+	}
+	if got := long.Exponent(); got != -3100 {
+		t.Errorf("Expected exponent -3100, got %d", got)
+	}
+
+	// lower precisions are rounded from the one cached value
+	c := newMathConst(euler, computeE)
+	for _, p := range []int32{3050, 3020, 3100, 3001} {
+		if got := c.value(p); !got.Equal(computeE(p)) {
+			t.Errorf("E(%d) from the cache differs from the computed value", p)
+		}
+	}
+	if c.highDigits != 3100+constGuardDigits {
+		t.Errorf("Expected one cached value of %d digits, got %d", 3100+constGuardDigits, c.highDigits)
+	}
+
+	mutated := c.value(3050)
+	mutated.value.SetInt64(0)
+	if got := c.value(3050); !got.Equal(computeE(3050)) {
+		t.Errorf("cached value was mutated through a returned decimal")
+	}
+}
+
+func TestMathConstantsCacheIsolation(t *testing.T) {
+	// RoundBank may mutate its receiver's coefficient when no rounding is needed
+	first := Pi(8)
+	first.RoundBank(8)
+	first.value.SetInt64(0)
+
+	if second := Pi(8); !second.Equal(RequireFromString("3.14159265")) {
+		t.Errorf("cached value was mutated through a returned decimal: %s", second.String())
+	}
+}
+
+func TestMathConstantsConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for p := int32(0); p < 40; p++ {
+				Pi(p + int32(i))
+				E(p)
+				Ln2(p)
+				Sqrt2(3001 + p%2)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,136 @@
+package safem
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// DefaultExpMaxIntegerDigits is the limit on the number of integer digits of an
+// Exp result used when ExpContext.MaxIntegerDigits is zero. It accepts arguments
+// up to about 23025.
+const DefaultExpMaxIntegerDigits = 10000
+
+// expMaxRefinements bounds the number of times Exp widens its guard digits while
+// trying to round correctly.
+const expMaxRefinements = 8
+
+// ExpContext configures the natural exponent calculated by Decimal.Exp.
+type ExpContext struct {
+	// Precision is the number of digits after the decimal point of the result.
+	// Negative precision is allowed.
+	Precision int32
+
+	// MaxIntegerDigits rejects arguments whose exponent would have more integer
+	// digits than this. Zero selects DefaultExpMaxIntegerDigits.
+	MaxIntegerDigits int32
+}
+
+// Exp calculates the natural exponent of decimal (e to the power of d), correctly
+// rounded (half away from zero) to ctx.Precision digits after the decimal point.
+//
+// Unlike ExpTaylor and ExpHullAbrham, Exp reduces the argument to x = k*ln(2) + r
+// with |r| <= ln(2)/2, halves r further before summing the series, and chooses the
+// number of terms from the requested precision. The result is recomputed with more
+// guard digits whenever the error bound straddles a rounding boundary.
+//
+// Exp returns error when the result would need more than ctx.MaxIntegerDigits
+// integer digits. Results too small to show at the requested precision are 0.
+//
+// Example:
+//
+//	d1, err := NewFromFloat(26.1).Exp(ExpContext{Precision: 20})
+//	d1.String()  // output: "216314672147.05767284062928674083"
+//
+//	d2, err := NewFromInt(-1).Exp(ExpContext{Precision: 10})
+//	d2.String()  // output: "0.3678794412"
+func (d Decimal) Exp(ctx ExpContext) (Decimal, error) {
+	precision := ctx.Precision
+	maxDigits := ctx.MaxIntegerDigits
+	if maxDigits <= 0 {
+		maxDigits = DefaultExpMaxIntegerDigits
+	}
+
+	if d.IsZero() {
+		return New(1, 0).Round(precision), nil
+	}
+
+	// magnitude = log10(e^d), the position of the leading digit of the result
+	magnitude := d.InexactFloat64() * math.Log10E
+	if magnitude > float64(maxDigits) {
+		return Decimal{}, fmt.Errorf("exp(x) exceeds %d integer digits, cannot be calculated", maxDigits)
+	}
+	if magnitude < -float64(precision)-2 {
+		// e^d < 10^(-precision-1) rounds to zero
+		return New(0, 0).Round(precision), nil
+	}
+
+	guard := int32(4)
+	for i := 0; i < expMaxRefinements; i++ {
+		v, bound := d.expApprox(precision+guard, magnitude)
+		lo := v.Sub(bound).Round(precision)
+		hi := v.Add(bound).Round(precision)
+		if lo.Equal(hi) {
+			return hi, nil
+		}
+		guard *= 2
+	}
+
+	return Decimal{}, fmt.Errorf("exp(x) cannot be rounded correctly to %d places", precision)
+}
+
+// expApprox returns an approximation of e^d with at least places digits after the
+// decimal point, together with a bound on its absolute error.
+func (d Decimal) expApprox(places int32, magnitude float64) (value Decimal, bound Decimal) {
+	// significant digits needed so the absolute error stays below 10^(-places)
+	sig := places + int32(math.Ceil(magnitude)) + 3
+	if sig < 3 {
+		sig = 3
+	}
+
+	// x = k*ln(2) + r; the error of ln(2) is scaled by k, so carry its digits too
+	k := d.DivRound(ln2.withPrecision(20), 0)
+	kDigits := int32(k.NumDigits())
+	r := d.Sub(k.Mul(Ln2(sig + kDigits + 4)))
+
+	// e^r = (e^(r / 2^s))^(2^s); each squaring can triple the error, which costs
+	// about half a digit per halving
+	s := uint(math.Sqrt(float64(sig)) / 2)
+	work := sig + int32(s)/2 + 4
+	unit := new(big.Int).Exp(tenInt, big.NewInt(int64(work)), nil)
+
+	x := r.rescale(-work).value
+	x.Rsh(x, s)
+
+	sum := new(big.Int).Set(unit)
+	term := new(big.Int).Set(unit)
+	terms := int64(0)
+	for i := int64(1); term.Sign() != 0; i++ {
+		term.Mul(term, x).Quo(term, unit)
+		term.Quo(term, big.NewInt(i))
+		sum.Add(sum, term)
+		terms++
+	}
+
+	for i := uint(0); i < s; i++ {
+		sum.Mul(sum, sum).Quo(sum, unit)
+	}
+
+	// truncation error of the series and of every squaring, plus the error of r
+	ulps := new(big.Int).Exp(big.NewInt(3), big.NewInt(int64(s)), nil)
+	ulps.Mul(ulps, big.NewInt(terms+6))
+	ulps.Add(ulps, new(big.Int).Exp(tenInt, big.NewInt(int64(work-sig)), nil))
+
+	// multiply by 2^k exactly, using 2^(-n) = 5^n * 10^(-n) for negative k
+	n := k.IntPart()
+	var factor Decimal
+	if n >= 0 {
+		factor = Decimal{new(big.Int).Lsh(oneInt, uint(n)), 0}
+	} else {
+		factor = Decimal{new(big.Int).Exp(fiveInt, big.NewInt(-n), nil), int32(n)}
+	}
+
+	value = Decimal{sum, -work}.Mul(factor)
+	bound = Decimal{ulps, -work}.Mul(factor)
+	return value, bound
+}
@@ -0,0 +1,107 @@
+package safem
+
+import (
+	"testing"
+)
+
+func TestDecimalExp(t *testing.T) {
+	// Reference values computed at 2000 significant digits and rounded half up
+	tests := []struct {
+		input     string
+		precision int32
+		expected  string
+	}{
+		{"0", 10, "1"},
+		{"1", 50, "2.71828182845904523536028747135266249775724709369996"},
+		{"-1", 50, "0.36787944117144232159552377016146086744581113103177"},
+		{"0.5", 40, "1.6487212707001281468486507878141635716538"},
+		{"26.1", 20, "216314672147.05767284062928674083"},
+		{"-26.1", 30, "4.622894924668668941E-12"},
+		{"100", 30, "26881171418161354484126255515800135873611118.773741922415191608615280287035"},
+		{"1e-30", 60, "1.000000000000000000000000000001000000000000000000000000000001"},
+		{"-1000", 450, "5.075958897549457E-435"},
+		{"0.693147180559945309417232121458", 40, "1.9999999999999999999999999999996468638490"},
+		{"-0.000001", 25, "0.9999990000004999998333334"},
+		{"12.3456789", -3, "2.30E+5"},
+		{"-1000", 10, "0"},
+		{"-1e9", 10, "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := RequireFromString(tt.input).Exp(ExpContext{Precision: tt.precision})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !result.Equal(RequireFromString(tt.expected)) {
+				t.Errorf("Expected %s, got %s", tt.expected, result.String())
+			}
+		})
+	}
+}
+
+func TestDecimalExpLimits(t *testing.T) {
+	if _, err := NewFromInt(1e9).Exp(ExpContext{Precision: 2}); err == nil {
+		t.Errorf("Expected error for huge argument")
+	}
+	if _, err := NewFromInt(100).Exp(ExpContext{Precision: 2, MaxIntegerDigits: 40}); err == nil {
+		t.Errorf("Expected error when result exceeds MaxIntegerDigits")
+	}
+	if _, err := NewFromInt(100).Exp(ExpContext{Precision: 2, MaxIntegerDigits: 50}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestDecimalExpMatchesExpTaylor(t *testing.T) {
+	for _, s := range []string{"0.1", "1.5", "-2.75", "7", "-0.333"} {
+		d := RequireFromString(s)
+		expected, err := d.ExpTaylor(30)
+		if err != nil {
+			t.Fatalf("ExpTaylor(%s): %v", s, err)
+		}
+		result, err := d.Exp(ExpContext{Precision: 30})
+		if err != nil {
+			t.Fatalf("Exp(%s): %v", s, err)
+		}
+		// ExpTaylor is not always correctly rounded, allow one unit in the last place
+		if result.Sub(expected).Abs().GreaterThan(New(1, -30)) {
+			t.Errorf("Exp(%s) = %s, ExpTaylor = %s", s, result, expected)
+		}
+	}
+}
+
+func BenchmarkDecimalExp(b *testing.B) {
+	d := NewFromFloat(26.1)
+	for i := 0; i < b.N; i++ {
+		d.Exp(ExpContext{Precision: 20})
+	}
+}
+
+func BenchmarkDecimalExpTaylor(b *testing.B) {
+	d := NewFromFloat(26.1)
+	for i := 0; i < b.N; i++ {
+		d.ExpTaylor(20)
+	}
+}
+
+func BenchmarkDecimalExpHullAbrham(b *testing.B) {
+	// 12 integer digits + 20 decimal places
+	d := NewFromFloat(26.1)
+	for i := 0; i < b.N; i++ {
+		d.ExpHullAbrham(32)
+	}
+}
+
+func BenchmarkDecimalExpLargeArgument(b *testing.B) {
+	d := NewFromInt(1000)
+	for i := 0; i < b.N; i++ {
+		d.Exp(ExpContext{Precision: 20})
+	}
+}
+
+func BenchmarkDecimalExpTaylorLargeArgument(b *testing.B) {
+	d := NewFromInt(1000)
+	for i := 0; i < b.N; i++ {
+		d.ExpTaylor(20)
+	}
+}
@@ -0,0 +1,198 @@
+package safem
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Format implements the fmt.Formatter interface, so decimals print exactly with
+// printf-style verbs instead of going through InexactFloat64.
+//
+// Supported verbs:
+//
+//	%v, %s  String(); with a precision, StringFixed(precision)
+//	%q      like %s, double-quoted
+//	%f, %F  every digit of the decimal, no exponent; with a precision, StringFixed(precision)
+//	%e, %E  scientific notation, e.g. -1.2345e+03; the precision counts digits after the point,
+//	        and without one every digit is printed rather than six
+//	%g, %G  %e for large exponents, %f otherwise; the precision counts significant digits
+//
+// Width and the '+', ' ', '0' and '-' flags behave as they do for float64. The '#'
+// flag keeps trailing zeros for %g. Rounding is half away from zero, as in Round.
+//
+// Example:
+//
+//	d := RequireFromString("-1234.5678")
+//	fmt.Sprintf("%.2f", d)   // output: "-1234.57"
+//	fmt.Sprintf("%+12.3e", d) // output: "  -1.235e+03"
+//	fmt.Sprintf("%010.1f", d) // output: "-0001234.6"
+func (d Decimal) Format(s fmt.State, verb rune) {
+	d.ensureInitialized()
+	prec, hasPrec := s.Precision()
+
+	var body string
+	switch verb {
+	case 'v', 's', 'q', 'f', 'F':
+		if hasPrec {
+			body = d.StringFixed(int32(prec))
+		} else {
+			body = d.String()
+		}
+	case 'e', 'E':
+		if !hasPrec {
+			prec = -1
+		}
+		body = d.formatExp(prec, verb == 'E')
+	case 'g', 'G':
+		if !hasPrec {
+			prec = -1
+		}
+		body = d.formatGeneral(prec, verb == 'G', s.Flag('#'))
+	default:
+		fmt.Fprintf(s, "%%!%c(safem.Decimal=%s)", verb, d.String())
+		return
+	}
+
+	if verb == 'q' {
+		writePadded(s, "", strconv.Quote(body), false)
+		return
+	}
+
+	sign := ""
+	if strings.HasPrefix(body, "-") {
+		sign, body = "-", body[1:]
+	} else if s.Flag('+') && verb != 'v' {
+		// %+v only asks for field names, as with float64
+		sign = "+"
+	} else if s.Flag(' ') {
+		sign = " "
+	}
+	writePadded(s, sign, body, true)
+}
+
+// formatExp returns d in scientific notation with prec digits after the point,
+// or with every significant digit when prec < 0.
+func (d Decimal) formatExp(prec int, upper bool) string {
+	digits, exp := d.sigDigits(prec + 1)
+
+	mant := digits[:1]
+	if len(digits) > 1 {
+		mant += "." + digits[1:]
+	}
+
+	e := byte('e')
+	if upper {
+		e = 'E'
+	}
+	expSign := byte('+')
+	if exp < 0 {
+		expSign, exp = '-', -exp
+	}
+	expStr := strconv.Itoa(exp)
+	if len(expStr) < 2 {
+		expStr = "0" + expStr
+	}
+
+	res := mant + string(e) + string(expSign) + expStr
+	if d.IsNegative() {
+		return "-" + res
+	}
+	return res
+}
+
+// formatGeneral mirrors strconv's %g: scientific notation when the exponent is
+// below -4 or at least the precision (6 when printing every digit), fixed otherwise.
+func (d Decimal) formatGeneral(prec int, upper, keepZeros bool) string {
+	if prec == 0 {
+		prec = 1
+	}
+
+	digits, exp := d.sigDigits(prec)
+	eprec := prec
+	if prec < 0 {
+		eprec = 6
+	}
+
+	var res string
+	if exp < -4 || exp >= eprec {
+		res = d.formatExp(len(digits)-1, upper)
+		if !keepZeros {
+			res = trimMantissaZeros(res)
+		}
+		return res
+	}
+
+	places := len(digits) - 1 - exp
+	if places < 0 {
+		places = 0
+	}
+	res = d.StringFixed(int32(places))
+	if !keepZeros && strings.Contains(res, ".") {
+		res = strings.TrimSuffix(strings.TrimRight(res, "0"), ".")
+	}
+	return res
+}
+
+// sigDigits returns the absolute value of d rounded to n significant digits, as
+// a digit string without leading zeros, and the decimal exponent of its first
+// digit. When n < 1 every significant digit is returned, without trailing zeros.
+func (d Decimal) sigDigits(n int) (string, int) {
+	if d.IsZero() {
+		if n < 1 {
+			n = 1
+		}
+		return strings.Repeat("0", n), 0
+	}
+
+	abs := d.Abs()
+	if n < 1 {
+		digits := strings.TrimRight(abs.value.String(), "0")
+		exp := abs.NumDigits() - 1 + int(abs.exp)
+		return digits, exp
+	}
+
+	exp := abs.NumDigits() - 1 + int(abs.exp)
+	r := abs.Round(int32(n - 1 - exp))
+	if newExp := r.NumDigits() - 1 + int(r.exp); newExp != exp {
+		// rounding carried into a new leading digit, e.g. 9.99 -> 10.0
+		exp = newExp
+		r = abs.Round(int32(n - 1 - exp))
+	}
+	return r.value.String(), exp
+}
+
+// trimMantissaZeros removes trailing zeros, and a trailing point, from the
+// mantissa of a number in scientific notation.
+func trimMantissaZeros(s string) string {
+	i := strings.IndexAny(s, "eE")
+	mant, exp := s[:i], s[i:]
+	if strings.Contains(mant, ".") {
+		mant = strings.TrimRight(mant, "0")
+		mant = strings.TrimSuffix(mant, ".")
+	}
+	return mant + exp
+}
+
+// writePadded writes sign and body padded to the state's width. Zero padding is
+// inserted between the sign and the digits when requested and allowed.
+func writePadded(s fmt.State, sign, body string, numeric bool) {
+	width, hasWidth := s.Width()
+	pad := 0
+	if hasWidth {
+		pad = width - len([]rune(sign+body))
+	}
+	if pad <= 0 {
+		fmt.Fprint(s, sign+body)
+		return
+	}
+
+	switch {
+	case s.Flag('-'):
+		fmt.Fprint(s, sign+body+strings.Repeat(" ", pad))
+	case s.Flag('0') && numeric:
+		fmt.Fprint(s, sign+strings.Repeat("0", pad)+body)
+	default:
+		fmt.Fprint(s, strings.Repeat(" ", pad)+sign+body)
+	}
+}
@@ -0,0 +1,102 @@
+package safem
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestDecimalFormatMatchesFloat64(t *testing.T) {
+	// These values are exact in binary and never fall on a rounding midpoint, so
+	// float64 formatting is a valid reference. Without a precision %e differs by
+	// design: it prints every digit instead of six.
+	values := []string{"0", "1", "-1", "1234.59375", "-1234.59375", "0.15234375", "-3.0390625", "123456789", "1e-7", "5e20", "99.9375"}
+	formats := []string{
+		"%.3e", "%.3E", "%.0e", "%+.2e", "%12.3e", "%-12.3e|", "%012.3e",
+		"%g", "%.3g", "%.10g", "%G", "%#.6g", "%+g", "% g",
+		"%.2f", "%.0f", "%+.3f", "% .1f", "%10.2f", "%-10.2f|", "%010.2f",
+	}
+
+	for _, v := range values {
+		d := RequireFromString(v)
+		f := d.InexactFloat64()
+		for _, format := range formats {
+			expected := fmt.Sprintf(format, f)
+			if result := fmt.Sprintf(format, d); result != expected {
+				t.Errorf("Sprintf(%q, %s): expected %q, got %q", format, v, expected, result)
+			}
+		}
+	}
+}
+
+func TestDecimalFormat(t *testing.T) {
+	tests := []struct {
+		format   string
+		input    string
+		expected string
+	}{
+		{"%v", "-1234.5678", "-1234.5678"},
+		{"%s", "1.50", "1.5"},
+		{"%f", "123456789012345678901234567890.000000000000000000000001", "123456789012345678901234567890.000000000000000000000001"},
+		{"%.2f", "-1234.5678", "-1234.57"},
+		{"%.2f", "0.005", "0.01"},
+		{"%.2v", "1.005", "1.01"},
+		{"%q", "1.5", `"1.5"`},
+		{"%8q", "1.5", `   "1.5"`},
+		{"%e", "123456789012345678901234567890", "1.2345678901234567890123456789e+29"},
+		{"%.2e", "9.999", "1.00e+01"},
+		{"%.3g", "99999", "1e+05"},
+		{"%g", "0.00001234", "1.234e-05"},
+		{"%e", "1234.5", "1.2345e+03"},
+		{"%E", "0.125", "1.25E-01"},
+		{"%.0f", "-0.4", "0"},
+		{"%+v", "2", "2"},
+		{"%+s", "2", "+2"},
+		{"%x", "2", "%!x(safem.Decimal=2)"},
+		{"%v", "", "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format+" "+tt.input, func(t *testing.T) {
+			var d Decimal
+			if tt.input != "" {
+				d = RequireFromString(tt.input)
+			}
+			if result := fmt.Sprintf(tt.format, d); result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestBigIntFormat(t *testing.T) {
+	large, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	tests := []struct {
+		format   string
+		input    BigInt
+		expected string
+	}{
+		{"%v", BigInt{large}, "123456789012345678901234567890"},
+		{"%d", BigInt{big.NewInt(-42)}, "-42"},
+		{"%08d", BigInt{big.NewInt(-42)}, "-0000042"},
+		{"%x", BigInt{big.NewInt(255)}, "ff"},
+		{"%#x", BigInt{big.NewInt(255)}, "0xff"},
+		{"%.3e", BigInt{large}, "1.235e+29"},
+		{"%.2f", BigInt{big.NewInt(7)}, "7.00"},
+		{"%+10s", BigInt{big.NewInt(7)}, "        +7"},
+		{"%q", BigInt{big.NewInt(7)}, `"7"`},
+		{"%v", BigInt{}, "<nil>"},
+		{"%6v", BigInt{}, " <nil>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format+" "+tt.expected, func(t *testing.T) {
+			if result := fmt.Sprintf(tt.format, tt.input); result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+			if result := fmt.Sprintf(tt.format, &tt.input); result != tt.expected {
+				t.Errorf("Expected %q through pointer, got %q", tt.expected, result)
+			}
+		})
+	}
+}
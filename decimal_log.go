@@ -0,0 +1,197 @@
+package safem
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// Log10 calculates the base-10 logarithm of d.
+// Precision argument specifies how precise the result must be (number of digits after decimal point).
+// Negative precision is allowed.
+//
+// Exact powers of ten produce exact results.
+//
+// Example:
+//
+//	d1, err := NewFromInt(1000).Log10(8)
+//	d1.String()  // output: "3"
+//
+//	d2, err := NewFromFloat(2).Log10(10)
+//	d2.String()  // output: "0.3010299957"
+func (d Decimal) Log10(precision int32) (Decimal, error) {
+	if err := d.checkLogDomain("base-10"); err != nil {
+		return Decimal{}, err
+	}
+
+	// d = c * 10^exp where c is 1 after stripping trailing zeros => log10(d) is an integer
+	c := new(big.Int).Set(d.value)
+	k := int64(d.exp)
+	r := new(big.Int)
+	for {
+		q, m := new(big.Int).QuoRem(c, tenInt, r)
+		if m.Sign() != 0 {
+			break
+		}
+		c = q
+		k++
+	}
+	if c.Cmp(oneInt) == 0 {
+		return NewFromInt(k).Round(precision), nil
+	}
+
+	return d.logConst(ln10, precision)
+}
+
+// Log2 calculates the base-2 logarithm of d.
+// Precision argument specifies how precise the result must be (number of digits after decimal point).
+// Negative precision is allowed.
+//
+// Exact powers of two, including negative powers such as 0.125, produce exact results.
+//
+// Example:
+//
+//	d1, err := NewFromInt(1024).Log2(8)
+//	d1.String()  // output: "10"
+//
+//	d2, err := NewFromInt(10).Log2(10)
+//	d2.String()  // output: "3.3219280949"
+func (d Decimal) Log2(precision int32) (Decimal, error) {
+	if err := d.checkLogDomain("base-2"); err != nil {
+		return Decimal{}, err
+	}
+
+	rat := d.Rat()
+	num, denom := rat.Num(), rat.Denom()
+	if isPowerOfTwo(num) && denom.Cmp(oneInt) == 0 {
+		return NewFromInt(int64(num.BitLen() - 1)).Round(precision), nil
+	}
+	if num.Cmp(oneInt) == 0 && isPowerOfTwo(denom) {
+		return NewFromInt(-int64(denom.BitLen() - 1)).Round(precision), nil
+	}
+
+	return d.logConst(ln2, precision)
+}
+
+// Log calculates the logarithm of d to the given base.
+// Precision argument specifies how precise the result must be (number of digits after decimal point).
+// Negative precision is allowed.
+//
+// When d is an exact integer power of base the exact exponent is returned.
+//
+// Log returns error when:
+//   - d <= 0 => undefined value
+//   - base <= 0 or base == 1 => undefined value
+//
+// Example:
+//
+//	d1, err := NewFromInt(3125).Log(NewFromInt(5), 4)
+//	d1.String()  // output: "5"
+//
+//	d2, err := NewFromFloat(0.001).Log(NewFromFloat(0.1), 4)
+//	d2.String()  // output: "3"
+//
+//	d3, err := NewFromInt(100).Log(NewFromInt(3), 10)
+//	d3.String()  // output: "4.1918065486"
+func (d Decimal) Log(base Decimal, precision int32) (Decimal, error) {
+	if err := d.checkLogDomain("arbitrary base"); err != nil {
+		return Decimal{}, err
+	}
+	if base.Sign() <= 0 {
+		return Decimal{}, fmt.Errorf("cannot calculate logarithm for non-positive base")
+	}
+	if base.Equal(New(1, 0)) {
+		return Decimal{}, fmt.Errorf("cannot calculate logarithm for base 1")
+	}
+
+	work := precision + logGuardDigits(d)
+
+	lnB, err := base.Ln(work)
+	if err != nil {
+		return Decimal{}, err
+	}
+
+	// base close to 1 makes ln(base) small, which magnifies the error of the quotient
+	if m := lnB.Abs(); m.LessThan(New(1, 0)) {
+		leadingZeros := -(m.exp + int32(m.NumDigits()))
+		work += 2 * (leadingZeros + 2)
+		if lnB, err = base.Ln(work); err != nil {
+			return Decimal{}, err
+		}
+		if lnB.IsZero() {
+			return Decimal{}, fmt.Errorf("cannot calculate logarithm for base too close to 1")
+		}
+	}
+
+	lnD, err := d.Ln(work)
+	if err != nil {
+		return Decimal{}, err
+	}
+
+	res := lnD.DivRound(lnB, work)
+	if k, ok := exactLogExponent(d, base, res); ok {
+		return NewFromInt(k).Round(precision), nil
+	}
+
+	return res.Round(precision), nil
+}
+
+// logConst returns ln(d) / ln(b), where lnB holds a cached approximation of ln(b) > 0.5.
+func (d Decimal) logConst(lnB constApproximation, precision int32) (Decimal, error) {
+	work := precision + logGuardDigits(d)
+
+	lnD, err := d.Ln(work)
+	if err != nil {
+		return Decimal{}, err
+	}
+
+	return lnD.DivRound(lnB.withPrecision(work+2), precision), nil
+}
+
+func (d Decimal) checkLogDomain(kind string) error {
+	if d.IsNegative() {
+		return fmt.Errorf("cannot calculate %s logarithm for negative decimals", kind)
+	}
+	if d.IsZero() {
+		return fmt.Errorf("cannot represent %s logarithm of 0, result: -infinity", kind)
+	}
+	return nil
+}
+
+// logGuardDigits returns the number of extra digits needed so that the error of
+// ln(d), which grows with the magnitude of ln(d), does not leak into the result.
+func logGuardDigits(d Decimal) int32 {
+	// |ln(d)| <= 2.31 * (|adjusted exponent| + 1)
+	adjusted := math.Abs(float64(d.exp) + float64(d.NumDigits()))
+	return int32(math.Log10(2.31*(adjusted+1))) + 4
+}
+
+// exactLogExponent reports whether d == base^k for the integer k nearest to approx.
+func exactLogExponent(d, base, approx Decimal) (int64, bool) {
+	rounded := approx.Round(0)
+	if !approx.Sub(rounded).Abs().LessThan(New(1, -6)) || !rounded.value.IsInt64() {
+		return 0, false
+	}
+	k := rounded.IntPart()
+
+	// bound the work: base^k cannot equal d if it needs many more bits than d has
+	dr, br := d.Rat(), base.Rat()
+	bits := int64(dr.Num().BitLen() + dr.Denom().BitLen())
+	if k == 0 || (k > 0 && k > 4*bits) || (k < 0 && -k > 4*bits) {
+		return k, k == 0 && dr.Cmp(big.NewRat(1, 1)) == 0
+	}
+
+	e := big.NewInt(k)
+	if k < 0 {
+		e.Neg(e)
+		br.Inv(br)
+	}
+	num := new(big.Int).Exp(br.Num(), e, nil)
+	denom := new(big.Int).Exp(br.Denom(), e, nil)
+
+	return k, new(big.Rat).SetFrac(num, denom).Cmp(dr) == 0
+}
+
+func isPowerOfTwo(x *big.Int) bool {
+	return x.Sign() > 0 && x.TrailingZeroBits() == uint(x.BitLen()-1)
+}
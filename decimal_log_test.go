@@ -0,0 +1,117 @@
+package safem
+
+import (
+	"testing"
+)
+
+func TestDecimalLog10(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		precision int32
+		expected  string
+		hasError  bool
+	}{
+		{"exact power", "1000", 8, "3", false},
+		{"exact negative power", "0.0001", 8, "-4", false},
+		{"one", "1", 8, "0", false},
+		{"trailing zeros", "100.000", 4, "2", false},
+		{"two", "2", 10, "0.3010299957", false},
+		{"two high precision", "2", 40, "0.3010299956639811952137388947244930267682", false},
+		{"fraction", "123456.789", 20, "5.09151497716927044752", false},
+		{"zero", "0", 4, "", true},
+		{"negative", "-10", 4, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := RequireFromString(tt.input).Log10(tt.precision)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !result.Equal(RequireFromString(tt.expected)) {
+				t.Errorf("Expected %s, got %s", tt.expected, result.String())
+			}
+		})
+	}
+}
+
+func TestDecimalLog2(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		precision int32
+		expected  string
+		hasError  bool
+	}{
+		{"exact power", "1024", 8, "10", false},
+		{"exact negative power", "0.125", 8, "-3", false},
+		{"ten", "10", 10, "3.3219280949", false},
+		{"ten high precision", "10", 40, "3.3219280948873623478703194294893901758648", false},
+		{"small fraction", "0.0003", 12, "-11.702749878828", false},
+		{"zero", "0", 4, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := RequireFromString(tt.input).Log2(tt.precision)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !result.Equal(RequireFromString(tt.expected)) {
+				t.Errorf("Expected %s, got %s", tt.expected, result.String())
+			}
+		})
+	}
+}
+
+func TestDecimalLog(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		base      string
+		precision int32
+		expected  string
+		hasError  bool
+	}{
+		{"exact power", "3125", "5", 4, "5", false},
+		{"exact fractional base", "0.001", "0.1", 4, "3", false},
+		{"exact negative exponent", "0.04", "5", 4, "-2", false},
+		{"inexact", "100", "3", 10, "4.1918065486", false},
+		{"base close to one", "7", "1.0001", 6, "19460.074429", false},
+		{"tiny input", "1e-40", "7", 12, "-47.331786498198", false},
+		{"base one", "10", "1", 4, "", true},
+		{"negative base", "10", "-2", 4, "", true},
+		{"zero input", "0", "2", 4, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := RequireFromString(tt.input).Log(RequireFromString(tt.base), tt.precision)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !result.Equal(RequireFromString(tt.expected)) {
+				t.Errorf("Expected %s, got %s", tt.expected, result.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package safem
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestDecimalSqrt(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		precision int32
+		expected  string
+		hasError  bool
+	}{
+		{"zero", "0", 4, "0", false},
+		{"perfect square", "144", 0, "12", false},
+		{"two", "2", 10, "1.4142135624", false},
+		{"two high precision", "2", 50, "1.41421356237309504880168872420969807856967187537695", false},
+		{"fraction", "0.0625", 4, "0.25", false},
+		{"small fraction", "0.000002", 8, "0.00141421", false},
+		{"rounds half up", "0.25", 0, "1", false},
+		{"negative precision", "123456789", -2, "11100", false},
+		{"uniswap k", "1000000000000000000000000000000000000", 0, "1000000000000000000", false},
+		{"negative", "-4", 2, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := RequireFromString(tt.input).Sqrt(tt.precision)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !result.Equal(RequireFromString(tt.expected)) {
+				t.Errorf("Expected %s, got %s", tt.expected, result.String())
+			}
+		})
+	}
+}
+
+func TestDecimalCbrtAndRoot(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		n         int
+		precision int32
+		expected  string
+		hasError  bool
+	}{
+		{"cbrt perfect cube", "27", 3, 4, "3", false},
+		{"cbrt negative", "-27", 3, 4, "-3", false},
+		{"cbrt two", "2", 3, 10, "1.2599210499", false},
+		{"tenth root", "1024", 10, 2, "2", false},
+		{"seventh root", "5", 7, 12, "1.258498950642", false},
+		{"large fourth root", "10000000000000000000000000000000000000000", 4, 0, "10000000000", false},
+		{"first root rounds", "1.2345", 1, 2, "1.23", false},
+		{"negative even root", "-16", 4, 2, "", true},
+		{"zero index", "16", 0, 2, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := RequireFromString(tt.input)
+			var result Decimal
+			var err error
+			if tt.n == 3 {
+				result, err = d.Cbrt(tt.precision)
+			} else {
+				result, err = d.Root(tt.n, tt.precision)
+			}
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !result.Equal(RequireFromString(tt.expected)) {
+				t.Errorf("Expected %s, got %s", tt.expected, result.String())
+			}
+		})
+	}
+}
+
+func TestDecimalRootIsCorrectlyRounded(t *testing.T) {
+	// For every input, the rounded root r must satisfy (r-0.5ulp)^n <= d < (r+0.5ulp)^n
+	half := New(5, -7)
+	for _, s := range []string{"0.5", "3", "7.77", "99", "12345.6789", "0.000123"} {
+		d := RequireFromString(s)
+		for _, n := range []int{2, 3, 5} {
+			r, err := d.Root(n, 6)
+			if err != nil {
+				t.Fatalf("Root(%s, %d): %v", s, n, err)
+			}
+			lo, _ := r.Sub(half).PowInt32(int32(n))
+			hi, _ := r.Add(half).PowInt32(int32(n))
+			if d.LessThan(lo) || !d.LessThan(hi) {
+				t.Errorf("Root(%s, %d) = %s is not correctly rounded", s, n, r)
+			}
+		}
+	}
+}
+
+func TestBigIntSqrt(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    *big.Int
+		expected *big.Int
+	}{
+		{"nil input", nil, big.NewInt(0)},
+		{"negative input", big.NewInt(-9), big.NewInt(0)},
+		{"zero", big.NewInt(0), big.NewInt(0)},
+		{"one", big.NewInt(1), big.NewInt(1)},
+		{"three", big.NewInt(3), big.NewInt(1)},
+		{"four", big.NewInt(4), big.NewInt(2)},
+		{"not a square", big.NewInt(99), big.NewInt(9)},
+		{"max uint256", new(big.Int).Sub(new(big.Int).Lsh(oneInt, 256), oneInt), new(big.Int).Sub(new(big.Int).Lsh(oneInt, 128), oneInt)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := BigIntSqrt(tt.input)
+			if result.Cmp(tt.expected) != 0 {
+				t.Errorf("Expected %s, got %s", tt.expected.String(), result.String())
+			}
+		})
+	}
+
+	for i := int64(0); i < 2000; i++ {
+		x := big.NewInt(i * i * 7919)
+		if got, want := BigIntSqrt(x), new(big.Int).Sqrt(x); got.Cmp(want) != 0 {
+			t.Fatalf("BigIntSqrt(%s) = %s, want %s", x, got, want)
+		}
+	}
+}
+
+func BenchmarkDecimalSqrt(b *testing.B) {
+	d := NewFromInt(2)
+	for i := 0; i < b.N; i++ {
+		d.Sqrt(40)
+	}
+}
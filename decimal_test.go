@@ -0,0 +1,19 @@
+package safem
+
+import "testing"
+
+func TestNewFromFloat32(t *testing.T) {
+	tests := map[float32]string{
+		0:        "0",
+		0.1:      "0.1",
+		1.1:      "1.1",
+		-3.25:    "-3.25",
+		16777217: "16777216",
+		1e-45:    "0.000000000000000000000000000000000000000000001",
+	}
+	for f, expected := range tests {
+		if got := NewFromFloat32(f).String(); got != expected {
+			t.Errorf("Expected %s, got %s", expected, got)
+		}
+	}
+}
@@ -0,0 +1,392 @@
+package safem
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// SinPrec returns the sine of the radian argument d, rounded to precision digits
+// after the decimal point.
+//
+// Unlike Sin, the argument is reduced modulo π/2 using Pi at the precision the
+// input requires, so the result is accurate even for large arguments.
+//
+// Example:
+//
+//	NewFromInt(1).SinPrec(20).String()  // output: "0.84147098480789650665"
+//	NewFromInt(1e6).SinPrec(10).String() // output: "-0.3499935022"
+func (d Decimal) SinPrec(precision int32) Decimal {
+	if d.IsZero() {
+		return New(0, 0).Round(precision)
+	}
+	w := trigWorkPrecision(precision)
+	sin, cos, q := reduceHalfPi(d, w)
+	return quadrantValue(sin, cos, q).Round(precision)
+}
+
+// CosPrec returns the cosine of the radian argument d, rounded to precision digits
+// after the decimal point.
+//
+// Example:
+//
+//	NewFromInt(1).CosPrec(20).String() // output: "0.54030230586813971740"
+func (d Decimal) CosPrec(precision int32) Decimal {
+	if d.IsZero() {
+		return New(1, 0).Round(precision)
+	}
+	w := trigWorkPrecision(precision)
+	sin, cos, q := reduceHalfPi(d, w)
+	return quadrantValue(sin, cos, q+1).Round(precision)
+}
+
+// TanPrec returns the tangent of the radian argument d, rounded to precision digits
+// after the decimal point.
+//
+// Example:
+//
+//	NewFromInt(1).TanPrec(20).String() // output: "1.55740772465490223051"
+func (d Decimal) TanPrec(precision int32) Decimal {
+	if d.IsZero() {
+		return New(0, 0).Round(precision)
+	}
+
+	w := trigWorkPrecision(precision)
+	for {
+		sin, cos, q := reduceHalfPi(d, w)
+		num := quadrantValue(sin, cos, q)
+		den := quadrantValue(sin, cos, q+1)
+		if den.IsZero() {
+			w *= 2
+			continue
+		}
+
+		// near ±π/2 tan(x) ~ 1/cos(x), so each leading zero of the cosine costs
+		// two digits of the quotient
+		lost := -(den.exp + int32(den.NumDigits()))
+		if need := trigWorkPrecision(precision) + 2*lost + 2; lost > 0 && w < need {
+			w = need
+			continue
+		}
+
+		return num.DivRound(den, precision)
+	}
+}
+
+// AtanPrec returns the arctangent, in radians, of d, rounded to precision digits
+// after the decimal point.
+//
+// Example:
+//
+//	NewFromInt(1).AtanPrec(20).String() // output: "0.78539816339744830962"
+func (d Decimal) AtanPrec(precision int32) Decimal {
+	w := trigWorkPrecision(precision)
+	return atanFixed(d, w).Round(precision)
+}
+
+// Asin returns the arcsine, in radians, of d, rounded to precision digits after
+// the decimal point.
+//
+// Asin returns error when |d| > 1.
+//
+// Example:
+//
+//	d, err := NewFromFloat(0.5).Asin(20)
+//	d.String() // output: "0.52359877559829887308"
+func (d Decimal) Asin(precision int32) (Decimal, error) {
+	one := New(1, 0)
+	if d.Abs().GreaterThan(one) {
+		return Decimal{}, fmt.Errorf("cannot calculate arcsine for decimals outside [-1, 1]")
+	}
+	w := trigWorkPrecision(precision)
+	return asinFixed(d, w).Round(precision), nil
+}
+
+// Acos returns the arccosine, in radians, of d, rounded to precision digits after
+// the decimal point.
+//
+// Acos returns error when |d| > 1.
+//
+// Example:
+//
+//	d, err := NewFromFloat(0.5).Acos(20)
+//	d.String() // output: "1.04719755119659774615"
+func (d Decimal) Acos(precision int32) (Decimal, error) {
+	one := New(1, 0)
+	if d.Abs().GreaterThan(one) {
+		return Decimal{}, fmt.Errorf("cannot calculate arccosine for decimals outside [-1, 1]")
+	}
+	w := trigWorkPrecision(precision)
+	halfPi := Pi(w).Mul(New(5, -1))
+	return halfPi.Sub(asinFixed(d, w)).Round(precision), nil
+}
+
+// Atan2 returns the arctangent of d/x, using the signs of the two to determine the
+// quadrant of the return value, rounded to precision digits after the decimal point.
+// The receiver is the y coordinate, following math.Atan2(y, x).
+//
+// Atan2 of (0, 0) is 0.
+//
+// Example:
+//
+//	NewFromInt(1).Atan2(NewFromInt(-1), 20).String() // output: "2.35619449019234492885"
+func (d Decimal) Atan2(x Decimal, precision int32) Decimal {
+	w := trigWorkPrecision(precision)
+	y := d
+
+	if x.IsZero() {
+		if y.IsZero() {
+			return New(0, 0).Round(precision)
+		}
+		halfPi := Pi(w).Mul(New(5, -1))
+		if y.IsNegative() {
+			halfPi = halfPi.Neg()
+		}
+		return halfPi.Round(precision)
+	}
+
+	var res Decimal
+	if y.Abs().Cmp(x.Abs()) <= 0 {
+		// |y/x| <= 1, add ±π when x points left
+		res = atanFixed(y.DivRound(x, w), w)
+		if x.IsNegative() {
+			if y.IsNegative() {
+				res = res.Sub(Pi(w))
+			} else {
+				res = res.Add(Pi(w))
+			}
+		}
+	} else {
+		// |x/y| < 1, atan2(y, x) = sign(y) * π/2 - atan(x/y)
+		halfPi := Pi(w).Mul(New(5, -1))
+		if y.IsNegative() {
+			halfPi = halfPi.Neg()
+		}
+		res = halfPi.Sub(atanFixed(x.DivRound(y, w), w))
+	}
+
+	return res.Round(precision)
+}
+
+// Sinh returns the hyperbolic sine of d, rounded to precision digits after the
+// decimal point.
+//
+// Sinh returns error when e^d cannot be calculated, see ExpTaylor.
+//
+// Example:
+//
+//	d, err := NewFromInt(1).Sinh(20)
+//	d.String() // output: "1.17520119364380145688"
+func (d Decimal) Sinh(precision int32) (Decimal, error) {
+	if d.IsZero() {
+		return New(0, 0).Round(precision), nil
+	}
+	w := trigWorkPrecision(precision)
+	ep, en, err := expPair(d, w)
+	if err != nil {
+		return Decimal{}, fmt.Errorf("cannot calculate hyperbolic sine: %w", err)
+	}
+	return ep.Sub(en).Mul(New(5, -1)).Round(precision), nil
+}
+
+// Cosh returns the hyperbolic cosine of d, rounded to precision digits after the
+// decimal point.
+//
+// Cosh returns error when e^d cannot be calculated, see ExpTaylor.
+//
+// Example:
+//
+//	d, err := NewFromInt(1).Cosh(20)
+//	d.String() // output: "1.54308063481524377848"
+func (d Decimal) Cosh(precision int32) (Decimal, error) {
+	if d.IsZero() {
+		return New(1, 0).Round(precision), nil
+	}
+	w := trigWorkPrecision(precision)
+	ep, en, err := expPair(d, w)
+	if err != nil {
+		return Decimal{}, fmt.Errorf("cannot calculate hyperbolic cosine: %w", err)
+	}
+	return ep.Add(en).Mul(New(5, -1)).Round(precision), nil
+}
+
+// Tanh returns the hyperbolic tangent of d, rounded to precision digits after the
+// decimal point.
+//
+// Tanh returns error when e^(2d) cannot be calculated, see ExpTaylor.
+//
+// Example:
+//
+//	d, err := NewFromInt(1).Tanh(20)
+//	d.String() // output: "0.76159415595576488812"
+func (d Decimal) Tanh(precision int32) (Decimal, error) {
+	if d.IsZero() {
+		return New(0, 0).Round(precision), nil
+	}
+	one := New(1, 0)
+	w := trigWorkPrecision(precision)
+
+	// 1 - |tanh(x)| < 2 * e^(-2|x|), which is below 10^(-w) once |x| > (w+1) * ln(10) / 2
+	limit := New(int64(w)+1, 0).Mul(ln10.withPrecision(4)).Mul(New(5, -1)).Add(one)
+	if d.Abs().GreaterThan(limit) {
+		return New(int64(d.Sign()), 0).Round(precision), nil
+	}
+
+	e2x, err := d.Add(d).ExpTaylor(w)
+	if err != nil {
+		return Decimal{}, fmt.Errorf("cannot calculate hyperbolic tangent: %w", err)
+	}
+	return e2x.Sub(one).DivRound(e2x.Add(one), precision), nil
+}
+
+// trigWorkPrecision returns the working precision used for trigonometric and
+// hyperbolic series: a few guard digits plus enough to absorb the per-term
+// truncation error, which grows with the number of terms.
+func trigWorkPrecision(precision int32) int32 {
+	p := precision
+	if p < 0 {
+		p = 0
+	}
+	return p + 6 + int32(math.Log10(float64(p)+1))
+}
+
+// reduceHalfPi writes d = k * π/2 + r with |r| <= π/4 and returns sin(r) and cos(r)
+// as decimals with w places, along with k mod 4.
+func reduceHalfPi(d Decimal, w int32) (sin, cos Decimal, q int) {
+	// k has up to intDigits digits, so π/2 needs that many extra places to keep
+	// the absolute error of r below 10^(-w)
+	intDigits := int32(d.NumDigits()) + d.exp
+	if intDigits < 0 {
+		intDigits = 0
+	}
+	halfPi := Pi(w + intDigits + 2).Mul(New(5, -1))
+
+	k := d.DivRound(halfPi, 0)
+	r := d.Sub(k.Mul(halfPi))
+
+	mod := new(big.Int).Mod(k.rescale(0).value, fourInt)
+	q = int(mod.Int64())
+
+	s, c := sinCosFixed(r.rescale(-w).value, w)
+	return Decimal{s, -w}, Decimal{c, -w}, q
+}
+
+// quadrantValue returns sin(k * π/2 + r) given sin(r), cos(r) and q = k mod 4.
+// cos(x) is sin(x + π/2), i.e. quadrantValue(sin, cos, q+1).
+func quadrantValue(sin, cos Decimal, q int) Decimal {
+	switch q % 4 {
+	case 0:
+		return sin
+	case 1:
+		return cos
+	case 2:
+		return sin.Neg()
+	default:
+		return cos.Neg()
+	}
+}
+
+// sinCosFixed evaluates the Taylor series of sin and cos for the fixed-point
+// argument r / 10^w with |r / 10^w| <= 1, returning results scaled by 10^w.
+func sinCosFixed(r *big.Int, w int32) (sin, cos *big.Int) {
+	one := new(big.Int).Exp(tenInt, big.NewInt(int64(w)), nil)
+	r2 := new(big.Int).Mul(r, r)
+	r2.Quo(r2, one)
+
+	sin = new(big.Int).Set(r)
+	term := new(big.Int).Set(r)
+	for k := int64(1); term.Sign() != 0; k++ {
+		term.Mul(term, r2).Quo(term, one)
+		term.Quo(term, big.NewInt(-(2*k)*(2*k+1)))
+		sin.Add(sin, term)
+	}
+
+	cos = new(big.Int).Set(one)
+	term.Set(one)
+	for k := int64(1); term.Sign() != 0; k++ {
+		term.Mul(term, r2).Quo(term, one)
+		term.Quo(term, big.NewInt(-(2*k-1)*(2*k)))
+		cos.Add(cos, term)
+	}
+
+	return sin, cos
+}
+
+// atanFixed returns atan(d) with w places.
+func atanFixed(d Decimal, w int32) Decimal {
+	if d.IsZero() {
+		return New(0, 0)
+	}
+
+	one := New(1, 0)
+	neg := d.IsNegative()
+	x := d.Abs()
+
+	// atan(x) = π/2 - atan(1/x) for x > 1
+	invert := x.GreaterThan(one)
+	if invert {
+		x = one.DivRound(x, w+2)
+	}
+
+	// each halving doubles the final error, so carry three more digits
+	wf := w + 3
+	unit := new(big.Int).Exp(tenInt, big.NewInt(int64(wf)), nil)
+	unit2 := new(big.Int).Mul(unit, unit)
+	limit := new(big.Int).Quo(unit, big.NewInt(100))
+
+	// halve the argument with atan(x) = 2 * atan(x / (1 + sqrt(1 + x^2)))
+	// until the series below converges quickly
+	X := x.rescale(-wf).value
+	halvings := uint(0)
+	for X.Cmp(limit) > 0 {
+		s := new(big.Int).Mul(X, X)
+		s.Add(s, unit2).Sqrt(s)
+		s.Add(s, unit)
+		X.Mul(X, unit).Quo(X, s)
+		halvings++
+	}
+
+	// atan(x) = x - x^3/3 + x^5/5 - ...
+	x2 := new(big.Int).Mul(X, X)
+	x2.Quo(x2, unit)
+	sum := new(big.Int).Set(X)
+	pow := new(big.Int).Set(X)
+	tmp := new(big.Int)
+	for k := int64(1); pow.Sign() != 0; k++ {
+		pow.Mul(pow, x2).Quo(pow, unit).Neg(pow)
+		sum.Add(sum, tmp.Quo(pow, big.NewInt(2*k+1)))
+	}
+	sum.Lsh(sum, halvings)
+
+	res := Decimal{sum, -wf}
+	if invert {
+		res = Pi(wf).Mul(New(5, -1)).Sub(res)
+	}
+	if neg {
+		res = res.Neg()
+	}
+	return res
+}
+
+// asinFixed returns asin(d) with w places for |d| <= 1, using the well-conditioned
+// identity asin(x) = 2 * atan(x / (1 + sqrt(1 - x^2))).
+func asinFixed(d Decimal, w int32) Decimal {
+	one := New(1, 0)
+	s, _ := one.Sub(d.Mul(d)).Sqrt(w + 2)
+	t := d.DivRound(one.Add(s), w+2)
+	a := atanFixed(t, w)
+	return a.Add(a)
+}
+
+// expPair returns e^d and e^(-d) with at least w places.
+func expPair(d Decimal, w int32) (Decimal, Decimal, error) {
+	ep, err := d.ExpTaylor(w)
+	if err != nil {
+		return Decimal{}, Decimal{}, err
+	}
+	en, err := d.Neg().ExpTaylor(w)
+	if err != nil {
+		return Decimal{}, Decimal{}, err
+	}
+	return ep, en, nil
+}
@@ -0,0 +1,137 @@
+package safem
+
+import (
+	"testing"
+)
+
+// Reference values computed independently at 200 significant digits and rounded
+// half up to the requested number of places.
+func TestDecimalTrigPrecisionReference(t *testing.T) {
+	tests := []struct {
+		fn        string
+		input     string
+		precision int32
+		expected  string
+	}{
+		{"sin", "1", 60, "0.841470984807896506652502321630298999622563060798371065672752"},
+		{"sin", "1000000", 55, "-0.3499935021712929521176524867807714690614066053287162739"},
+		{"sin", "-2.5", 55, "-0.5984721441039564940518547021861622717035971715772235733"},
+		{"sin", "12345678901234567890.5", 52, "0.8637091954439360904196776760038334949113437714324785"},
+		{"cos", "1", 60, "0.540302305868139717400936607442976603732310420617922227670097"},
+		{"cos", "3.14159", 55, "-0.9999999999964792306046123925085004832510182873886512279"},
+		{"cos", "-100", 55, "0.8623188722876839341019385139508425355100840085355108293"},
+		{"tan", "1", 60, "1.557407724654902230506974807458360173087250772381520038383947"},
+		{"tan", "1.5707963267", 55, "10537783201.3423171981452566485903050107357102238999833523389879731"},
+		{"tan", "-0.3", 55, "-0.3093362496096232330353036796982946672578159068004613408"},
+		{"atan", "1", 60, "0.785398163397448309615660845819875721049292349843776455243736"},
+		{"atan", "-7.25", 55, "-1.4337301524847089866404719096698873648609738893518238121"},
+		{"atan", "0.001", 55, "0.0009999996666668666665238096349205440116209345542680131"},
+		{"asin", "0.5", 60, "0.523598775598298873077107230546583814032861566562517636829157"},
+		{"asin", "-0.999999", 55, "-1.5693821131146723674682498958670957936345586639191267502"},
+		{"asin", "1", 55, "1.5707963267948966192313216916397514420985846996875529105"},
+		{"acos", "0.5", 60, "1.047197551196597746154214461093167628065723133125035273658315"},
+		{"acos", "-1", 55, "3.1415926535897932384626433832795028841971693993751058210"},
+		{"acos", "0.999", 55, "0.0447250871687334312496962326715510699041805567621581828"},
+		{"sinh", "1", 60, "1.175201193643801456882381850595600815155717981334095870229565"},
+		{"sinh", "-0.001", 55, "-0.0010000001666666750000001984127011684303601491103097006"},
+		{"sinh", "10", 55, "11013.2328747033933772365245548463644029014511903193461038352"},
+		{"cosh", "1", 60, "1.543080634815243778477905620757061682601529112365863704737402"},
+		{"cosh", "-3", 55, "10.0676619957777658419539360351158898368098037153712866800"},
+		{"tanh", "1", 60, "0.761594155955764888119458282604793590412768597257936551596811"},
+		{"tanh", "-0.5", 55, "-0.4621171572600097585023184836436725487302892803301130386"},
+		{"tanh", "200", 55, "1.0000000000000000000000000000000000000000000000000000000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fn+"("+tt.input+")", func(t *testing.T) {
+			d := RequireFromString(tt.input)
+			var result Decimal
+			var err error
+			switch tt.fn {
+			case "sin":
+				result = d.SinPrec(tt.precision)
+			case "cos":
+				result = d.CosPrec(tt.precision)
+			case "tan":
+				result = d.TanPrec(tt.precision)
+			case "atan":
+				result = d.AtanPrec(tt.precision)
+			case "asin":
+				result, err = d.Asin(tt.precision)
+			case "acos":
+				result, err = d.Acos(tt.precision)
+			case "sinh":
+				result, err = d.Sinh(tt.precision)
+			case "cosh":
+				result, err = d.Cosh(tt.precision)
+			case "tanh":
+				result, err = d.Tanh(tt.precision)
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !result.Equal(RequireFromString(tt.expected)) {
+				t.Errorf("Expected %s, got %s", tt.expected, result.String())
+			}
+		})
+	}
+}
+
+func TestDecimalAtan2(t *testing.T) {
+	tests := []struct {
+		fn        string
+		y         string
+		x         string
+		precision int32
+		expected  string
+	}{
+		{"atan2", "1", "-1", 60, "2.356194490192344928846982537459627163147877049531329365731208"},
+		{"atan2", "-3", "-4", 55, "-2.4980915447965088516598341545621802461556588082597934381"},
+		{"atan2", "5", "0.25", 55, "1.5208379310729538578213154046049065606073076192640457361"},
+		{"atan2", "-2", "0", 55, "-1.5707963267948966192313216916397514420985846996875529105"},
+		{"atan2", "0", "0", 10, "0"},
+		{"atan2", "0", "-1", 10, "3.1415926536"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.y+","+tt.x, func(t *testing.T) {
+			result := RequireFromString(tt.y).Atan2(RequireFromString(tt.x), tt.precision)
+			if !result.Equal(RequireFromString(tt.expected)) {
+				t.Errorf("Expected %s, got %s", tt.expected, result.String())
+			}
+		})
+	}
+}
+
+func TestDecimalInverseTrigDomain(t *testing.T) {
+	if _, err := NewFromFloat(1.0001).Asin(10); err == nil {
+		t.Errorf("Expected error for Asin outside [-1, 1]")
+	}
+	if _, err := NewFromFloat(-1.5).Acos(10); err == nil {
+		t.Errorf("Expected error for Acos outside [-1, 1]")
+	}
+}
+
+func TestDecimalTrigZero(t *testing.T) {
+	zero := Decimal{}
+	if !zero.SinPrec(10).IsZero() || !zero.TanPrec(10).IsZero() || !zero.AtanPrec(10).IsZero() {
+		t.Errorf("Expected odd functions to vanish at zero")
+	}
+	if cosh, err := zero.Cosh(10); err != nil || !zero.CosPrec(10).Equal(New(1, 0)) || !cosh.Equal(New(1, 0)) {
+		t.Errorf("Expected cos(0) and cosh(0) to be 1")
+	}
+}
+
+func BenchmarkDecimalSinPrec(b *testing.B) {
+	d := NewFromFloat(1.2345)
+	for i := 0; i < b.N; i++ {
+		d.SinPrec(50)
+	}
+}
+
+func BenchmarkDecimalSin(b *testing.B) {
+	d := NewFromFloat(1.2345)
+	for i := 0; i < b.N; i++ {
+		d.Sin()
+	}
+}
@@ -0,0 +1,97 @@
+// Package fixedpoint converts between the binary fixed-point numbers of
+// concentrated-liquidity AMMs and safem.Decimal: Q64.96 square-root prices,
+// Q128.128 ratios, ticks and human-readable prices.
+//
+// Tick and sqrt price conversions reproduce Uniswap v3's TickMath bit for bit,
+// and MulDiv reproduces FullMath's mulDiv and mulDivRoundingUp, so results can be
+// compared with on-chain values exactly. Values are *big.Int interpreted as
+// Solidity uint256 (or uint160 for sqrt prices); inputs are never modified and
+// every result is a new value.
+//
+// Example:
+//
+//	sqrtPriceX96, _ := fixedpoint.SqrtRatioAtTick(-201000)
+//	tick, _ := fixedpoint.TickAtSqrtRatio(sqrtPriceX96)       // -201000
+//	price, _ := fixedpoint.SqrtPriceX96ToPrice(sqrtPriceX96, 6, 18) // WETH per USDC
+package fixedpoint
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/morpheum-labs/safem"
+	"github.com/morpheum-labs/safem/internal/bigint"
+)
+
+var (
+	// Q96 is 2^96, the value of 1 in Q64.96.
+	Q96 = new(big.Int).Lsh(big.NewInt(1), 96)
+
+	// Q128 is 2^128, the value of 1 in Q128.128.
+	Q128 = new(big.Int).Lsh(big.NewInt(1), 128)
+
+	maxUint160 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 160), big.NewInt(1))
+	maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+)
+
+// uint256 returns ErrInvalidInput when x is nil, ErrNegativeInput when x is
+// negative and ErrTooLarge when x does not fit in 256 bits.
+func uint256(name string, x *big.Int) error {
+	if x == nil {
+		return fmt.Errorf("cannot compute: %s is nil: %w", name, safem.ErrInvalidInput)
+	}
+	if x.Sign() < 0 {
+		return fmt.Errorf("cannot compute: %s is negative: %w", name, safem.ErrNegativeInput)
+	}
+	if x.Cmp(maxUint256) > 0 {
+		return fmt.Errorf("cannot compute: %s exceeds uint256: %w", name, safem.ErrTooLarge)
+	}
+	return nil
+}
+
+// MulDiv returns a * b / denominator rounded with mode. The product is kept to
+// full precision, as with FullMath's 512-bit intermediate, so it may exceed 256
+// bits as long as the result does not.
+//
+// With big.ToZero or big.ToNegativeInf the result is FullMath.mulDiv, with
+// big.AwayFromZero or big.ToPositiveInf it is FullMath.mulDivRoundingUp. The
+// nearest modes round halfway cases to even or away from zero.
+//
+// Returns ErrDivisionByZero when denominator is zero, ErrNegativeInput for
+// negative arguments and ErrTooLarge when an argument or the rounded result does
+// not fit in 256 bits, where the contract would revert.
+//
+// Example:
+//
+//	MulDiv(Q128, Q128, new(big.Int).Mul(big.NewInt(3), Q128), big.ToZero)
+//	// output: 113427455640312821154458202477256070485 (Q128 / 3)
+func MulDiv(a, b, denominator *big.Int, mode big.RoundingMode) (*big.Int, error) {
+	if err := uint256("a", a); err != nil {
+		return nil, err
+	}
+	if err := uint256("b", b); err != nil {
+		return nil, err
+	}
+	if err := uint256("denominator", denominator); err != nil {
+		return nil, err
+	}
+	if denominator.Sign() == 0 {
+		return nil, fmt.Errorf("cannot compute mulDiv: %w", safem.ErrDivisionByZero)
+	}
+
+	result := bigint.QuoRound(new(big.Int).Mul(a, b), denominator, mode)
+	if result.Cmp(maxUint256) > 0 {
+		return nil, fmt.Errorf("cannot compute mulDiv: result exceeds uint256: %w", safem.ErrTooLarge)
+	}
+	return result, nil
+}
+
+// MulDivDown is MulDiv rounding down, FullMath.mulDiv.
+func MulDivDown(a, b, denominator *big.Int) (*big.Int, error) {
+	return MulDiv(a, b, denominator, big.ToZero)
+}
+
+// MulDivUp is MulDiv rounding up, FullMath.mulDivRoundingUp.
+func MulDivUp(a, b, denominator *big.Int) (*big.Int, error) {
+	return MulDiv(a, b, denominator, big.AwayFromZero)
+}
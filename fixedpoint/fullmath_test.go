@@ -0,0 +1,103 @@
+package fixedpoint
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/morpheum-labs/safem"
+)
+
+func bigInt(s string) *big.Int {
+	x, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("invalid integer " + s)
+	}
+	return x
+}
+
+func mulQ128(n, d int64) *big.Int {
+	x := new(big.Int).Mul(Q128, big.NewInt(n))
+	return x.Quo(x, big.NewInt(d))
+}
+
+// Vectors from Uniswap v3-core test/FullMath.spec.ts.
+func TestMulDiv(t *testing.T) {
+	third := new(big.Int).Quo(Q128, big.NewInt(3))
+	thirdUp := new(big.Int).Add(third, big.NewInt(1))
+
+	tests := []struct {
+		name          string
+		a, b, d       *big.Int
+		down, up      *big.Int
+		errDown, errU error
+	}{
+		{"all max inputs", maxUint256, maxUint256, maxUint256, maxUint256, maxUint256, nil, nil},
+		{"accurate without phantom overflow", Q128, mulQ128(50, 100), mulQ128(150, 100), third, thirdUp, nil, nil},
+		{"accurate with phantom overflow", Q128, mulQ128(35, 1), mulQ128(8, 1), mulQ128(4375, 1000), mulQ128(4375, 1000), nil, nil},
+		{"accurate with phantom overflow and repeating decimal", Q128, mulQ128(1000, 1), mulQ128(3000, 1), third, thirdUp, nil, nil},
+		{"denominator is 0", Q128, big.NewInt(5), big.NewInt(0), nil, nil, safem.ErrDivisionByZero, safem.ErrDivisionByZero},
+		{"output overflows uint256", Q128, Q128, big.NewInt(1), nil, nil, safem.ErrTooLarge, safem.ErrTooLarge},
+		{"overflow with all max inputs", maxUint256, maxUint256, new(big.Int).Sub(maxUint256, big.NewInt(1)), nil, nil, safem.ErrTooLarge, safem.ErrTooLarge},
+		{"mulDivRoundingUp overflows after rounding up",
+			big.NewInt(535006138814359), bigInt("432862656469423142931042426214547535783388063929571229938474969"), big.NewInt(2),
+			maxUint256, nil, nil, safem.ErrTooLarge},
+		{"mulDivRoundingUp overflows after rounding up 2",
+			bigInt("115792089237316195423570985008687907853269984659341747863450311749907997002549"),
+			bigInt("115792089237316195423570985008687907853269984659341747863450311749907997002550"),
+			bigInt("115792089237316195423570985008687907853269984653042931687443039491902864365164"),
+			maxUint256, nil, nil, safem.ErrTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			down, err := MulDivDown(tt.a, tt.b, tt.d)
+			if !errors.Is(err, tt.errDown) {
+				t.Errorf("MulDivDown: Expected error %v, got %v", tt.errDown, err)
+			} else if err == nil && down.Cmp(tt.down) != 0 {
+				t.Errorf("MulDivDown: Expected %s, got %s", tt.down, down)
+			}
+			up, err := MulDivUp(tt.a, tt.b, tt.d)
+			if !errors.Is(err, tt.errU) {
+				t.Errorf("MulDivUp: Expected error %v, got %v", tt.errU, err)
+			} else if err == nil && up.Cmp(tt.up) != 0 {
+				t.Errorf("MulDivUp: Expected %s, got %s", tt.up, up)
+			}
+		})
+	}
+}
+
+func TestMulDivModes(t *testing.T) {
+	tests := []struct {
+		a, b, d  int64
+		mode     big.RoundingMode
+		expected int64
+	}{
+		{7, 1, 2, big.ToZero, 3},
+		{7, 1, 2, big.ToNegativeInf, 3},
+		{7, 1, 2, big.AwayFromZero, 4},
+		{7, 1, 2, big.ToPositiveInf, 4},
+		{7, 1, 2, big.ToNearestEven, 4},
+		{5, 1, 2, big.ToNearestEven, 2},
+		{5, 1, 2, big.ToNearestAway, 3},
+		{5, 3, 4, big.ToNearestEven, 4},
+		{5, 3, 7, big.ToNearestEven, 2},
+		{6, 1, 3, big.AwayFromZero, 2},
+	}
+	for _, tt := range tests {
+		got, err := MulDiv(big.NewInt(tt.a), big.NewInt(tt.b), big.NewInt(tt.d), tt.mode)
+		if err != nil || got.Int64() != tt.expected {
+			t.Errorf("MulDiv(%d, %d, %d, %v): Expected %d, got %v %v", tt.a, tt.b, tt.d, tt.mode, tt.expected, got, err)
+		}
+	}
+
+	if _, err := MulDivDown(big.NewInt(-1), big.NewInt(1), big.NewInt(1)); !errors.Is(err, safem.ErrNegativeInput) {
+		t.Errorf("Expected ErrNegativeInput, got %v", err)
+	}
+	if _, err := MulDivDown(nil, big.NewInt(1), big.NewInt(1)); !errors.Is(err, safem.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput, got %v", err)
+	}
+	if _, err := MulDivDown(new(big.Int).Lsh(Q128, 128), big.NewInt(0), big.NewInt(1)); !errors.Is(err, safem.ErrTooLarge) {
+		t.Errorf("Expected ErrTooLarge, got %v", err)
+	}
+}
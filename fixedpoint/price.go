@@ -0,0 +1,182 @@
+package fixedpoint
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/morpheum-labs/safem"
+	"github.com/morpheum-labs/safem/internal/bigint"
+)
+
+const (
+	// Resolution96 is the number of fractional bits of a Q64.96 number, as
+	// FixedPoint96.RESOLUTION.
+	Resolution96 = 96
+
+	// Resolution128 is the number of fractional bits of a Q128.128 number, as
+	// FixedPoint128.
+	Resolution128 = 128
+)
+
+var fiveInt = big.NewInt(5)
+
+// FromQ returns the unsigned fixed-point number x with fractionalBits fractional
+// bits as an exact Decimal. Every binary fraction has a finite decimal expansion,
+// so no rounding takes place.
+//
+// Example:
+//
+//	FromQ(big.NewInt(3), 1)                 // output: 1.5
+//	FromQ(new(big.Int).Rsh(Q96, 1), Resolution96) // output: 0.5
+func FromQ(x *big.Int, fractionalBits uint) safem.Decimal {
+	if x == nil {
+		return safem.New(0, 0)
+	}
+	// x / 2^n = x * 5^n / 10^n
+	pow5 := new(big.Int).Exp(fiveInt, big.NewInt(int64(fractionalBits)), nil)
+	return safem.NewFromBigInt(new(big.Int).Mul(x, pow5), -int32(fractionalBits))
+}
+
+// ToQ returns d as an unsigned fixed-point number with fractionalBits fractional
+// bits, rounded with mode. Returns ErrNegativeInput when d is negative.
+//
+// Example:
+//
+//	ToQ(safem.RequireFromString("1.5"), 1, big.ToZero) // output: 3
+//	ToQ(safem.RequireFromString("0.1"), 4, big.ToZero) // output: 1 (0.0625)
+func ToQ(d safem.Decimal, fractionalBits uint, mode big.RoundingMode) (*big.Int, error) {
+	if d.Sign() < 0 {
+		return nil, fmt.Errorf("cannot convert %s to fixed point: %w", d, safem.ErrNegativeInput)
+	}
+	num, den := ratio(d)
+	num.Lsh(num, fractionalBits)
+	return bigint.QuoRound(num, den, mode), nil
+}
+
+// ratio returns d as num / den with den a power of ten.
+func ratio(d safem.Decimal) (num, den *big.Int) {
+	num = d.Coefficient()
+	den = big.NewInt(1)
+	if exp := d.Exponent(); exp >= 0 {
+		num.Mul(num, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil))
+	} else {
+		den.Exp(big.NewInt(10), big.NewInt(int64(-exp)), nil)
+	}
+	return num, den
+}
+
+// SqrtPriceX96ToPrice returns the exact price of token0 in units of token1 at
+// sqrtPriceX96, adjusted for the tokens' decimals:
+//
+//	price = (sqrtPriceX96 / 2^96)^2 * 10^(decimals0 - decimals1)
+//
+// The result is exact, with up to 192 fractional digits more than the decimals
+// adjustment; round it for display. Returns ErrInvalidInput when sqrtPriceX96 is
+// nil, ErrNegativeInput when it is negative and ErrTooLarge when it exceeds
+// uint160.
+//
+// Example:
+//
+//	// USDC (6 decimals) / WETH (18 decimals) at tick 200000
+//	sqrtPriceX96, _ := SqrtRatioAtTick(200000)
+//	SqrtPriceX96ToPrice(sqrtPriceX96, 6, 18) // WETH per USDC
+func SqrtPriceX96ToPrice(sqrtPriceX96 *big.Int, decimals0, decimals1 uint8) (safem.Decimal, error) {
+	if err := uint160(sqrtPriceX96); err != nil {
+		return safem.Decimal{}, err
+	}
+	priceX192 := new(big.Int).Mul(sqrtPriceX96, sqrtPriceX96)
+	return FromQ(priceX192, 2*Resolution96).Shift(int32(decimals0) - int32(decimals1)), nil
+}
+
+// PriceToSqrtPriceX96 returns the sqrt price of a price of token0 in units of
+// token1, the inverse of SqrtPriceX96ToPrice. The square root is computed
+// exactly and rounded once with mode.
+//
+// Returns ErrNegativeInput for a negative price and ErrTooLarge when the sqrt
+// price exceeds uint160. The result is not checked against MinSqrtRatio and
+// MaxSqrtRatio; TickAtSqrtRatio rejects prices outside the tick range.
+//
+// Example:
+//
+//	PriceToSqrtPriceX96(safem.RequireFromString("4"), 18, 18, big.ToZero) // output: 2 * Q96
+func PriceToSqrtPriceX96(price safem.Decimal, decimals0, decimals1 uint8, mode big.RoundingMode) (*big.Int, error) {
+	if price.Sign() < 0 {
+		return nil, fmt.Errorf("cannot convert price %s to sqrt price: %w", price, safem.ErrNegativeInput)
+	}
+	num, den := ratio(price.Shift(int32(decimals1) - int32(decimals0)))
+	num.Lsh(num, 2*Resolution96)
+
+	sqrtPriceX96 := sqrtRound(num, den, mode)
+	if sqrtPriceX96.Cmp(maxUint160) > 0 {
+		return nil, fmt.Errorf("cannot convert price %s to sqrt price: exceeds uint160: %w", price, safem.ErrTooLarge)
+	}
+	return sqrtPriceX96, nil
+}
+
+// TickToPrice returns the price of token0 in units of token1 at tick, using the
+// sqrt price of the tick as the pool does.
+//
+// Example:
+//
+//	TickToPrice(0, 18, 18)  // output: 1
+//	TickToPrice(0, 6, 18)   // output: 0.000000000001
+func TickToPrice(tick int32, decimals0, decimals1 uint8) (safem.Decimal, error) {
+	sqrtPriceX96, err := SqrtRatioAtTick(tick)
+	if err != nil {
+		return safem.Decimal{}, err
+	}
+	return SqrtPriceX96ToPrice(sqrtPriceX96, decimals0, decimals1)
+}
+
+// PriceToTick returns the greatest tick whose price is at most price. Returns
+// ErrInvalidInput when the price is outside the tick range.
+func PriceToTick(price safem.Decimal, decimals0, decimals1 uint8) (int32, error) {
+	sqrtPriceX96, err := PriceToSqrtPriceX96(price, decimals0, decimals1, big.ToZero)
+	if err != nil {
+		return 0, err
+	}
+	return TickAtSqrtRatio(sqrtPriceX96)
+}
+
+func uint160(sqrtPriceX96 *big.Int) error {
+	if sqrtPriceX96 == nil {
+		return fmt.Errorf("cannot convert sqrt price: nil: %w", safem.ErrInvalidInput)
+	}
+	if sqrtPriceX96.Sign() < 0 {
+		return fmt.Errorf("cannot convert sqrt price %s: %w", sqrtPriceX96, safem.ErrNegativeInput)
+	}
+	if sqrtPriceX96.Cmp(maxUint160) > 0 {
+		return fmt.Errorf("cannot convert sqrt price %s: exceeds uint160: %w", sqrtPriceX96, safem.ErrTooLarge)
+	}
+	return nil
+}
+
+// sqrtRound returns sqrt(n / d) rounded with mode, for n >= 0 and d > 0.
+func sqrtRound(n, d *big.Int, mode big.RoundingMode) *big.Int {
+	// ⌊√⌊x⌋⌋ = ⌊√x⌋ for every real x >= 0
+	s := new(big.Int).Sqrt(new(big.Int).Quo(n, d))
+
+	// compare s^2 * d with n for directed modes, (s + 1/2)^2 * d with n otherwise
+	var up bool
+	switch mode {
+	case big.ToZero, big.ToNegativeInf:
+		up = false
+	case big.AwayFromZero, big.ToPositiveInf:
+		sq := new(big.Int).Mul(s, s)
+		up = sq.Mul(sq, d).Cmp(n) != 0
+	default:
+		mid := new(big.Int).Lsh(s, 1)
+		mid.Add(mid, big.NewInt(1))
+		mid.Mul(mid, mid).Mul(mid, d)
+		switch c := mid.Cmp(new(big.Int).Lsh(n, 2)); {
+		case c < 0:
+			up = true
+		case c == 0:
+			up = mode == big.ToNearestAway || s.Bit(0) == 1
+		}
+	}
+	if up {
+		s.Add(s, big.NewInt(1))
+	}
+	return s
+}
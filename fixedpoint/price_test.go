@@ -0,0 +1,149 @@
+package fixedpoint
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/morpheum-labs/safem"
+)
+
+func TestFromQ(t *testing.T) {
+	tests := []struct {
+		x        *big.Int
+		bits     uint
+		expected string
+	}{
+		{big.NewInt(3), 1, "1.5"},
+		{Q96, Resolution96, "1"},
+		{new(big.Int).Rsh(Q96, 1), Resolution96, "0.5"},
+		{Q128, Resolution128, "1"},
+		{big.NewInt(1), 10, "0.0009765625"},
+		{nil, Resolution96, "0"},
+	}
+	for _, tt := range tests {
+		if got := FromQ(tt.x, tt.bits); got.String() != tt.expected {
+			t.Errorf("FromQ(%v, %d): Expected %s, got %s", tt.x, tt.bits, tt.expected, got)
+		}
+	}
+}
+
+func TestToQ(t *testing.T) {
+	tests := []struct {
+		d        string
+		bits     uint
+		mode     big.RoundingMode
+		expected string
+	}{
+		{"1.5", 1, big.ToZero, "3"},
+		{"0.1", 4, big.ToZero, "1"},
+		{"0.1", 4, big.AwayFromZero, "2"},
+		{"0.1", 4, big.ToNearestEven, "2"},
+		{"1", Resolution96, big.ToZero, "79228162514264337593543950336"},
+		{"1e3", 0, big.ToZero, "1000"},
+	}
+	for _, tt := range tests {
+		got, err := ToQ(safem.RequireFromString(tt.d), tt.bits, tt.mode)
+		if err != nil || got.String() != tt.expected {
+			t.Errorf("ToQ(%s, %d, %v): Expected %s, got %v %v", tt.d, tt.bits, tt.mode, tt.expected, got, err)
+		}
+	}
+	if _, err := ToQ(safem.RequireFromString("-1"), 96, big.ToZero); !errors.Is(err, safem.ErrNegativeInput) {
+		t.Errorf("Expected ErrNegativeInput, got %v", err)
+	}
+}
+
+func TestSqrtPriceX96ToPrice(t *testing.T) {
+	tests := []struct {
+		sqrtPriceX96         *big.Int
+		decimals0, decimals1 uint8
+		expected             string
+	}{
+		{Q96, 18, 18, "1"},
+		{new(big.Int).Lsh(Q96, 1), 18, 18, "4"},
+		{new(big.Int).Rsh(Q96, 1), 18, 18, "0.25"},
+		{Q96, 6, 18, "0.000000000001"},
+		{Q96, 18, 6, "1000000000000"},
+		// SqrtRatioAtTick(200000) for USDC (6 decimals) / WETH (18 decimals)
+		{bigInt("1744244129640337381386292603617838"), 6, 18,
+			"0.0004846803050257335883327160063504904004321876310447949473473814921024849473249488083809824164690706206841300722941960360400040086916487018400204395403538349209639213288625114728347398340702056884765625"},
+	}
+	for _, tt := range tests {
+		got, err := SqrtPriceX96ToPrice(tt.sqrtPriceX96, tt.decimals0, tt.decimals1)
+		if err != nil || got.String() != tt.expected {
+			t.Errorf("SqrtPriceX96ToPrice(%s, %d, %d): Expected %s, got %s %v", tt.sqrtPriceX96, tt.decimals0, tt.decimals1, tt.expected, got, err)
+		}
+	}
+
+	errTests := []struct {
+		sqrtPriceX96 *big.Int
+		err          error
+	}{
+		{nil, safem.ErrInvalidInput},
+		{big.NewInt(-1), safem.ErrNegativeInput},
+		{new(big.Int).Add(maxUint160, big.NewInt(1)), safem.ErrTooLarge},
+	}
+	for _, tt := range errTests {
+		if _, err := SqrtPriceX96ToPrice(tt.sqrtPriceX96, 18, 18); !errors.Is(err, tt.err) {
+			t.Errorf("SqrtPriceX96ToPrice(%v): Expected %v, got %v", tt.sqrtPriceX96, tt.err, err)
+		}
+	}
+}
+
+func TestPriceToSqrtPriceX96(t *testing.T) {
+	// the exact square root of this price is 1/2 in Q64.96
+	half := FromQ(big.NewInt(1), 2*Resolution96+2)
+
+	tests := []struct {
+		price                string
+		decimals0, decimals1 uint8
+		mode                 big.RoundingMode
+		expected             string
+	}{
+		{"1", 18, 18, big.ToZero, "79228162514264337593543950336"},
+		{"4", 18, 18, big.AwayFromZero, "158456325028528675187087900672"},
+		{"2.25", 18, 18, big.AwayFromZero, "118842243771396506390315925504"},
+		{"2", 18, 18, big.ToZero, "112045541949572279837463876454"},
+		{"2", 18, 18, big.AwayFromZero, "112045541949572279837463876455"},
+		{"2", 18, 18, big.ToNearestEven, "112045541949572279837463876455"},
+		{"0.000000000001", 6, 18, big.ToZero, "79228162514264337593543950336"},
+		{"0.0005", 6, 18, big.ToZero, "1771595571142957102961017161607260"},
+		{"0.0005", 6, 18, big.ToPositiveInf, "1771595571142957102961017161607261"},
+		{half.String(), 18, 18, big.ToNearestEven, "0"},
+		{half.String(), 18, 18, big.ToNearestAway, "1"},
+		{"0", 18, 18, big.AwayFromZero, "0"},
+	}
+	for _, tt := range tests {
+		got, err := PriceToSqrtPriceX96(safem.RequireFromString(tt.price), tt.decimals0, tt.decimals1, tt.mode)
+		if err != nil || got.String() != tt.expected {
+			t.Errorf("PriceToSqrtPriceX96(%s, %d, %d, %v): Expected %s, got %v %v", tt.price, tt.decimals0, tt.decimals1, tt.mode, tt.expected, got, err)
+		}
+	}
+
+	if _, err := PriceToSqrtPriceX96(safem.RequireFromString("-1"), 18, 18, big.ToZero); !errors.Is(err, safem.ErrNegativeInput) {
+		t.Errorf("Expected ErrNegativeInput, got %v", err)
+	}
+	if _, err := PriceToSqrtPriceX96(safem.RequireFromString("1e100"), 18, 18, big.ToZero); !errors.Is(err, safem.ErrTooLarge) {
+		t.Errorf("Expected ErrTooLarge, got %v", err)
+	}
+}
+
+func TestPriceTick(t *testing.T) {
+	tick, err := PriceToTick(safem.RequireFromString("0.0005"), 6, 18)
+	if err != nil || tick != 200311 {
+		t.Errorf("Expected 200311, got %d %v", tick, err)
+	}
+	if _, err := PriceToTick(safem.RequireFromString("1e-50"), 18, 18); !errors.Is(err, safem.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput, got %v", err)
+	}
+
+	for tick := int32(MinTick); tick <= MaxTick; tick += 7919 {
+		price, err := TickToPrice(tick, 6, 18)
+		if err != nil {
+			t.Fatalf("TickToPrice(%d): %v", tick, err)
+		}
+		if got, err := PriceToTick(price, 6, 18); err != nil || got != tick {
+			t.Errorf("PriceToTick(TickToPrice(%d)): Expected %d, got %d %v", tick, tick, got, err)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package fixedpoint
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/morpheum-labs/safem"
+)
+
+const (
+	// MinTick is the lowest tick, the tick of a price of 1.0001^-887272 ≈ 2^-128.
+	MinTick = -887272
+
+	// MaxTick is the highest tick, the tick of a price of 1.0001^887272 ≈ 2^128.
+	MaxTick = -MinTick
+)
+
+var (
+	// MinSqrtRatio is SqrtRatioAtTick(MinTick), the lowest sqrt price.
+	MinSqrtRatio = big.NewInt(4295128739)
+
+	// MaxSqrtRatio is SqrtRatioAtTick(MaxTick), one more than the highest sqrt
+	// price accepted by TickAtSqrtRatio.
+	MaxSqrtRatio, _ = new(big.Int).SetString("1461446703485210103287273052203988822378723970342", 10)
+)
+
+// tickRatios are 2^128 / sqrt(1.0001)^(2^i) for i = 0..19, in Q128.128, as in
+// TickMath.getSqrtRatioAtTick.
+var tickRatios = [20]*big.Int{
+	hexInt("fffcb933bd6fad37aa2d162d1a594001"),
+	hexInt("fff97272373d413259a46990580e213a"),
+	hexInt("fff2e50f5f656932ef12357cf3c7fdcc"),
+	hexInt("ffe5caca7e10e4e61c3624eaa0941cd0"),
+	hexInt("ffcb9843d60f6159c9db58835c926644"),
+	hexInt("ff973b41fa98c081472e6896dfb254c0"),
+	hexInt("ff2ea16466c96a3843ec78b326b52861"),
+	hexInt("fe5dee046a99a2a811c461f1969c3053"),
+	hexInt("fcbe86c7900a88aedcffc83b479aa3a4"),
+	hexInt("f987a7253ac413176f2b074cf7815e54"),
+	hexInt("f3392b0822b70005940c7a398e4b70f3"),
+	hexInt("e7159475a2c29b7443b29c7fa6e889d9"),
+	hexInt("d097f3bdfd2022b8845ad8f792aa5825"),
+	hexInt("a9f746462d870fdf8a65dc1f90e061e5"),
+	hexInt("70d869a156d2a1b890bb3df62baf32f7"),
+	hexInt("31be135f97d08fd981231505542fcfa6"),
+	hexInt("9aa508b5b7a84e1c677de54f3e99bc9"),
+	hexInt("5d6af8dedb81196699c329225ee604"),
+	hexInt("2216e584f5fa1ea926041bedfe98"),
+	hexInt("48a170391f7dc42444e8fa2"),
+}
+
+var (
+	// log2Sqrt10001 is 1 / log2(sqrt(1.0001)) as a Q64.64 number; tickLowOffset
+	// and tickHiOffset bound the error of the Q64.64 log_2 in Q128.128.
+	log2Sqrt10001, _ = new(big.Int).SetString("255738958999603826347141", 10)
+	tickLowOffset, _ = new(big.Int).SetString("3402992956809132418596140100660247210", 10)
+	tickHiOffset, _  = new(big.Int).SetString("291339464771989622907027621153398088495", 10)
+)
+
+func hexInt(s string) *big.Int {
+	x, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("fixedpoint: invalid constant " + s)
+	}
+	return x
+}
+
+// SqrtRatioAtTick returns sqrt(1.0001^tick) as a Q64.96 number, rounded up, as
+// TickMath.getSqrtRatioAtTick. Returns ErrInvalidInput when tick is outside
+// [MinTick, MaxTick].
+//
+// Example:
+//
+//	SqrtRatioAtTick(0)       // output: 79228162514264337593543950336 (Q96)
+//	SqrtRatioAtTick(MinTick) // output: 4295128739
+func SqrtRatioAtTick(tick int32) (*big.Int, error) {
+	if tick < MinTick || tick > MaxTick {
+		return nil, fmt.Errorf("cannot compute sqrt ratio at tick %d: %w", tick, safem.ErrInvalidInput)
+	}
+	absTick := tick
+	if absTick < 0 {
+		absTick = -absTick
+	}
+
+	ratio := new(big.Int)
+	if absTick&1 != 0 {
+		ratio.Set(tickRatios[0])
+	} else {
+		ratio.Set(Q128)
+	}
+	for i := 1; i < len(tickRatios); i++ {
+		if absTick&(1<<i) != 0 {
+			ratio.Mul(ratio, tickRatios[i]).Rsh(ratio, 128)
+		}
+	}
+	if tick > 0 {
+		ratio.Quo(maxUint256, ratio)
+	}
+
+	// Q128.128 to Q64.96, rounding up so that TickAtSqrtRatio of the result is tick
+	sqrtPriceX96 := new(big.Int).Rsh(ratio, 32)
+	if ratio.TrailingZeroBits() < 32 {
+		sqrtPriceX96.Add(sqrtPriceX96, big.NewInt(1))
+	}
+	return sqrtPriceX96, nil
+}
+
+// TickAtSqrtRatio returns the greatest tick whose sqrt ratio is at most
+// sqrtPriceX96, as TickMath.getTickAtSqrtRatio. Returns ErrInvalidInput when
+// sqrtPriceX96 is nil or outside [MinSqrtRatio, MaxSqrtRatio).
+//
+// Example:
+//
+//	TickAtSqrtRatio(MinSqrtRatio)                     // output: -887272
+//	TickAtSqrtRatio(new(big.Int).Sub(MaxSqrtRatio, 1)) // output: 887271
+func TickAtSqrtRatio(sqrtPriceX96 *big.Int) (int32, error) {
+	if sqrtPriceX96 == nil || sqrtPriceX96.Cmp(MinSqrtRatio) < 0 || sqrtPriceX96.Cmp(MaxSqrtRatio) >= 0 {
+		return 0, fmt.Errorf("cannot compute tick at sqrt ratio %v: %w", sqrtPriceX96, safem.ErrInvalidInput)
+	}
+	ratio := new(big.Int).Lsh(sqrtPriceX96, 32)
+
+	// normalise ratio to 128 significant bits in r
+	msb := ratio.BitLen() - 1
+	r := new(big.Int)
+	if msb >= 128 {
+		r.Rsh(ratio, uint(msb-127))
+	} else {
+		r.Lsh(ratio, uint(127-msb))
+	}
+
+	// log_2 of ratio as a signed Q64.64 number, 14 fractional bits by squaring
+	log2 := big.NewInt(int64(msb - 128))
+	log2.Lsh(log2, 64)
+	for bit := 63; bit >= 50; bit-- {
+		r.Mul(r, r).Rsh(r, 127)
+		f := r.Bit(128)
+		if f != 0 {
+			log2.Or(log2, new(big.Int).Lsh(big.NewInt(1), uint(bit)))
+		}
+		r.Rsh(r, f)
+	}
+
+	logSqrt10001 := new(big.Int).Mul(log2, log2Sqrt10001)
+	// big.Int.Rsh shifts arithmetically, as the contract's sar
+	tickLow := int32(new(big.Int).Rsh(new(big.Int).Sub(logSqrt10001, tickLowOffset), 128).Int64())
+	tickHi := int32(new(big.Int).Rsh(new(big.Int).Add(logSqrt10001, tickHiOffset), 128).Int64())
+	if tickLow == tickHi {
+		return tickLow, nil
+	}
+	hi, err := SqrtRatioAtTick(tickHi)
+	if err != nil {
+		return 0, err
+	}
+	if hi.Cmp(sqrtPriceX96) <= 0 {
+		return tickHi, nil
+	}
+	return tickLow, nil
+}
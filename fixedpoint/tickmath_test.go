@@ -0,0 +1,80 @@
+package fixedpoint
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/morpheum-labs/safem"
+)
+
+// Vectors from Uniswap v3-core test/TickMath.spec.ts.
+func TestSqrtRatioAtTick(t *testing.T) {
+	tests := []struct {
+		tick     int32
+		expected string
+	}{
+		{MinTick, "4295128739"},
+		{MinTick + 1, "4295343490"},
+		{0, "79228162514264337593543950336"},
+		{MaxTick - 1, "1461373636630004318706518188784493106690254656249"},
+		{MaxTick, "1461446703485210103287273052203988822378723970342"},
+	}
+	for _, tt := range tests {
+		got, err := SqrtRatioAtTick(tt.tick)
+		if err != nil || got.String() != tt.expected {
+			t.Errorf("SqrtRatioAtTick(%d): Expected %s, got %v %v", tt.tick, tt.expected, got, err)
+		}
+	}
+
+	if MinSqrtRatio.Cmp(bigInt(tests[0].expected)) != 0 || MaxSqrtRatio.Cmp(bigInt(tests[4].expected)) != 0 {
+		t.Errorf("Expected MinSqrtRatio and MaxSqrtRatio at MinTick and MaxTick, got %s and %s", MinSqrtRatio, MaxSqrtRatio)
+	}
+	for _, tick := range []int32{MinTick - 1, MaxTick + 1} {
+		if _, err := SqrtRatioAtTick(tick); !errors.Is(err, safem.ErrInvalidInput) {
+			t.Errorf("SqrtRatioAtTick(%d): Expected ErrInvalidInput, got %v", tick, err)
+		}
+	}
+}
+
+func TestTickAtSqrtRatio(t *testing.T) {
+	tests := []struct {
+		sqrtPriceX96 *big.Int
+		expected     int32
+	}{
+		{MinSqrtRatio, MinTick},
+		{big.NewInt(4295343490), MinTick + 1},
+		{Q96, 0},
+		{new(big.Int).Sub(Q96, big.NewInt(1)), -1},
+		{bigInt("1461373636630004318706518188784493106690254656249"), MaxTick - 1},
+		{new(big.Int).Sub(MaxSqrtRatio, big.NewInt(1)), MaxTick - 1},
+	}
+	for _, tt := range tests {
+		got, err := TickAtSqrtRatio(tt.sqrtPriceX96)
+		if err != nil || got != tt.expected {
+			t.Errorf("TickAtSqrtRatio(%s): Expected %d, got %d %v", tt.sqrtPriceX96, tt.expected, got, err)
+		}
+	}
+
+	for _, x := range []*big.Int{nil, new(big.Int).Sub(MinSqrtRatio, big.NewInt(1)), MaxSqrtRatio} {
+		if _, err := TickAtSqrtRatio(x); !errors.Is(err, safem.ErrInvalidInput) {
+			t.Errorf("TickAtSqrtRatio(%v): Expected ErrInvalidInput, got %v", x, err)
+		}
+	}
+}
+
+func TestTickRoundTrip(t *testing.T) {
+	for tick := int32(MinTick + 1); tick < MaxTick; tick += 997 {
+		sqrtPriceX96, err := SqrtRatioAtTick(tick)
+		if err != nil {
+			t.Fatalf("SqrtRatioAtTick(%d): %v", tick, err)
+		}
+		if got, err := TickAtSqrtRatio(sqrtPriceX96); err != nil || got != tick {
+			t.Errorf("TickAtSqrtRatio(SqrtRatioAtTick(%d)): Expected %d, got %d %v", tick, tick, got, err)
+		}
+		below := new(big.Int).Sub(sqrtPriceX96, big.NewInt(1))
+		if got, err := TickAtSqrtRatio(below); err != nil || got != tick-1 {
+			t.Errorf("TickAtSqrtRatio(SqrtRatioAtTick(%d) - 1): Expected %d, got %d %v", tick, tick-1, got, err)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package safem
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// FloatMode selects how a float64 amount is read before it is scaled to token
+// units.
+type FloatMode uint8
+
+const (
+	// FloatBinary reads the float64 as its binary value, so 0.1 is
+	// 0.1000000000000000055511151231257827021181583404541015625, and keeps the
+	// arithmetic of each function as it was. It is the mode of BigIntBaseX,
+	// EtherToWei and ProcessFloatToDecimalAdjustment.
+	FloatBinary FloatMode = iota
+
+	// FloatShortest reads the float64 as the shortest decimal that converts back
+	// to the same float64, as strconv.FormatFloat(f, 'g', -1, 64) prints it, so 0.1
+	// is 0.1. The decimal is then scaled exactly.
+	FloatShortest
+)
+
+// String returns the name of the mode, e.g. "shortest".
+func (m FloatMode) String() string {
+	switch m {
+	case FloatBinary:
+		return "binary"
+	case FloatShortest:
+		return "shortest"
+	}
+	return fmt.Sprintf("FloatMode(%d)", uint8(m))
+}
+
+// FloatConversion converts float64 amounts to token units in a FloatMode.
+//
+// USAGE INTENTION:
+// - Use FloatConversionV2 for new code: 0.1 ETH is exactly 100000000000000000 wei
+// - FloatConversionV1 is the original API, kept for callers that depend on its results
+// - Use when amounts arrive as float64 from JSON, configuration or user input
+//
+// TRADE-OFFS:
+// - FloatShortest matches what a user typed and what the float64 prints as
+// - FloatShortest formats the float64 first, which costs a few hundred ns more
+// - Amounts with digits below one token unit are truncated, or rejected by EtherToWei
+//
+// Example:
+//
+//	FloatConversionV1.BigIntBaseX(1.005, 18) // 1004999999999999872
+//	FloatConversionV2.BigIntBaseX(1.005, 18) // 1005000000000000000
+type FloatConversion struct {
+	Mode FloatMode
+}
+
+var (
+	// FloatConversionV1 is the original float conversion API: BigIntBaseX,
+	// EtherToWei and ProcessFloatToDecimalAdjustment.
+	FloatConversionV1 = FloatConversion{Mode: FloatBinary}
+
+	// FloatConversionV2 reads floats as their shortest decimal representation.
+	FloatConversionV2 = FloatConversion{Mode: FloatShortest}
+)
+
+// BigIntBaseX converts f to token units with y decimals, truncating towards
+// zero. Negative values, NaN and infinities are 0.
+func (c FloatConversion) BigIntBaseX(f float64, y int64) *big.Int {
+	if c.Mode != FloatShortest {
+		return BigIntBaseX(f, y)
+	}
+	v, _, ok := shortestToUnits(f, y)
+	if !ok || v.Sign() < 0 {
+		return big.NewInt(0)
+	}
+	return v
+}
+
+// EtherToWei converts an amount of ether to wei.
+//
+// In FloatShortest mode it returns ErrPrecisionLoss when the shortest
+// representation of ether has digits below one wei, e.g. 1e-19, and
+// ErrNegativeInput and ErrInvalidInput for negative values, NaN and infinities.
+func (c FloatConversion) EtherToWei(ether float64) (*big.Int, error) {
+	if c.Mode != FloatShortest {
+		return EtherToWei(ether)
+	}
+	if ether < 0 {
+		return nil, fmt.Errorf("cannot convert %g ether to wei: %w", ether, ErrNegativeInput)
+	}
+	v, exact, ok := shortestToUnits(ether, 18)
+	if !ok {
+		return nil, fmt.Errorf("cannot convert %g ether to wei: %w", ether, ErrInvalidInput)
+	}
+	if !exact {
+		return nil, fmt.Errorf("cannot convert %g ether to wei: %w", ether, ErrPrecisionLoss)
+	}
+	return v, nil
+}
+
+// ProcessFloatToDecimalAdjustment converts stateAmt to token units with
+// decimals decimals, truncating towards zero. Negative values, NaN and
+// infinities are 0.
+func (c FloatConversion) ProcessFloatToDecimalAdjustment(decimals int, stateAmt float64) *big.Int {
+	if c.Mode != FloatShortest {
+		return ProcessFloatToDecimalAdjustment(decimals, stateAmt)
+	}
+	return c.BigIntBaseX(stateAmt, int64(decimals))
+}
+
+// shortestToUnits returns the shortest decimal of f times 10^y, truncated
+// towards zero, and whether no digits were dropped. ok is false for NaN and
+// infinities.
+func shortestToUnits(f float64, y int64) (v *big.Int, exact bool, ok bool) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, false, false
+	}
+	d := shortestFromFloat64(f).Shift(int32(y))
+	return d.Truncate(0).BigInt(), d.IsInteger(), true
+}
+
+// shortestFromFloat64 returns the shortest decimal that converts back to f,
+// using the same roundShortest algorithm as strconv.
+func shortestFromFloat64(f float64) Decimal {
+	if f == 0 {
+		return New(0, 0)
+	}
+	return newFromFloat(f, math.Float64bits(f), &float64info)
+}
@@ -0,0 +1,136 @@
+package safem
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// floatGolden holds floats whose binary value differs from the decimal they
+// print as, with their token units in both modes.
+var floatGolden = []struct {
+	f        float64
+	decimals int64
+	binary   string
+	shortest string
+}{
+	{0.1, 18, "100000000000000000", "100000000000000000"},
+	{0.30000000000000004, 18, "300000000000000064", "300000000000000040"},
+	{1.005, 18, "1004999999999999872", "1005000000000000000"},
+	{2.675, 18, "2675000000000000000", "2675000000000000000"},
+	{1.15, 14, "114999999999999", "115000000000000"},
+	{1.15, 18, "1149999999999999872", "1150000000000000000"},
+	{4.35, 14, "434999999999999", "435000000000000"},
+	{0.07, 18, "70000000000000008", "70000000000000000"},
+	{8.2, 14, "819999999999999", "820000000000000"},
+	{0.57, 14, "56999999999999", "57000000000000"},
+	{19.99, 14, "1998999999999999", "1999000000000000"},
+	{5.42323, 18, "5423230000000000000", "5423230000000000000"},
+	{299792.458, 18, "299792457999999976865792", "299792458000000000000000"},
+	{1.0 / 3, 18, "333333333333333312", "333333333333333300"},
+	{2.0 / 3, 18, "666666666666666624", "666666666666666600"},
+	{1e21, 18, "999999999999999939709166371603178586112", "1000000000000000000000000000000000000000"},
+	{9007199254740993, 18, "9007199254740992000000000000000000", "9007199254740992000000000000000000"},
+	{1e-18, 18, "1", "1"},
+	{1.5e-18, 18, "1", "1"},
+	{5e-324, 18, "0", "0"},
+	{0, 18, "0", "0"},
+	{-1.5, 18, "0", "0"},
+}
+
+func TestFloatConversionGolden(t *testing.T) {
+	for _, tt := range floatGolden {
+		if got := FloatConversionV1.BigIntBaseX(tt.f, tt.decimals).String(); got != tt.binary {
+			t.Errorf("V1.BigIntBaseX(%v, %d): Expected %s, got %s", tt.f, tt.decimals, tt.binary, got)
+		}
+		if got := BigIntBaseX(tt.f, tt.decimals).String(); got != tt.binary {
+			t.Errorf("BigIntBaseX(%v, %d): Expected %s, got %s", tt.f, tt.decimals, tt.binary, got)
+		}
+		if got := FloatConversionV2.BigIntBaseX(tt.f, tt.decimals).String(); got != tt.shortest {
+			t.Errorf("V2.BigIntBaseX(%v, %d): Expected %s, got %s", tt.f, tt.decimals, tt.shortest, got)
+		}
+		if got := FloatConversionV2.ProcessFloatToDecimalAdjustment(int(tt.decimals), tt.f).String(); got != tt.shortest {
+			t.Errorf("V2.ProcessFloatToDecimalAdjustment(%d, %v): Expected %s, got %s", tt.decimals, tt.f, tt.shortest, got)
+		}
+	}
+}
+
+func TestFloatConversionEtherToWei(t *testing.T) {
+	tests := []struct {
+		ether    float64
+		binary   string
+		shortest string
+		err      error
+	}{
+		{0.1, "100000000000000000", "100000000000000000", nil},
+		{1.005, "1004999999999999872", "1005000000000000000", nil},
+		{0.07, "70000000000000008", "70000000000000000", nil},
+		{4.35, "4349999999999999488", "4350000000000000000", nil},
+		{1e21, "999999999999999939709166371603178586112", "1000000000000000000000000000000000000000", nil},
+		{1e-18, "1", "1", nil},
+		{1.5e-18, "", "", ErrPrecisionLoss},
+		{-1, "", "", ErrNegativeInput},
+		{math.NaN(), "", "", ErrInvalidInput},
+		{math.Inf(1), "", "", ErrInvalidInput},
+	}
+
+	for _, tt := range tests {
+		wei, err := FloatConversionV1.EtherToWei(tt.ether)
+		if tt.binary != "" && (err != nil || wei.String() != tt.binary) {
+			t.Errorf("V1.EtherToWei(%v): Expected %s, got %v %v", tt.ether, tt.binary, wei, err)
+		}
+		if tt.binary == "" && err == nil {
+			t.Errorf("V1.EtherToWei(%v): Expected error, got %v", tt.ether, wei)
+		}
+
+		wei, err = FloatConversionV2.EtherToWei(tt.ether)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("V2.EtherToWei(%v): Expected error %v, got %v", tt.ether, tt.err, err)
+			continue
+		}
+		if err == nil && wei.String() != tt.shortest {
+			t.Errorf("V2.EtherToWei(%v): Expected %s, got %s", tt.ether, tt.shortest, wei.String())
+		}
+	}
+}
+
+func TestFloatConversionInvalid(t *testing.T) {
+	for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1), -0.1} {
+		if got := FloatConversionV2.BigIntBaseX(f, 18); got.Sign() != 0 {
+			t.Errorf("BigIntBaseX(%v): Expected 0, got %s", f, got.String())
+		}
+	}
+	if got := (FloatMode(9)).String(); got != "FloatMode(9)" {
+		t.Errorf("Expected FloatMode(9), got %s", got)
+	}
+}
+
+func TestShortestFromFloat64MatchesStrconv(t *testing.T) {
+	check := func(f float64) {
+		expected := strconv.FormatFloat(f, 'g', -1, 64)
+		want, err := NewFromString(expected)
+		if err != nil {
+			t.Fatalf("cannot parse %s: %v", expected, err)
+		}
+		if got := shortestFromFloat64(f); got.String() != want.String() {
+			t.Errorf("Expected %s, got %s", expected, got.String())
+		}
+	}
+
+	for _, f := range []float64{
+		0, 0.1, 0.3, 1.005, -2.5, 1e23, 5e-324, math.MaxFloat64, 2.2250738585072014e-308,
+		2.225073858507201e-308, 9007199254740993, 123456789.123456789,
+	} {
+		check(f)
+	}
+
+	r := rand.New(rand.NewSource(45))
+	for i := 0; i < 20000; i++ {
+		if f := math.Float64frombits(r.Uint64()); !math.IsNaN(f) && !math.IsInf(f, 0) {
+			check(f)
+		}
+		check(float64(r.Int63n(100000000)) / 10000)
+	}
+}
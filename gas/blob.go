@@ -0,0 +1,117 @@
+package gas
+
+import (
+	"fmt"
+
+	"github.com/morpheum-labs/safem"
+)
+
+// GasPerBlob is the blob gas used by one blob, 2^17.
+const GasPerBlob = 1 << 17
+
+// BlobConfig holds the EIP-4844 blob fee parameters of a fork.
+type BlobConfig struct {
+	// TargetBlobGasPerBlock is the blob gas above which the blob base fee rises.
+	TargetBlobGasPerBlock uint64
+
+	// MinBaseFeePerBlobGas is the blob base fee at zero excess blob gas.
+	MinBaseFeePerBlobGas uint64
+
+	// BaseFeeUpdateFraction controls how fast the blob base fee follows the excess
+	// blob gas.
+	BaseFeeUpdateFraction uint64
+}
+
+var (
+	// Cancun is the blob fee configuration introduced by EIP-4844: 3 target blobs.
+	Cancun = BlobConfig{
+		TargetBlobGasPerBlock: 3 * GasPerBlob,
+		MinBaseFeePerBlobGas:  1,
+		BaseFeeUpdateFraction: 3338477,
+	}
+
+	// Prague is the blob fee configuration of EIP-7691: 6 target blobs.
+	Prague = BlobConfig{
+		TargetBlobGasPerBlock: 6 * GasPerBlob,
+		MinBaseFeePerBlobGas:  1,
+		BaseFeeUpdateFraction: 5007716,
+	}
+)
+
+// FakeExponential approximates factor * e^(numerator/denominator) using the
+// Taylor expansion of EIP-4844. The result is bit-exact with the
+// specification's fake_exponential:
+//
+//	i = 1
+//	output = 0
+//	numerator_accum = factor * denominator
+//	while numerator_accum > 0:
+//	    output += numerator_accum
+//	    numerator_accum = (numerator_accum * numerator) // (denominator * i)
+//	    i += 1
+//	return output // denominator
+//
+// Returns ErrInvalidInput for nil arguments, ErrNegativeInput for negative ones
+// and ErrDivisionByZero when denominator is zero.
+//
+// Example:
+//
+//	FakeExponential(one, NewBigIntFromInt64(50000000), NewBigIntFromInt64(2225652)) // output: 5709098764
+func FakeExponential(factor, numerator, denominator *safem.BigInt) (*safem.BigInt, error) {
+	for _, arg := range []struct {
+		name string
+		x    *safem.BigInt
+	}{{"factor", factor}, {"numerator", numerator}, {"denominator", denominator}} {
+		if arg.x == nil || arg.x.Int == nil {
+			return nil, fmt.Errorf("cannot compute fake exponential: %s is nil: %w", arg.name, safem.ErrInvalidInput)
+		}
+		if arg.x.Sign() < 0 {
+			return nil, fmt.Errorf("cannot compute fake exponential: %s is negative: %w", arg.name, safem.ErrNegativeInput)
+		}
+	}
+	if denominator.Sign() == 0 {
+		return nil, fmt.Errorf("cannot compute fake exponential: %w", safem.ErrDivisionByZero)
+	}
+
+	output := safem.NewBigIntFromInt64(0)
+	accum := new(safem.BigInt).Mul(factor, denominator)
+	var divisor safem.BigInt
+	for i := int64(1); accum.Sign() > 0; i++ {
+		output.Add(output, accum)
+		accum.Mul(accum, numerator)
+		divisor.Mul(denominator, safem.NewBigIntFromInt64(i))
+		safem.NilAsZero.Quo(accum, accum, &divisor)
+	}
+	return safem.NilAsZero.Quo(output, output, denominator), nil
+}
+
+// BlobBaseFee returns the base fee per blob gas of a block with the given excess
+// blob gas:
+//
+//	FakeExponential(MinBaseFeePerBlobGas, excessBlobGas, BaseFeeUpdateFraction)
+//
+// Returns ErrDivisionByZero when BaseFeeUpdateFraction is zero.
+func (c BlobConfig) BlobBaseFee(excessBlobGas uint64) (*safem.BigInt, error) {
+	return FakeExponential(
+		safem.NewBigIntFromUint64(c.MinBaseFeePerBlobGas),
+		safem.NewBigIntFromUint64(excessBlobGas),
+		safem.NewBigIntFromUint64(c.BaseFeeUpdateFraction),
+	)
+}
+
+// NextExcessBlobGas returns the excess blob gas of the block following a parent
+// with the given excess blob gas and blob gas used:
+// max(parentExcessBlobGas + parentBlobGasUsed - TargetBlobGasPerBlock, 0).
+func (c BlobConfig) NextExcessBlobGas(parentExcessBlobGas, parentBlobGasUsed uint64) uint64 {
+	total := parentExcessBlobGas + parentBlobGasUsed
+	if total < c.TargetBlobGasPerBlock {
+		return 0
+	}
+	return total - c.TargetBlobGasPerBlock
+}
+
+// BlobFee returns blobs * GasPerBlob * blobBaseFee, the blob fee paid by a
+// transaction carrying the given number of blobs. A nil blob base fee is zero.
+func BlobFee(blobs uint64, blobBaseFee *safem.BigInt) *safem.BigInt {
+	return Cost(blobs*GasPerBlob, blobBaseFee)
+}
@@ -0,0 +1,220 @@
+// Package gas computes Ethereum transaction fees on safem.BigInt: the effective
+// gas price and cost of legacy and EIP-1559 transactions, the base fee of the
+// next block (EIP-1559) and the blob base fee (EIP-4844).
+//
+// All amounts are in wei. Inputs are never modified; every result is a new value.
+//
+// Example:
+//
+//	baseFee := safem.NewBigIntFromInt64(30_000_000_000) // 30 gwei
+//	price, _ := gas.EffectiveGasPrice(baseFee, maxFee, maxPriorityFee)
+//	cost := gas.TotalCost(21000, price, value)
+//	next, _ := gas.Ethereum.NextBaseFee(baseFee, gasUsed, gasLimit)
+package gas
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/morpheum-labs/safem"
+)
+
+var (
+	ErrFeeCapTooLow    = errors.New("max fee per gas less than base fee")
+	ErrTipAboveFeeCap  = errors.New("max priority fee per gas higher than max fee per gas")
+	ErrGasPriceTooLow  = errors.New("gas price less than base fee")
+	ErrGasUsedTooLarge = errors.New("gas used higher than gas limit")
+)
+
+// value returns x, or ErrInvalidInput when x is nil, and ErrNegativeInput when x
+// is negative.
+func value(name string, x *safem.BigInt) (*safem.BigInt, error) {
+	if x == nil || x.Int == nil {
+		return nil, fmt.Errorf("cannot compute fee: %s is nil: %w", name, safem.ErrInvalidInput)
+	}
+	if x.Sign() < 0 {
+		return nil, fmt.Errorf("cannot compute fee: %s is negative: %w", name, safem.ErrNegativeInput)
+	}
+	return x, nil
+}
+
+func fromUint64(x uint64) *safem.BigInt {
+	return safem.NewBigIntFromUint64(x)
+}
+
+// EffectiveGasPrice returns the price per gas paid by an EIP-1559 transaction,
+// min(maxFeePerGas, baseFee + maxPriorityFeePerGas).
+//
+// A nil baseFee means the block has no base fee, as before London, and the
+// result is maxFeePerGas. Returns ErrTipAboveFeeCap when the priority fee is
+// higher than the fee cap and ErrFeeCapTooLow when the fee cap is below the base
+// fee, since such a transaction cannot be included.
+//
+// Example:
+//
+//	// base fee 10 gwei, max fee 30 gwei, tip 2 gwei
+//	EffectiveGasPrice(baseFee, maxFee, tip) // output: 12000000000
+func EffectiveGasPrice(baseFee, maxFeePerGas, maxPriorityFeePerGas *safem.BigInt) (*safem.BigInt, error) {
+	feeCap, err := value("max fee per gas", maxFeePerGas)
+	if err != nil {
+		return nil, err
+	}
+	tipCap, err := value("max priority fee per gas", maxPriorityFeePerGas)
+	if err != nil {
+		return nil, err
+	}
+	if tipCap.Cmp(feeCap) > 0 {
+		return nil, fmt.Errorf("cannot compute fee: tip %s, fee cap %s: %w", tipCap, feeCap, ErrTipAboveFeeCap)
+	}
+	if baseFee == nil || baseFee.Int == nil {
+		return safem.NewBigInt(feeCap.Int), nil
+	}
+	if _, err := value("base fee", baseFee); err != nil {
+		return nil, err
+	}
+	if feeCap.Cmp(baseFee) < 0 {
+		return nil, fmt.Errorf("cannot compute fee: fee cap %s, base fee %s: %w", feeCap, baseFee, ErrFeeCapTooLow)
+	}
+
+	price := new(safem.BigInt).Add(baseFee, tipCap)
+	if price.Cmp(feeCap) > 0 {
+		price.Set(feeCap.Int)
+	}
+	return price, nil
+}
+
+// EffectiveTip returns the part of the effective gas price of an EIP-1559
+// transaction that goes to the block producer, min(maxPriorityFeePerGas,
+// maxFeePerGas - baseFee). Errors are those of EffectiveGasPrice.
+func EffectiveTip(baseFee, maxFeePerGas, maxPriorityFeePerGas *safem.BigInt) (*safem.BigInt, error) {
+	price, err := EffectiveGasPrice(baseFee, maxFeePerGas, maxPriorityFeePerGas)
+	if err != nil {
+		return nil, err
+	}
+	return price.Sub(price, baseFee), nil
+}
+
+// LegacyGasPrice returns the price per gas paid by a legacy or EIP-2930
+// transaction, which is its gas price whatever the base fee.
+//
+// A nil baseFee means the block has no base fee. Returns ErrGasPriceTooLow when
+// the gas price is below the base fee.
+func LegacyGasPrice(baseFee, gasPrice *safem.BigInt) (*safem.BigInt, error) {
+	price, err := value("gas price", gasPrice)
+	if err != nil {
+		return nil, err
+	}
+	if baseFee != nil && baseFee.Int != nil {
+		if _, err := value("base fee", baseFee); err != nil {
+			return nil, err
+		}
+		if price.Cmp(baseFee) < 0 {
+			return nil, fmt.Errorf("cannot compute fee: gas price %s, base fee %s: %w", price, baseFee, ErrGasPriceTooLow)
+		}
+	}
+	return safem.NewBigInt(price.Int), nil
+}
+
+// Cost returns gasUsed * gasPrice, the fee paid for the gas. A nil gas price
+// is zero.
+func Cost(gasUsed uint64, gasPrice *safem.BigInt) *safem.BigInt {
+	return new(safem.BigInt).Mul(fromUint64(gasUsed), gasPrice)
+}
+
+// TotalCost returns gasUsed * gasPrice + value, the amount taken from the sender
+// of a transaction. A nil gas price or value is zero.
+//
+// Example:
+//
+//	// 21000 gas at 12 gwei, sending 1 ether
+//	TotalCost(21000, price, oneEther) // output: 1000252000000000000
+func TotalCost(gasUsed uint64, gasPrice, value *safem.BigInt) *safem.BigInt {
+	cost := Cost(gasUsed, gasPrice)
+	return cost.Add(cost, value)
+}
+
+// MaxCost returns gasLimit * maxFeePerGas + value, the balance a sender needs for
+// a transaction to be valid. For legacy transactions pass the gas price as
+// maxFeePerGas.
+func MaxCost(gasLimit uint64, maxFeePerGas, value *safem.BigInt) *safem.BigInt {
+	return TotalCost(gasLimit, maxFeePerGas, value)
+}
+
+// Refund returns (gasLimit - gasUsed) * gasPrice, the amount returned to the
+// sender for unused gas after paying gasLimit * gasPrice up front. gasUsed is
+// the gas used after any refund counter was applied.
+//
+// Returns ErrGasUsedTooLarge when gasUsed is higher than gasLimit.
+func Refund(gasLimit, gasUsed uint64, gasPrice *safem.BigInt) (*safem.BigInt, error) {
+	if gasUsed > gasLimit {
+		return nil, fmt.Errorf("cannot compute refund: gas used %d, gas limit %d: %w", gasUsed, gasLimit, ErrGasUsedTooLarge)
+	}
+	return Cost(gasLimit-gasUsed, gasPrice), nil
+}
+
+// Config holds the EIP-1559 parameters of a chain.
+type Config struct {
+	// ElasticityMultiplier is the ratio of the gas limit to the gas target.
+	ElasticityMultiplier uint64
+
+	// BaseFeeChangeDenominator bounds the change of the base fee between blocks
+	// to 1/BaseFeeChangeDenominator.
+	BaseFeeChangeDenominator uint64
+}
+
+// Ethereum is the EIP-1559 configuration of Ethereum mainnet.
+var Ethereum = Config{
+	ElasticityMultiplier:     2,
+	BaseFeeChangeDenominator: 8,
+}
+
+// NextBaseFee returns the base fee of the block following a parent block with the
+// given base fee, gas used and gas limit, per EIP-1559:
+//
+//	target = parentGasLimit / ElasticityMultiplier
+//	used > target: parentBaseFee + max(parentBaseFee * (used - target) / target / BaseFeeChangeDenominator, 1)
+//	used < target: parentBaseFee - parentBaseFee * (target - used) / target / BaseFeeChangeDenominator
+//
+// Divisions truncate, as in the specification. Returns ErrInvalidInput when a
+// parameter of c is zero or the gas target is zero.
+//
+// Example:
+//
+//	// 1 gwei, 11M of a 20M limit
+//	Ethereum.NextBaseFee(parentBaseFee, 11_000_000, 20_000_000) // output: 1012500000
+func (c Config) NextBaseFee(parentBaseFee *safem.BigInt, parentGasUsed, parentGasLimit uint64) (*safem.BigInt, error) {
+	baseFee, err := value("parent base fee", parentBaseFee)
+	if err != nil {
+		return nil, err
+	}
+	if c.ElasticityMultiplier == 0 || c.BaseFeeChangeDenominator == 0 {
+		return nil, fmt.Errorf("cannot compute base fee: elasticity %d, denominator %d: %w", c.ElasticityMultiplier, c.BaseFeeChangeDenominator, safem.ErrInvalidInput)
+	}
+	target := parentGasLimit / c.ElasticityMultiplier
+	if target == 0 {
+		return nil, fmt.Errorf("cannot compute base fee: gas limit %d has no target: %w", parentGasLimit, safem.ErrInvalidInput)
+	}
+
+	next := safem.NewBigInt(baseFee.Int)
+	if parentGasUsed == target {
+		return next, nil
+	}
+
+	var delta safem.BigInt
+	if parentGasUsed > target {
+		delta.Mul(baseFee, fromUint64(parentGasUsed-target))
+	} else {
+		delta.Mul(baseFee, fromUint64(target-parentGasUsed))
+	}
+	safem.NilAsZero.Quo(&delta, &delta, fromUint64(target))
+	safem.NilAsZero.Quo(&delta, &delta, fromUint64(c.BaseFeeChangeDenominator))
+
+	if parentGasUsed > target {
+		if delta.Sign() == 0 {
+			delta.SetInt64(1)
+		}
+		return next.Add(next, &delta), nil
+	}
+	// delta is at most baseFee / BaseFeeChangeDenominator, so the result is not negative
+	return next.Sub(next, &delta), nil
+}
@@ -0,0 +1,245 @@
+package gas
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/morpheum-labs/safem"
+)
+
+func gwei(x int64) *safem.BigInt {
+	return safem.NewBigIntFromInt64(x * 1_000_000_000)
+}
+
+func TestEffectiveGasPrice(t *testing.T) {
+	tests := []struct {
+		baseFee, feeCap, tipCap *safem.BigInt
+		price, tip              string
+		err                     error
+	}{
+		{gwei(10), gwei(30), gwei(2), "12000000000", "2000000000", nil},
+		{gwei(10), gwei(11), gwei(2), "11000000000", "1000000000", nil},
+		{gwei(10), gwei(10), gwei(0), "10000000000", "0", nil},
+		{gwei(10), gwei(10), gwei(10), "10000000000", "0", nil},
+		{nil, gwei(30), gwei(2), "30000000000", "30000000000", nil},
+		{gwei(10), gwei(9), gwei(1), "", "", ErrFeeCapTooLow},
+		{gwei(10), gwei(30), gwei(31), "", "", ErrTipAboveFeeCap},
+		{gwei(10), nil, gwei(1), "", "", safem.ErrInvalidInput},
+		{gwei(10), gwei(30), nil, "", "", safem.ErrInvalidInput},
+		{gwei(-1), gwei(30), gwei(1), "", "", safem.ErrNegativeInput},
+	}
+
+	for _, tt := range tests {
+		price, err := EffectiveGasPrice(tt.baseFee, tt.feeCap, tt.tipCap)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("Expected error %v, got %v", tt.err, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if price.String() != tt.price {
+			t.Errorf("Expected price %s, got %s", tt.price, price.String())
+		}
+		tip, err := EffectiveTip(tt.baseFee, tt.feeCap, tt.tipCap)
+		if err != nil || tip.String() != tt.tip {
+			t.Errorf("Expected tip %s, got %s %v", tt.tip, tip.String(), err)
+		}
+	}
+
+	// inputs are not modified
+	baseFee, feeCap := gwei(10), gwei(11)
+	price, _ := EffectiveGasPrice(baseFee, feeCap, gwei(5))
+	price.SetInt64(0)
+	if feeCap.String() != "11000000000" || baseFee.String() != "10000000000" {
+		t.Errorf("Expected inputs unchanged, got %s %s", baseFee.String(), feeCap.String())
+	}
+}
+
+func TestLegacyGasPrice(t *testing.T) {
+	price, err := LegacyGasPrice(gwei(10), gwei(20))
+	if err != nil || price.String() != "20000000000" {
+		t.Errorf("Expected 20000000000, got %s %v", price.String(), err)
+	}
+	price, err = LegacyGasPrice(nil, gwei(1))
+	if err != nil || price.String() != "1000000000" {
+		t.Errorf("Expected 1000000000, got %s %v", price.String(), err)
+	}
+	if _, err := LegacyGasPrice(gwei(10), gwei(9)); !errors.Is(err, ErrGasPriceTooLow) {
+		t.Errorf("Expected ErrGasPriceTooLow, got %v", err)
+	}
+}
+
+func TestCosts(t *testing.T) {
+	price := gwei(12)
+	oneEther := safem.NewBigIntFromInt64(1_000_000_000_000_000_000)
+
+	if got := Cost(21000, price).String(); got != "252000000000000" {
+		t.Errorf("Expected 252000000000000, got %s", got)
+	}
+	if got := TotalCost(21000, price, oneEther).String(); got != "1000252000000000000" {
+		t.Errorf("Expected 1000252000000000000, got %s", got)
+	}
+	if got := TotalCost(21000, price, nil).String(); got != "252000000000000" {
+		t.Errorf("Expected 252000000000000, got %s", got)
+	}
+	if got := MaxCost(100000, gwei(30), oneEther).String(); got != "1003000000000000000" {
+		t.Errorf("Expected 1003000000000000000, got %s", got)
+	}
+
+	refund, err := Refund(100000, 21000, price)
+	if err != nil || refund.String() != "948000000000000" {
+		t.Errorf("Expected 948000000000000, got %s %v", refund.String(), err)
+	}
+	if _, err := Refund(21000, 21001, price); !errors.Is(err, ErrGasUsedTooLarge) {
+		t.Errorf("Expected ErrGasUsedTooLarge, got %v", err)
+	}
+}
+
+func TestNextBaseFee(t *testing.T) {
+	tests := []struct {
+		config        Config
+		parentBaseFee int64
+		gasLimit      uint64
+		gasUsed       uint64
+		expected      string
+	}{
+		// go-ethereum's TestCalcBaseFee
+		{Ethereum, 1000000000, 20000000, 10000000, "1000000000"},
+		{Ethereum, 1000000000, 20000000, 9000000, "987500000"},
+		{Ethereum, 1000000000, 20000000, 11000000, "1012500000"},
+
+		{Ethereum, 1000000000, 20000000, 0, "875000000"},
+		{Ethereum, 1000000000, 20000000, 20000000, "1125000000"},
+		{Ethereum, 7, 30000000, 15000001, "8"}, // increase of at least 1
+		{Ethereum, 7, 30000000, 14999999, "7"}, // decrease rounds to 0
+		{Ethereum, 0, 30000000, 30000000, "1"}, // recovers from zero
+		{Ethereum, 0, 30000000, 0, "0"},        // never negative
+		{Config{4, 50}, 1000000000, 40000000, 40000000, "1060000000"},
+		{Config{4, 50}, 1000000000, 40000000, 0, "980000000"},
+	}
+
+	for _, tt := range tests {
+		next, err := tt.config.NextBaseFee(safem.NewBigIntFromInt64(tt.parentBaseFee), tt.gasUsed, tt.gasLimit)
+		if err != nil {
+			t.Errorf("Unexpected error %v", err)
+			continue
+		}
+		if next.String() != tt.expected {
+			t.Errorf("%d/%d at %d: Expected %s, got %s", tt.gasUsed, tt.gasLimit, tt.parentBaseFee, tt.expected, next.String())
+		}
+	}
+
+	invalid := []struct {
+		config   Config
+		baseFee  *safem.BigInt
+		gasLimit uint64
+	}{
+		{Config{0, 8}, gwei(1), 30000000},
+		{Config{2, 0}, gwei(1), 30000000},
+		{Ethereum, gwei(1), 1},
+		{Ethereum, nil, 30000000},
+	}
+	for _, tt := range invalid {
+		if _, err := tt.config.NextBaseFee(tt.baseFee, 0, tt.gasLimit); !errors.Is(err, safem.ErrInvalidInput) {
+			t.Errorf("Expected ErrInvalidInput, got %v", err)
+		}
+	}
+}
+
+func TestFakeExponential(t *testing.T) {
+	// test vectors of the consensus specs and go-ethereum
+	tests := []struct {
+		factor, numerator, denominator int64
+		expected                       string
+	}{
+		{1, 2, 1, "6"}, // approximately 7.389
+		{1, 4, 2, "6"},
+		{1, 3, 1, "16"}, // approximately 20.09
+		{1, 6, 2, "18"},
+		{1, 4, 1, "49"}, // approximately 54.60
+		{1, 8, 2, "50"},
+		{10, 8, 2, "542"}, // approximately 540.598
+		{11, 8, 2, "596"}, // approximately 600.58
+		{1, 5, 1, "136"},  // approximately 148.4
+		{1, 5, 2, "11"},   // approximately 12.18
+		{2, 5, 2, "23"},   // approximately 24.36
+		{1, 50000000, 2225652, "5709098764"},
+		{1, 0, 1, "1"},
+		{38493, 0, 1000, "38493"},
+		{0, 1000, 1, "0"},
+	}
+
+	for _, tt := range tests {
+		got, err := FakeExponential(safem.NewBigIntFromInt64(tt.factor), safem.NewBigIntFromInt64(tt.numerator), safem.NewBigIntFromInt64(tt.denominator))
+		if err != nil {
+			t.Errorf("Unexpected error %v", err)
+			continue
+		}
+		if got.String() != tt.expected {
+			t.Errorf("fake_exponential(%d, %d, %d): Expected %s, got %s", tt.factor, tt.numerator, tt.denominator, tt.expected, got.String())
+		}
+	}
+
+	one := safem.NewBigIntFromInt64(1)
+	if _, err := FakeExponential(one, one, safem.NewBigIntFromInt64(0)); !errors.Is(err, safem.ErrDivisionByZero) {
+		t.Errorf("Expected ErrDivisionByZero, got %v", err)
+	}
+	if _, err := FakeExponential(one, safem.NewBigIntFromInt64(-1), one); !errors.Is(err, safem.ErrNegativeInput) {
+		t.Errorf("Expected ErrNegativeInput, got %v", err)
+	}
+	if _, err := FakeExponential(nil, one, one); !errors.Is(err, safem.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestBlobBaseFee(t *testing.T) {
+	tests := []struct {
+		config   BlobConfig
+		excess   uint64
+		expected string
+	}{
+		{Cancun, 0, "1"},
+		{Cancun, 3 * GasPerBlob * 10, "3"},
+		{Cancun, 10000000, "19"},
+		{Cancun, 100000000, "10203769476395"},
+		{Prague, 0, "1"},
+		{Prague, 100000000, "470442149"},
+	}
+
+	for _, tt := range tests {
+		fee, err := tt.config.BlobBaseFee(tt.excess)
+		if err != nil || fee.String() != tt.expected {
+			t.Errorf("excess %d: Expected %s, got %s %v", tt.excess, tt.expected, fee.String(), err)
+		}
+	}
+
+	if _, err := (BlobConfig{}).BlobBaseFee(0); !errors.Is(err, safem.ErrDivisionByZero) {
+		t.Errorf("Expected ErrDivisionByZero, got %v", err)
+	}
+
+	if got := BlobFee(2, safem.NewBigIntFromInt64(19)).String(); got != "4980736" {
+		t.Errorf("Expected 4980736, got %s", got)
+	}
+}
+
+func TestNextExcessBlobGas(t *testing.T) {
+	tests := []struct {
+		config         BlobConfig
+		excess, used   uint64
+		expectedExcess uint64
+	}{
+		{Cancun, 0, 0, 0},
+		{Cancun, 0, 3 * GasPerBlob, 0},
+		{Cancun, 0, 6 * GasPerBlob, 3 * GasPerBlob},
+		{Cancun, 2 * GasPerBlob, 2 * GasPerBlob, GasPerBlob},
+		{Cancun, GasPerBlob, 0, 0},
+		{Prague, 0, 9 * GasPerBlob, 3 * GasPerBlob},
+		{Prague, 0, 6 * GasPerBlob, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.config.NextExcessBlobGas(tt.excess, tt.used); got != tt.expectedExcess {
+			t.Errorf("%d + %d: Expected %d, got %d", tt.excess, tt.used, tt.expectedExcess, got)
+		}
+	}
+}
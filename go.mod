@@ -1,3 +1,8 @@
 module github.com/morpheum-labs/safem
 
 go 1.25.0
+
+require (
+	google.golang.org/genproto v0.0.0-20250603155806-513f23925822
+	google.golang.org/protobuf v1.36.10
+)
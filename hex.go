@@ -0,0 +1,281 @@
+package safem
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Limits of the 256-bit integer types of the EVM.
+var (
+	maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(oneInt, 256), oneInt)
+	maxInt256  = new(big.Int).Sub(new(big.Int).Lsh(oneInt, 255), oneInt)
+	minInt256  = new(big.Int).Neg(new(big.Int).Lsh(oneInt, 255))
+)
+
+// EncodeQuantity returns x as a JSON-RPC QUANTITY: 0x-prefixed lower-case hex
+// without leading zeros, and "0x0" for zero.
+//
+// Returns ErrInvalidInput for nil and ErrNegativeInput for negative values,
+// which have no QUANTITY encoding, and ErrTooLarge for values of 2^256 or more,
+// which DecodeQuantity rejects.
+//
+// Example:
+//
+//	EncodeQuantity(big.NewInt(65))   // output: "0x41"
+//	EncodeQuantity(big.NewInt(1024)) // output: "0x400"
+//	EncodeQuantity(big.NewInt(0))    // output: "0x0"
+func EncodeQuantity(x *big.Int) (string, error) {
+	if x == nil {
+		return "", fmt.Errorf("cannot encode quantity: %w", ErrInvalidInput)
+	}
+	if x.Sign() < 0 {
+		return "", fmt.Errorf("cannot encode quantity %s: %w", x.String(), ErrNegativeInput)
+	}
+	if x.Cmp(maxUint256) > 0 {
+		return "", fmt.Errorf("cannot encode quantity %s: %w", x.String(), ErrTooLarge)
+	}
+	return "0x" + x.Text(16), nil
+}
+
+// DecodeQuantity parses a JSON-RPC QUANTITY. Validation is strict: the value
+// must start with "0x", have at least one hex digit, have no leading zeros
+// unless it is "0x0", and fit in 256 bits. Hex digits may be upper or lower case.
+//
+// Example:
+//
+//	DecodeQuantity("0x400")  // 1024
+//	DecodeQuantity("0x")     // error: no digits
+//	DecodeQuantity("0x0400") // error: leading zero
+//	DecodeQuantity("ff")     // error: missing 0x prefix
+func DecodeQuantity(s string) (*big.Int, error) {
+	digits, ok := strings.CutPrefix(s, "0x")
+	var reason string
+	switch {
+	case !ok:
+		reason = "missing 0x prefix"
+	case digits == "":
+		reason = "no digits"
+	case len(digits) > 1 && digits[0] == '0':
+		reason = "leading zero"
+	case len(digits) > 64:
+		reason = "larger than 256 bits"
+	case !isHexDigits(digits):
+		reason = "invalid hex digit"
+	}
+	if reason != "" {
+		return nil, fmt.Errorf("cannot decode quantity %q: %s: %w", s, reason, ErrInvalidString)
+	}
+	v, _ := new(big.Int).SetString(digits, 16)
+	return v, nil
+}
+
+// EncodeDecimalQuantity returns d in token units with the given decimals as a
+// JSON-RPC QUANTITY. Returns ErrPrecisionLoss when d has digits below one unit.
+//
+// Example:
+//
+//	EncodeDecimalQuantity(RequireFromString("1.5"), 18) // output: "0x14d1120d7b160000"
+func EncodeDecimalQuantity(d Decimal, decimals int32) (string, error) {
+	units, err := decimalUnits(d, decimals)
+	if err != nil {
+		return "", err
+	}
+	return EncodeQuantity(units)
+}
+
+// DecodeDecimalQuantity parses a JSON-RPC QUANTITY of token units and returns it
+// as a Decimal with the given decimals.
+func DecodeDecimalQuantity(s string, decimals int32) (Decimal, error) {
+	v, err := DecodeQuantity(s)
+	if err != nil {
+		return Decimal{}, err
+	}
+	return NewFromBigInt(v, -decimals), nil
+}
+
+// EncodeData returns b as JSON-RPC DATA: 0x-prefixed lower-case hex with two
+// digits per byte, and "0x" for no bytes.
+func EncodeData(b []byte) string {
+	return "0x" + hex.EncodeToString(b)
+}
+
+// DecodeData parses JSON-RPC DATA. The value must start with "0x" and have an
+// even number of hex digits; "0x" is empty data.
+//
+// Example:
+//
+//	DecodeData("0x004200") // [0x00 0x42 0x00]
+//	DecodeData("0xf0f0f")  // error: odd number of digits
+//	DecodeData("004200")   // error: missing 0x prefix
+func DecodeData(s string) ([]byte, error) {
+	digits, ok := strings.CutPrefix(s, "0x")
+	var reason string
+	switch {
+	case !ok:
+		reason = "missing 0x prefix"
+	case len(digits)%2 != 0:
+		reason = "odd number of digits"
+	case !isHexDigits(digits):
+		reason = "invalid hex digit"
+	}
+	if reason != "" {
+		return nil, fmt.Errorf("cannot decode data %q: %s: %w", s, reason, ErrInvalidString)
+	}
+	b, _ := hex.DecodeString(digits)
+	return b, nil
+}
+
+func isHexDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// ABIWord is a 32-byte big-endian word of the Ethereum contract ABI, the
+// encoding of uint<M> and int<M> values.
+type ABIWord [32]byte
+
+// NewUint256Word returns x as a uint256 word. Returns ErrNegativeInput for
+// negative values and ErrTooLarge for values of 2^256 or more.
+//
+// Example:
+//
+//	w, _ := NewUint256Word(big.NewInt(69))
+//	w.Hex() // output: "0x0000000000000000000000000000000000000000000000000000000000000045"
+func NewUint256Word(x *big.Int) (ABIWord, error) {
+	var w ABIWord
+	switch {
+	case x == nil:
+		return w, fmt.Errorf("cannot encode uint256: %w", ErrInvalidInput)
+	case x.Sign() < 0:
+		return w, fmt.Errorf("cannot encode %s as uint256: %w", x.String(), ErrNegativeInput)
+	case x.Cmp(maxUint256) > 0:
+		return w, fmt.Errorf("cannot encode %s as uint256: %w", x.String(), ErrTooLarge)
+	}
+	x.FillBytes(w[:])
+	return w, nil
+}
+
+// NewInt256Word returns x as an int256 word in two's complement. Returns
+// ErrTooLarge for values outside [-2^255, 2^255).
+//
+// Example:
+//
+//	w, _ := NewInt256Word(big.NewInt(-1))
+//	w.Hex() // output: "0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"
+func NewInt256Word(x *big.Int) (ABIWord, error) {
+	var w ABIWord
+	switch {
+	case x == nil:
+		return w, fmt.Errorf("cannot encode int256: %w", ErrInvalidInput)
+	case x.Cmp(minInt256) < 0 || x.Cmp(maxInt256) > 0:
+		return w, fmt.Errorf("cannot encode %s as int256: %w", x.String(), ErrTooLarge)
+	}
+	if x.Sign() >= 0 {
+		x.FillBytes(w[:])
+		return w, nil
+	}
+	// 2^256 + x is the two's complement of a negative x
+	v := new(big.Int).Add(maxUint256, x)
+	v.Add(v, oneInt).FillBytes(w[:])
+	return w, nil
+}
+
+// NewUint256WordFromDecimal returns d in token units with the given decimals as
+// a uint256 word. Returns ErrPrecisionLoss when d has digits below one unit.
+func NewUint256WordFromDecimal(d Decimal, decimals int32) (ABIWord, error) {
+	units, err := decimalUnits(d, decimals)
+	if err != nil {
+		return ABIWord{}, err
+	}
+	return NewUint256Word(units)
+}
+
+// NewInt256WordFromDecimal returns d in token units with the given decimals as
+// an int256 word. Returns ErrPrecisionLoss when d has digits below one unit.
+func NewInt256WordFromDecimal(d Decimal, decimals int32) (ABIWord, error) {
+	units, err := decimalUnits(d, decimals)
+	if err != nil {
+		return ABIWord{}, err
+	}
+	return NewInt256Word(units)
+}
+
+// ParseABIWord parses a word written as "0x" followed by exactly 64 hex digits.
+func ParseABIWord(s string) (ABIWord, error) {
+	var w ABIWord
+	digits, ok := strings.CutPrefix(s, "0x")
+	if !ok || len(digits) != 64 || !isHexDigits(digits) {
+		return w, fmt.Errorf("cannot decode ABI word %q: %w", s, ErrInvalidString)
+	}
+	// the digits are checked above, so Decode cannot fail
+	_, _ = hex.Decode(w[:], []byte(digits))
+	return w, nil
+}
+
+// Uint256 returns the word read as a uint256.
+func (w ABIWord) Uint256() *big.Int {
+	return new(big.Int).SetBytes(w[:])
+}
+
+// Int256 returns the word read as a two's complement int256.
+func (w ABIWord) Int256() *big.Int {
+	v := w.Uint256()
+	if w[0]&0x80 != 0 {
+		v.Sub(v, maxUint256).Sub(v, oneInt)
+	}
+	return v
+}
+
+// Uint256Decimal returns the word read as a uint256 amount of token units with
+// the given decimals.
+func (w ABIWord) Uint256Decimal(decimals int32) Decimal {
+	return NewFromBigInt(w.Uint256(), -decimals)
+}
+
+// Int256Decimal returns the word read as an int256 amount of token units with
+// the given decimals.
+func (w ABIWord) Int256Decimal(decimals int32) Decimal {
+	return NewFromBigInt(w.Int256(), -decimals)
+}
+
+// Hex returns the word as "0x" followed by 64 lower-case hex digits.
+func (w ABIWord) Hex() string {
+	return EncodeData(w[:])
+}
+
+// String returns the word in hex, as Hex.
+func (w ABIWord) String() string {
+	return w.Hex()
+}
+
+// Quantity returns the value as a JSON-RPC QUANTITY, see EncodeQuantity.
+func (b BigInt) Quantity() (string, error) {
+	return EncodeQuantity(b.Int)
+}
+
+// Uint256Word returns the value as a uint256 ABI word, see NewUint256Word.
+func (b BigInt) Uint256Word() (ABIWord, error) {
+	return NewUint256Word(b.Int)
+}
+
+// Int256Word returns the value as an int256 ABI word, see NewInt256Word.
+func (b BigInt) Int256Word() (ABIWord, error) {
+	return NewInt256Word(b.Int)
+}
+
+// decimalUnits returns d * 10^decimals, or ErrPrecisionLoss when that is not an
+// integer.
+func decimalUnits(d Decimal, decimals int32) (*big.Int, error) {
+	v := d.Shift(decimals)
+	if !v.IsInteger() {
+		return nil, fmt.Errorf("cannot convert %s to units with %d decimals: %w", d.String(), decimals, ErrPrecisionLoss)
+	}
+	return v.BigInt(), nil
+}
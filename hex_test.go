@@ -0,0 +1,225 @@
+package safem
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestQuantity(t *testing.T) {
+	// examples of the Ethereum JSON-RPC specification
+	valid := []struct {
+		s string
+		v int64
+	}{
+		{"0x41", 65},
+		{"0x400", 1024},
+		{"0x0", 0},
+		{"0xFF", 255},
+	}
+	for _, tt := range valid {
+		v, err := DecodeQuantity(tt.s)
+		if err != nil || v.Int64() != tt.v {
+			t.Errorf("DecodeQuantity(%s): Expected %d, got %v %v", tt.s, tt.v, v, err)
+		}
+		if s, err := EncodeQuantity(big.NewInt(tt.v)); err != nil || s != strings.ToLower(tt.s) {
+			t.Errorf("EncodeQuantity(%d): Expected %s, got %s %v", tt.v, strings.ToLower(tt.s), s, err)
+		}
+	}
+
+	invalid := []string{
+		"0x",     // at least one digit
+		"0x0400", // no leading zeros
+		"0x00",
+		"ff", // 0x prefix
+		"0X41",
+		"",
+		"-0x1",
+		"0x-1",
+		"0x1g",
+		"0x 1",
+		"0x1_0",
+		"0x1" + strings.Repeat("0", 64), // 2^256
+	}
+	for _, s := range invalid {
+		if v, err := DecodeQuantity(s); !errors.Is(err, ErrInvalidString) {
+			t.Errorf("DecodeQuantity(%q): Expected ErrInvalidString, got %v %v", s, v, err)
+		}
+	}
+
+	maxHex := "0x" + strings.Repeat("f", 64)
+	if v, err := DecodeQuantity(maxHex); err != nil || v.Cmp(maxUint256) != 0 {
+		t.Errorf("Expected 2^256-1, got %v %v", v, err)
+	}
+
+	if _, err := EncodeQuantity(big.NewInt(-1)); !errors.Is(err, ErrNegativeInput) {
+		t.Errorf("Expected ErrNegativeInput, got %v", err)
+	}
+	if _, err := EncodeQuantity(nil); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput, got %v", err)
+	}
+	if s, err := EncodeQuantity(maxUint256); err != nil || s != maxHex {
+		t.Errorf("Expected %s, got %s %v", maxHex, s, err)
+	}
+	if _, err := EncodeQuantity(new(big.Int).Lsh(oneInt, 256)); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Expected ErrTooLarge, got %v", err)
+	}
+	if s, err := (BigInt{Int: big.NewInt(1000)}).Quantity(); err != nil || s != "0x3e8" {
+		t.Errorf("Expected 0x3e8, got %s %v", s, err)
+	}
+}
+
+func TestDecimalQuantity(t *testing.T) {
+	s, err := EncodeDecimalQuantity(RequireFromString("1.5"), 18)
+	if err != nil || s != "0x14d1120d7b160000" {
+		t.Errorf("Expected 0x14d1120d7b160000, got %s %v", s, err)
+	}
+	d, err := DecodeDecimalQuantity(s, 18)
+	if err != nil || d.String() != "1.5" {
+		t.Errorf("Expected 1.5, got %s %v", d.String(), err)
+	}
+	if _, err := EncodeDecimalQuantity(RequireFromString("0.0000001"), 6); !errors.Is(err, ErrPrecisionLoss) {
+		t.Errorf("Expected ErrPrecisionLoss, got %v", err)
+	}
+	if _, err := DecodeDecimalQuantity("0x0400", 6); !errors.Is(err, ErrInvalidString) {
+		t.Errorf("Expected ErrInvalidString, got %v", err)
+	}
+}
+
+func TestData(t *testing.T) {
+	// examples of the Ethereum JSON-RPC specification
+	valid := []struct {
+		s string
+		b []byte
+	}{
+		{"0x41", []byte("A")},
+		{"0x004200", []byte{0x00, 0x42, 0x00}},
+		{"0x", []byte{}},
+	}
+	for _, tt := range valid {
+		b, err := DecodeData(tt.s)
+		if err != nil || !bytes.Equal(b, tt.b) {
+			t.Errorf("DecodeData(%s): Expected %x, got %x %v", tt.s, tt.b, b, err)
+		}
+		if s := EncodeData(tt.b); s != tt.s {
+			t.Errorf("EncodeData(%x): Expected %s, got %s", tt.b, tt.s, s)
+		}
+	}
+
+	for _, s := range []string{"0xf0f0f", "004200", "0x0g", "", "0X00"} {
+		if _, err := DecodeData(s); !errors.Is(err, ErrInvalidString) {
+			t.Errorf("DecodeData(%q): Expected ErrInvalidString, got %v", s, err)
+		}
+	}
+}
+
+func TestABIWord(t *testing.T) {
+	zeros := strings.Repeat("0", 62)
+	tests := []struct {
+		v      *big.Int
+		signed bool
+		hex    string
+	}{
+		{big.NewInt(69), false, "0x" + zeros + "45"}, // uint32 69 in the ABI specification
+		{big.NewInt(1), false, "0x" + zeros + "01"},
+		{big.NewInt(0), false, "0x" + zeros + "00"},
+		{new(big.Int).Set(maxUint256), false, "0x" + strings.Repeat("f", 64)},
+		{big.NewInt(-1), true, "0x" + strings.Repeat("f", 64)},
+		{big.NewInt(-2), true, "0x" + strings.Repeat("f", 63) + "e"},
+		{big.NewInt(127), true, "0x" + zeros + "7f"},
+		{big.NewInt(-128), true, "0x" + strings.Repeat("f", 62) + "80"},
+		{new(big.Int).Set(maxInt256), true, "0x7" + strings.Repeat("f", 63)},
+		{new(big.Int).Set(minInt256), true, "0x8" + strings.Repeat("0", 63)},
+	}
+
+	for _, tt := range tests {
+		var w ABIWord
+		var err error
+		if tt.signed {
+			w, err = NewInt256Word(tt.v)
+		} else {
+			w, err = NewUint256Word(tt.v)
+		}
+		if err != nil || w.Hex() != tt.hex {
+			t.Errorf("%s: Expected %s, got %s %v", tt.v, tt.hex, w.Hex(), err)
+			continue
+		}
+
+		parsed, err := ParseABIWord(tt.hex)
+		if err != nil || parsed != w {
+			t.Errorf("ParseABIWord(%s): Expected %s, got %s %v", tt.hex, w, parsed, err)
+		}
+		got := parsed.Uint256()
+		if tt.signed {
+			got = parsed.Int256()
+		}
+		if got.Cmp(tt.v) != 0 {
+			t.Errorf("%s: Expected %s, got %s", tt.hex, tt.v, got)
+		}
+	}
+
+	invalid := []struct {
+		v      *big.Int
+		signed bool
+		err    error
+	}{
+		{big.NewInt(-1), false, ErrNegativeInput},
+		{new(big.Int).Add(maxUint256, oneInt), false, ErrTooLarge},
+		{new(big.Int).Add(maxInt256, oneInt), true, ErrTooLarge},
+		{new(big.Int).Sub(minInt256, oneInt), true, ErrTooLarge},
+		{nil, false, ErrInvalidInput},
+		{nil, true, ErrInvalidInput},
+	}
+	for _, tt := range invalid {
+		var err error
+		if tt.signed {
+			_, err = NewInt256Word(tt.v)
+		} else {
+			_, err = NewUint256Word(tt.v)
+		}
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%v: Expected %v, got %v", tt.v, tt.err, err)
+		}
+	}
+
+	for _, s := range []string{"0x45", zeros + "0045", "0x" + zeros + "0g", "0X" + zeros + "45", "0x" + zeros + "00045"} {
+		if _, err := ParseABIWord(s); !errors.Is(err, ErrInvalidString) {
+			t.Errorf("ParseABIWord(%q): Expected ErrInvalidString, got %v", s, err)
+		}
+	}
+}
+
+func TestABIWordDecimal(t *testing.T) {
+	w, err := NewUint256WordFromDecimal(RequireFromString("1.5"), 6)
+	if err != nil || w.Hex() != "0x"+strings.Repeat("0", 58)+"16e360" {
+		t.Errorf("Expected 1500000, got %s %v", w.Hex(), err)
+	}
+	if d := w.Uint256Decimal(6); d.String() != "1.5" {
+		t.Errorf("Expected 1.5, got %s", d.String())
+	}
+
+	w, err = NewInt256WordFromDecimal(RequireFromString("-0.25"), 18)
+	if err != nil || w.Int256Decimal(18).String() != "-0.25" {
+		t.Errorf("Expected -0.25, got %s %v", w.Int256Decimal(18).String(), err)
+	}
+	if w.Uint256Decimal(0).Sign() <= 0 {
+		t.Errorf("Expected the two's complement to read as a positive uint256")
+	}
+
+	if _, err := NewUint256WordFromDecimal(RequireFromString("0.0000001"), 6); !errors.Is(err, ErrPrecisionLoss) {
+		t.Errorf("Expected ErrPrecisionLoss, got %v", err)
+	}
+	if _, err := NewInt256WordFromDecimal(RequireFromString("1.5"), 0); !errors.Is(err, ErrPrecisionLoss) {
+		t.Errorf("Expected ErrPrecisionLoss, got %v", err)
+	}
+
+	b := BigInt{Int: big.NewInt(-1)}
+	if _, err := b.Uint256Word(); !errors.Is(err, ErrNegativeInput) {
+		t.Errorf("Expected ErrNegativeInput, got %v", err)
+	}
+	if w, err := b.Int256Word(); err != nil || w.Int256().Int64() != -1 {
+		t.Errorf("Expected -1, got %s %v", w, err)
+	}
+}
@@ -0,0 +1,46 @@
+package bigint
+
+import "math/big"
+
+// QuoRound returns n / d rounded to an integer with mode, for d > 0. n may be
+// negative; the ToNearest modes break ties on the exact half.
+func QuoRound(n, d *big.Int, mode big.RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(n, d, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+
+	// QuoRem truncates towards zero; decide whether to move away from it
+	negative := r.Sign() < 0
+	var away bool
+	switch mode {
+	case big.ToZero:
+		away = false
+	case big.AwayFromZero:
+		away = true
+	case big.ToNegativeInf:
+		away = negative
+	case big.ToPositiveInf:
+		away = !negative
+	default:
+		switch c := new(big.Int).Lsh(r.Abs(r), 1).Cmp(d); {
+		case c > 0:
+			away = true
+		case c == 0:
+			away = mode == big.ToNearestAway || q.Bit(0) == 1
+		}
+	}
+	if away {
+		if negative {
+			q.Sub(q, one)
+		} else {
+			q.Add(q, one)
+		}
+	}
+	return q
+}
+
+// CeilDiv returns ⌈n / d⌉ for d > 0.
+func CeilDiv(n, d *big.Int) *big.Int {
+	return QuoRound(n, d, big.ToPositiveInf)
+}
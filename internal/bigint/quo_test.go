@@ -0,0 +1,47 @@
+package bigint
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestQuoRound(t *testing.T) {
+	modes := []big.RoundingMode{big.ToNearestEven, big.ToNearestAway, big.ToZero, big.AwayFromZero, big.ToNegativeInf, big.ToPositiveInf}
+	tests := []struct {
+		n, d     int64
+		expected [6]int64 // in the order of modes
+	}{
+		{6, 3, [6]int64{2, 2, 2, 2, 2, 2}},
+		{7, 3, [6]int64{2, 2, 2, 3, 2, 3}},
+		{8, 3, [6]int64{3, 3, 2, 3, 2, 3}},
+		{5, 2, [6]int64{2, 3, 2, 3, 2, 3}},
+		{7, 2, [6]int64{4, 4, 3, 4, 3, 4}},
+		{-7, 3, [6]int64{-2, -2, -2, -3, -3, -2}},
+		{-8, 3, [6]int64{-3, -3, -2, -3, -3, -2}},
+		{-5, 2, [6]int64{-2, -3, -2, -3, -3, -2}},
+		{0, 7, [6]int64{0, 0, 0, 0, 0, 0}},
+	}
+	for _, tt := range tests {
+		for i, mode := range modes {
+			got := QuoRound(big.NewInt(tt.n), big.NewInt(tt.d), mode)
+			if got.Int64() != tt.expected[i] {
+				t.Errorf("QuoRound(%d, %d, %v): Expected %d, got %s", tt.n, tt.d, mode, tt.expected[i], got)
+			}
+		}
+	}
+
+	n := big.NewInt(7)
+	QuoRound(n, big.NewInt(2), big.ToNearestEven)
+	if n.Int64() != 7 {
+		t.Errorf("QuoRound modified its argument")
+	}
+}
+
+func TestCeilDiv(t *testing.T) {
+	if got := CeilDiv(big.NewInt(7), big.NewInt(2)); got.Int64() != 4 {
+		t.Errorf("Expected 4, got %s", got)
+	}
+	if got := CeilDiv(big.NewInt(-7), big.NewInt(2)); got.Int64() != -3 {
+		t.Errorf("Expected -3, got %s", got)
+	}
+}
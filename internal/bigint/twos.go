@@ -0,0 +1,47 @@
+// Package bigint holds big.Int helpers shared by safem and its subpackages.
+package bigint
+
+import "math/big"
+
+var one = big.NewInt(1)
+
+// TwosComplement returns x as big-endian two's complement with the minimum
+// number of bytes, as java.math.BigInteger.toByteArray; zero is empty.
+func TwosComplement(x *big.Int) []byte {
+	switch x.Sign() {
+	case 0:
+		return nil
+	case 1:
+		b := x.Bytes()
+		if b[0]&0x80 != 0 {
+			b = append([]byte{0}, b...)
+		}
+		return b
+	}
+
+	// -x - 1 with every bit inverted is the two's complement of x
+	m := new(big.Int).Neg(x)
+	b := m.Sub(m, one).Bytes()
+	for i := range b {
+		b[i] = ^b[i]
+	}
+	if len(b) == 0 || b[0]&0x80 == 0 {
+		b = append([]byte{0xff}, b...)
+	}
+	return b
+}
+
+// FromTwosComplement is the inverse of TwosComplement. It also accepts encodings
+// with redundant sign bytes.
+func FromTwosComplement(b []byte) *big.Int {
+	if len(b) == 0 || b[0]&0x80 == 0 {
+		return new(big.Int).SetBytes(b)
+	}
+
+	inv := make([]byte, len(b))
+	for i := range b {
+		inv[i] = ^b[i]
+	}
+	x := new(big.Int).SetBytes(inv)
+	return x.Add(x, one).Neg(x)
+}
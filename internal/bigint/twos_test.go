@@ -0,0 +1,49 @@
+package bigint
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+)
+
+func TestTwosComplement(t *testing.T) {
+	// expected values match java.math.BigInteger.toByteArray
+	tests := []struct {
+		value    string
+		expected []byte
+	}{
+		{"0", nil},
+		{"1", []byte{0x01}},
+		{"127", []byte{0x7f}},
+		{"128", []byte{0x00, 0x80}},
+		{"255", []byte{0x00, 0xff}},
+		{"256", []byte{0x01, 0x00}},
+		{"-1", []byte{0xff}},
+		{"-128", []byte{0x80}},
+		{"-129", []byte{0xff, 0x7f}},
+		{"-256", []byte{0xff, 0x00}},
+		{"-32768", []byte{0x80, 0x00}},
+		{"1000000000000000000", []byte{0x0d, 0xe0, 0xb6, 0xb3, 0xa7, 0x64, 0x00, 0x00}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			x, _ := new(big.Int).SetString(tt.value, 10)
+			got := TwosComplement(x)
+			if !bytes.Equal(got, tt.expected) {
+				t.Errorf("Expected %x, got %x", tt.expected, got)
+			}
+			if back := FromTwosComplement(got); back.Cmp(x) != 0 {
+				t.Errorf("Expected %s, got %s", tt.value, back.String())
+			}
+		})
+	}
+
+	// redundant sign bytes are accepted
+	if got := FromTwosComplement([]byte{0xff, 0xff, 0x80}); got.Int64() != -128 {
+		t.Errorf("Expected -128, got %s", got.String())
+	}
+	if got := FromTwosComplement([]byte{0x00, 0x00, 0x01}); got.Int64() != 1 {
+		t.Errorf("Expected 1, got %s", got.String())
+	}
+}
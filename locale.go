@@ -0,0 +1,141 @@
+package safem
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Locale holds the symbols and patterns used to format numbers for one locale.
+// The built-in locales are derived from the CLDR number data (latn numbering
+// system) and embedded in the package, so no lookup ever touches the network.
+type Locale struct {
+	// Tag is the BCP 47 language tag, e.g. "de-DE".
+	Tag string
+
+	// Decimal separates the integer and fractional parts.
+	Decimal string
+
+	// Group separates groups of integer digits.
+	Group string
+
+	// Minus is the sign prepended to negative numbers.
+	Minus string
+
+	// GroupSize is the size of the group closest to the decimal separator.
+	GroupSize int
+
+	// SecondaryGroupSize is the size of every other group, e.g. 2 for the Indian
+	// 12,34,56,789. Zero means GroupSize.
+	SecondaryGroupSize int
+
+	// MinGroupingDigits is the number of digits the leftmost group must have for
+	// grouping to apply, e.g. 2 in es-ES where 1234 is written without separator.
+	MinGroupingDigits int
+
+	// CurrencyPattern places the currency symbol '¤' around the number '#', e.g.
+	// "¤#" for $12.50 and "#\u00a0¤" for 12,50 €.
+	CurrencyPattern string
+
+	// Compact lists the short compact units in increasing order of magnitude.
+	Compact []CompactUnit
+}
+
+// CompactUnit is one step of compact notation: values of at least 10^Exponent
+// are divided by 10^Exponent and printed with Suffix, e.g. {3, "K"} for 1.2K.
+type CompactUnit struct {
+	Exponent int32
+	Suffix   string
+}
+
+var (
+	compactEnglish = []CompactUnit{{3, "K"}, {6, "M"}, {9, "B"}, {12, "T"}}
+	compactGerman  = []CompactUnit{{6, "\u00a0Mio."}, {9, "\u00a0Mrd."}, {12, "\u00a0Bio."}}
+	compactCJK     = []CompactUnit{{4, "万"}, {8, "亿"}, {12, "万亿"}}
+)
+
+// locales is the embedded CLDR-derived locale table, keyed by lower-case tag.
+var locales = map[string]Locale{
+	"en-us": {Tag: "en-US", Decimal: ".", Group: ",", Minus: "-", GroupSize: 3, MinGroupingDigits: 1,
+		CurrencyPattern: "¤#", Compact: compactEnglish},
+	"en-gb": {Tag: "en-GB", Decimal: ".", Group: ",", Minus: "-", GroupSize: 3, MinGroupingDigits: 1,
+		CurrencyPattern: "¤#", Compact: compactEnglish},
+	"en-in": {Tag: "en-IN", Decimal: ".", Group: ",", Minus: "-", GroupSize: 3, SecondaryGroupSize: 2, MinGroupingDigits: 1,
+		CurrencyPattern: "¤#", Compact: []CompactUnit{{3, "K"}, {5, "L"}, {7, "Cr"}}},
+	"de-de": {Tag: "de-DE", Decimal: ",", Group: ".", Minus: "-", GroupSize: 3, MinGroupingDigits: 1,
+		CurrencyPattern: "#\u00a0¤", Compact: compactGerman},
+	"de-ch": {Tag: "de-CH", Decimal: ".", Group: "\u2019", Minus: "-", GroupSize: 3, MinGroupingDigits: 1,
+		CurrencyPattern: "¤\u00a0#", Compact: compactGerman},
+	"fr-fr": {Tag: "fr-FR", Decimal: ",", Group: "\u202f", Minus: "-", GroupSize: 3, MinGroupingDigits: 1,
+		CurrencyPattern: "#\u00a0¤", Compact: []CompactUnit{{3, "\u00a0k"}, {6, "\u00a0M"}, {9, "\u00a0Md"}, {12, "\u00a0Bn"}}},
+	"es-es": {Tag: "es-ES", Decimal: ",", Group: ".", Minus: "-", GroupSize: 3, MinGroupingDigits: 2,
+		CurrencyPattern: "#\u00a0¤", Compact: []CompactUnit{{3, "\u00a0mil"}, {6, "\u00a0M"}, {9, "\u00a0mil\u00a0M"}, {12, "\u00a0B"}}},
+	"it-it": {Tag: "it-IT", Decimal: ",", Group: ".", Minus: "-", GroupSize: 3, MinGroupingDigits: 1,
+		CurrencyPattern: "#\u00a0¤", Compact: []CompactUnit{{6, "\u00a0Mln"}, {9, "\u00a0Mrd"}, {12, "\u00a0Bln"}}},
+	"nl-nl": {Tag: "nl-NL", Decimal: ",", Group: ".", Minus: "-", GroupSize: 3, MinGroupingDigits: 1,
+		CurrencyPattern: "¤\u00a0#", Compact: []CompactUnit{{3, "K"}, {6, "\u00a0mln."}, {9, "\u00a0mld."}, {12, "\u00a0bln."}}},
+	"pt-br": {Tag: "pt-BR", Decimal: ",", Group: ".", Minus: "-", GroupSize: 3, MinGroupingDigits: 1,
+		CurrencyPattern: "¤\u00a0#", Compact: []CompactUnit{{3, "\u00a0mil"}, {6, "\u00a0mi"}, {9, "\u00a0bi"}, {12, "\u00a0tri"}}},
+	"pl-pl": {Tag: "pl-PL", Decimal: ",", Group: "\u00a0", Minus: "-", GroupSize: 3, MinGroupingDigits: 2,
+		CurrencyPattern: "#\u00a0¤", Compact: []CompactUnit{{3, "\u00a0tys."}, {6, "\u00a0mln"}, {9, "\u00a0mld"}, {12, "\u00a0bln"}}},
+	"sv-se": {Tag: "sv-SE", Decimal: ",", Group: "\u00a0", Minus: "\u2212", GroupSize: 3, MinGroupingDigits: 1,
+		CurrencyPattern: "#\u00a0¤", Compact: []CompactUnit{{3, "\u00a0tn"}, {6, "\u00a0mn"}, {9, "\u00a0md"}, {12, "\u00a0bn"}}},
+	"ru-ru": {Tag: "ru-RU", Decimal: ",", Group: "\u00a0", Minus: "-", GroupSize: 3, MinGroupingDigits: 1,
+		CurrencyPattern: "#\u00a0¤", Compact: []CompactUnit{{3, "\u00a0тыс."}, {6, "\u00a0млн"}, {9, "\u00a0млрд"}, {12, "\u00a0трлн"}}},
+	"tr-tr": {Tag: "tr-TR", Decimal: ",", Group: ".", Minus: "-", GroupSize: 3, MinGroupingDigits: 1,
+		CurrencyPattern: "¤#", Compact: []CompactUnit{{3, "\u00a0B"}, {6, "\u00a0Mn"}, {9, "\u00a0Mr"}, {12, "\u00a0Tn"}}},
+	"ja-jp": {Tag: "ja-JP", Decimal: ".", Group: ",", Minus: "-", GroupSize: 3, MinGroupingDigits: 1,
+		CurrencyPattern: "¤#", Compact: []CompactUnit{{4, "万"}, {8, "億"}, {12, "兆"}}},
+	"zh-cn": {Tag: "zh-CN", Decimal: ".", Group: ",", Minus: "-", GroupSize: 3, MinGroupingDigits: 1,
+		CurrencyPattern: "¤#", Compact: compactCJK},
+	"ko-kr": {Tag: "ko-KR", Decimal: ".", Group: ",", Minus: "-", GroupSize: 3, MinGroupingDigits: 1,
+		CurrencyPattern: "¤#", Compact: []CompactUnit{{3, "천"}, {4, "만"}, {8, "억"}, {12, "조"}}},
+}
+
+// defaultRegions maps a bare language to the region used for it.
+var defaultRegions = map[string]string{
+	"en": "en-us", "de": "de-de", "fr": "fr-fr", "es": "es-es", "it": "it-it", "nl": "nl-nl",
+	"pt": "pt-br", "pl": "pl-pl", "sv": "sv-se", "ru": "ru-ru", "tr": "tr-tr", "ja": "ja-jp",
+	"zh": "zh-cn", "ko": "ko-kr",
+}
+
+// LookupLocale returns the embedded locale for a BCP 47 tag. Tags are matched
+// case-insensitively, '_' is accepted in place of '-', and a bare language such
+// as "de" selects its default region.
+//
+// Returns error when the locale is not in the table.
+//
+// Example:
+//
+//	loc, err := LookupLocale("de_DE")
+//	loc.Decimal // output: ","
+func LookupLocale(tag string) (Locale, error) {
+	key := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	if region, ok := defaultRegions[key]; ok {
+		key = region
+	}
+	loc, ok := locales[key]
+	if !ok {
+		return Locale{}, fmt.Errorf("unknown locale %q", tag)
+	}
+	return loc, nil
+}
+
+// MustLookupLocale returns the embedded locale for tag, panicking if it is unknown.
+func MustLookupLocale(tag string) Locale {
+	loc, err := LookupLocale(tag)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
+
+// LocaleTags returns the tags of every embedded locale, sorted.
+func LocaleTags() []string {
+	tags := make([]string, 0, len(locales))
+	for _, loc := range locales {
+		tags = append(tags, loc.Tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
@@ -0,0 +1,265 @@
+package safem
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Formatter formats decimals, token amounts and BigInts for display in a locale,
+// with grouping separators, currency symbols, compact notation and shortened
+// runs of leading zeros for tiny values.
+//
+// A Formatter is a plain value; it is safe for concurrent use as long as its
+// fields are not modified.
+type Formatter struct {
+	// Locale supplies the separators, signs and patterns.
+	Locale Locale
+
+	// MinFractionDigits pads the fractional part with zeros to at least this many digits.
+	MinFractionDigits int32
+
+	// MaxFractionDigits rounds (half away from zero, or toward zero with
+	// Truncate) to at most this many digits after the decimal point. Values below
+	// MinFractionDigits are raised to it.
+	MaxFractionDigits int32
+
+	// SignificantDigits, when positive, keeps at least this many significant
+	// digits for values below 1 that MaxFractionDigits would otherwise round away,
+	// e.g. 0.00000123 instead of 0.00 for a token price.
+	SignificantDigits int
+
+	// Truncate drops the digits beyond MaxFractionDigits or SignificantDigits
+	// instead of rounding them, so a displayed amount never exceeds the real one,
+	// e.g. 0.99 rather than 1 for a balance of 0.999 that must not look spendable.
+	Truncate bool
+
+	// SubscriptZeros, when positive, writes a run of at least this many zeros right
+	// after the decimal point as a single zero with a subscript count, e.g.
+	// 0.0₅123 for 0.00000123.
+	SubscriptZeros int
+
+	// NoGrouping disables grouping separators.
+	NoGrouping bool
+
+	// Compact divides large values by the locale's compact units, e.g. 1.2K or 3.4M.
+	Compact bool
+
+	// Currency is the symbol placed by the locale's currency pattern. Empty
+	// formats a plain number.
+	Currency string
+
+	// Accounting writes negative values in parentheses instead of with a minus sign.
+	Accounting bool
+}
+
+// NewFormatter returns a Formatter for the locale tag with the CLDR default
+// decimal pattern: grouping on and up to 3 fraction digits.
+//
+// Returns error when the locale is unknown, see LookupLocale.
+//
+// Example:
+//
+//	f, err := NewFormatter("de-DE")
+//	f.Format(RequireFromString("1234.5678")) // output: "1.234,568"
+func NewFormatter(tag string) (*Formatter, error) {
+	loc, err := LookupLocale(tag)
+	if err != nil {
+		return nil, err
+	}
+	return &Formatter{Locale: loc, MaxFractionDigits: 3}, nil
+}
+
+// Format returns d formatted according to f.
+//
+// Example:
+//
+//	f := &Formatter{Locale: MustLookupLocale("en-US"), MinFractionDigits: 2, MaxFractionDigits: 2, Currency: "$"}
+//	f.Format(RequireFromString("-1234567.891")) // output: "-$1,234,567.89"
+//
+//	f = &Formatter{Locale: MustLookupLocale("en-US"), MaxFractionDigits: 1, Compact: true}
+//	f.Format(RequireFromString("1234")) // output: "1.2K"
+//
+//	f = &Formatter{Locale: MustLookupLocale("en-US"), MaxFractionDigits: 2, SignificantDigits: 3, SubscriptZeros: 4}
+//	f.Format(RequireFromString("0.000001234")) // output: "0.0₅123"
+func (f *Formatter) Format(d Decimal) string {
+	d.ensureInitialized()
+	neg := d.Sign() < 0
+	abs := d.Abs()
+
+	minFrac, maxFrac := f.MinFractionDigits, f.MaxFractionDigits
+	if minFrac < 0 {
+		minFrac = 0
+	}
+	if maxFrac < minFrac {
+		maxFrac = minFrac
+	}
+
+	suffix := ""
+	if f.Compact {
+		abs, suffix = f.compact(abs, maxFrac)
+	}
+
+	places := maxFrac
+	if f.SignificantDigits > 0 && !abs.IsZero() && abs.Cmp(New(1, 0)) < 0 {
+		// digits needed after the point: the leading zeros plus the significant digits
+		leading := int32(-abs.NumDigits() - int(abs.exp))
+		if p := leading + int32(f.SignificantDigits); p > places {
+			places = p
+		}
+	}
+	abs = f.round(abs, places)
+	if abs.IsZero() {
+		neg = false
+	}
+
+	intPart, fracPart := splitDigits(abs)
+	fracPart = strings.TrimRight(fracPart, "0")
+	if pad := int(minFrac) - len(fracPart); pad > 0 {
+		fracPart += strings.Repeat("0", pad)
+	}
+	if f.SubscriptZeros > 0 && intPart == "0" {
+		fracPart = subscriptLeadingZeros(fracPart, f.SubscriptZeros)
+	}
+
+	if !f.NoGrouping {
+		intPart = f.Locale.group(intPart)
+	}
+
+	num := intPart
+	if fracPart != "" {
+		num += f.Locale.Decimal + fracPart
+	}
+	num += suffix
+
+	if f.Currency != "" {
+		pattern := f.Locale.CurrencyPattern
+		if pattern == "" {
+			pattern = "¤#"
+		}
+		num = strings.Replace(strings.Replace(pattern, "#", num, 1), "¤", f.Currency, 1)
+	}
+
+	if !neg {
+		return num
+	}
+	if f.Accounting {
+		return "(" + num + ")"
+	}
+	minus := f.Locale.Minus
+	if minus == "" {
+		minus = "-"
+	}
+	return minus + num
+}
+
+// FormatUnits formats an integer token amount with the given number of decimals,
+// e.g. wei with 18 decimals. A nil amount formats as zero.
+//
+// Example:
+//
+//	f := &Formatter{Locale: MustLookupLocale("en-US"), MaxFractionDigits: 4}
+//	f.FormatUnits(big.NewInt(1234567890000000000), 18) // output: "1.2346"
+func (f *Formatter) FormatUnits(amount *big.Int, decimals int32) string {
+	if amount == nil {
+		return f.Format(New(0, 0))
+	}
+	return f.Format(NewFromBigInt(amount, -decimals))
+}
+
+// FormatBigInt formats a BigInt token amount with the given number of decimals.
+// A nil BigInt formats as zero.
+func (f *Formatter) FormatBigInt(amount *BigInt, decimals int32) string {
+	if amount == nil {
+		return f.FormatUnits(nil, decimals)
+	}
+	return f.FormatUnits(amount.Int, decimals)
+}
+
+// round rounds a non-negative abs to places, toward zero when f.Truncate is set.
+func (f *Formatter) round(abs Decimal, places int32) Decimal {
+	if f.Truncate {
+		return abs.RoundDown(places)
+	}
+	return abs.Round(places)
+}
+
+// compact divides abs by the largest compact unit it reaches. The value is
+// rounded to places in the current unit before the next one is tried, so a value
+// that rounds up into the next unit moves there: 999.96 becomes 1K and 999999
+// becomes 1M rather than 1,000 and 1000K.
+func (f *Formatter) compact(abs Decimal, places int32) (Decimal, string) {
+	scaled, suffix, exp := abs, "", int32(0)
+	for _, u := range f.Locale.Compact {
+		if f.round(scaled, places).Cmp(New(1, u.Exponent-exp)) < 0 {
+			break
+		}
+		scaled, suffix, exp = abs.Shift(-u.Exponent), u.Suffix, u.Exponent
+	}
+	return scaled, suffix
+}
+
+// group inserts the locale's group separator into a string of integer digits.
+func (l Locale) group(digits string) string {
+	size := l.GroupSize
+	if size <= 0 || l.Group == "" {
+		return digits
+	}
+	secondary := l.SecondaryGroupSize
+	if secondary <= 0 {
+		secondary = size
+	}
+	minGrouping := l.MinGroupingDigits
+	if minGrouping < 1 {
+		minGrouping = 1
+	}
+	if len(digits) < size+minGrouping {
+		return digits
+	}
+
+	groups := []string{digits[len(digits)-size:]}
+	rest := digits[:len(digits)-size]
+	for len(rest) > secondary {
+		groups = append(groups, rest[len(rest)-secondary:])
+		rest = rest[:len(rest)-secondary]
+	}
+	groups = append(groups, rest)
+
+	var sb strings.Builder
+	for i := len(groups) - 1; i >= 0; i-- {
+		sb.WriteString(groups[i])
+		if i > 0 {
+			sb.WriteString(l.Group)
+		}
+	}
+	return sb.String()
+}
+
+// splitDigits returns the integer and fractional digits of a non-negative decimal.
+func splitDigits(d Decimal) (string, string) {
+	if d.exp >= 0 {
+		return d.String(), ""
+	}
+	s := d.StringFixed(-d.exp)
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return s[:i], s[i+1:]
+	}
+	return s, ""
+}
+
+// subscriptLeadingZeros replaces a run of at least minZeros leading zeros in the
+// fractional digits with "0" followed by the run length in subscript digits.
+func subscriptLeadingZeros(frac string, minZeros int) string {
+	zeros := len(frac) - len(strings.TrimLeft(frac, "0"))
+	if zeros < minZeros || zeros == len(frac) {
+		return frac
+	}
+
+	var sb strings.Builder
+	sb.WriteByte('0')
+	for _, c := range strconv.Itoa(zeros) {
+		sb.WriteRune('₀' + (c - '0'))
+	}
+	sb.WriteString(frac[zeros:])
+	return sb.String()
+}
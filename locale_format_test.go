@@ -0,0 +1,147 @@
+package safem
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestLookupLocale(t *testing.T) {
+	tests := []struct {
+		tag      string
+		expected string
+	}{
+		{"de-DE", "de-DE"},
+		{"de_de", "de-DE"},
+		{"DE", "de-DE"},
+		{" en-in ", "en-IN"},
+		{"ja", "ja-JP"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			loc, err := LookupLocale(tt.tag)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if loc.Tag != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, loc.Tag)
+			}
+		})
+	}
+
+	if _, err := LookupLocale("xx-YY"); err == nil {
+		t.Errorf("Expected error for unknown locale")
+	}
+	if tags := LocaleTags(); len(tags) != len(locales) || tags[0] != "de-CH" {
+		t.Errorf("unexpected locale tags: %v", tags)
+	}
+}
+
+func TestFormatterFormat(t *testing.T) {
+	money := func(tag, symbol string) *Formatter {
+		return &Formatter{Locale: MustLookupLocale(tag), MinFractionDigits: 2, MaxFractionDigits: 2, Currency: symbol}
+	}
+	plain := func(tag string) *Formatter {
+		f, _ := NewFormatter(tag)
+		return f
+	}
+	compact := func(tag string) *Formatter {
+		return &Formatter{Locale: MustLookupLocale(tag), MaxFractionDigits: 1, Compact: true}
+	}
+	tiny := &Formatter{Locale: MustLookupLocale("en-US"), MaxFractionDigits: 2, SignificantDigits: 3, SubscriptZeros: 4}
+
+	tests := []struct {
+		name     string
+		f        *Formatter
+		value    string
+		expected string
+	}{
+		{"en-US plain", plain("en-US"), "1234567.8912", "1,234,567.891"},
+		{"en-US small", plain("en-US"), "999", "999"},
+		{"en-US negative", plain("en-US"), "-1234.5", "-1,234.5"},
+		{"en-US negative rounds to zero", plain("en-US"), "-0.0001", "0"},
+		{"de-DE plain", plain("de-DE"), "1234567.8912", "1.234.567,891"},
+		{"de-CH plain", plain("de-CH"), "1234567.25", "1’234’567.25"},
+		{"fr-FR plain", plain("fr-FR"), "1234567.25", "1 234 567,25"},
+		{"es-ES min grouping", plain("es-ES"), "1234.5", "1234,5"},
+		{"es-ES grouped", plain("es-ES"), "12345.5", "12.345,5"},
+		{"en-IN lakh grouping", plain("en-IN"), "123456789", "12,34,56,789"},
+		{"sv-SE minus", plain("sv-SE"), "-1234", "−1 234"},
+		{"no grouping", &Formatter{Locale: MustLookupLocale("en-US"), NoGrouping: true}, "1234567", "1234567"},
+		{"min fraction digits", &Formatter{Locale: MustLookupLocale("en-US"), MinFractionDigits: 4, MaxFractionDigits: 2}, "1.5", "1.5000"},
+		{"rounds half away from zero", &Formatter{Locale: MustLookupLocale("en-US"), MaxFractionDigits: 1}, "-2.25", "-2.3"},
+		{"truncates", &Formatter{Locale: MustLookupLocale("en-US"), MaxFractionDigits: 2, Truncate: true}, "-0.999", "-0.99"},
+
+		{"en-US currency", money("en-US", "$"), "1234.5", "$1,234.50"},
+		{"en-US negative currency", money("en-US", "$"), "-12.5", "-$12.50"},
+		{"de-DE currency", money("de-DE", "€"), "1234.5", "1.234,50 €"},
+		{"de-DE negative currency", money("de-DE", "€"), "-1234.5", "-1.234,50 €"},
+		{"pt-BR currency", money("pt-BR", "R$"), "10", "R$ 10,00"},
+		{"ja-JP currency", &Formatter{Locale: MustLookupLocale("ja-JP"), Currency: "¥"}, "1234", "¥1,234"},
+		{"accounting", &Formatter{Locale: MustLookupLocale("en-US"), MinFractionDigits: 2, MaxFractionDigits: 2, Currency: "$", Accounting: true}, "-12.5", "($12.50)"},
+
+		{"compact below first unit", compact("en-US"), "999", "999"},
+		{"compact thousands", compact("en-US"), "1234", "1.2K"},
+		{"compact millions", compact("en-US"), "3400000", "3.4M"},
+		{"compact billions", compact("en-US"), "5600000000", "5.6B"},
+		{"compact negative", compact("en-US"), "-1250", "-1.3K"},
+		{"compact rounds into next unit", compact("en-US"), "999960", "1M"},
+		{"compact rounds into first unit", compact("en-US"), "999.96", "1K"},
+		{"compact stays below first unit", compact("en-US"), "999.94", "999.9"},
+		{"compact beyond last unit", compact("en-US"), "1234567000000000", "1,234.6T"},
+		{"compact de-DE skips thousands", compact("de-DE"), "1234", "1.234"},
+		{"compact de-DE millions", compact("de-DE"), "1234567", "1,2 Mio."},
+		{"compact ja-JP", compact("ja-JP"), "123456", "12.3万"},
+		{"compact en-IN crore", compact("en-IN"), "25000000", "2.5Cr"},
+		{"compact currency", &Formatter{Locale: MustLookupLocale("en-US"), MaxFractionDigits: 1, Compact: true, Currency: "$"}, "1500000", "$1.5M"},
+
+		{"tiny subscript", tiny, "0.000001234", "0.0₅123"},
+		{"tiny two digit subscript", tiny, "0.000000000000123456", "0.0₁₂123"},
+		{"tiny below subscript threshold", tiny, "0.001234", "0.00123"},
+		{"tiny keeps max fraction digits", tiny, "0.5", "0.5"},
+		{"tiny ignores large values", tiny, "12.3456", "12.35"},
+		{"tiny truncates", &Formatter{Locale: MustLookupLocale("en-US"), SignificantDigits: 3, Truncate: true}, "0.0012349", "0.00123"},
+		{"compact truncates", &Formatter{Locale: MustLookupLocale("en-US"), MaxFractionDigits: 1, Compact: true, Truncate: true}, "999960", "999.9K"},
+		{"tiny de-DE", &Formatter{Locale: MustLookupLocale("de-DE"), SignificantDigits: 4, SubscriptZeros: 3}, "0.00001234567", "0,0₄1235"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.f.Format(RequireFromString(tt.value)); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFormatterUnits(t *testing.T) {
+	f := &Formatter{Locale: MustLookupLocale("de-DE"), MaxFractionDigits: 4}
+	wei, _ := new(big.Int).SetString("1234567891234567890000", 10)
+
+	if got := f.FormatUnits(wei, 18); got != "1.234,5679" {
+		t.Errorf("Expected %q, got %q", "1.234,5679", got)
+	}
+	if got := f.FormatUnits(nil, 18); got != "0" {
+		t.Errorf("Expected %q, got %q", "0", got)
+	}
+	if got := f.FormatBigInt(NewBigInt(wei), 18); got != "1.234,5679" {
+		t.Errorf("Expected %q, got %q", "1.234,5679", got)
+	}
+	if got := f.FormatBigInt(&BigInt{}, 6); got != "0" {
+		t.Errorf("Expected %q, got %q", "0", got)
+	}
+	if got := f.FormatUnits(big.NewInt(-1500000), 6); got != "-1,5" {
+		t.Errorf("Expected %q, got %q", "-1,5", got)
+	}
+	if wei.String() != "1234567891234567890000" {
+		t.Errorf("FormatUnits modified its argument: %s", wei.String())
+	}
+}
+
+func BenchmarkFormatterFormat(b *testing.B) {
+	f := &Formatter{Locale: MustLookupLocale("en-US"), MinFractionDigits: 2, MaxFractionDigits: 2, Currency: "$"}
+	d := RequireFromString("-1234567.891")
+	for i := 0; i < b.N; i++ {
+		f.Format(d)
+	}
+}
@@ -0,0 +1,414 @@
+package safem
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseError describes malformed input rejected by a Parser. Offset is the byte
+// offset in Input where the problem was found.
+type ParseError struct {
+	Input  string
+	Offset int
+	Msg    string
+
+	// Err is ErrInvalidInput for malformed input and ErrPrecisionLoss for amounts
+	// with more fraction digits than the requested decimals.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("cannot parse %q at offset %d: %s", e.Input, e.Offset, e.Msg)
+}
+
+// Unwrap returns the sentinel error classifying the failure.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Parser reads numbers written for a locale: the locale's group and decimal
+// separators, an optional currency symbol before or after the number, a leading
+// minus or plus sign, and accounting negatives in parentheses such as "($12.50)".
+//
+// Grouping is optional, but when present every group must have the locale's
+// size, so "1.234,56" parses in de-DE while "1.23,456" is rejected. Separators
+// that only differ in the kind of space, or ' for ’, are treated as equal.
+//
+// A Parser is a plain value; it is safe for concurrent use as long as its fields
+// are not modified.
+type Parser struct {
+	// Locale supplies the separators and the minus sign.
+	Locale Locale
+
+	// Currencies lists the symbols accepted before or after the number, e.g. "$"
+	// or "EUR". Any other symbol is rejected.
+	Currencies []string
+}
+
+// NewParser returns a Parser for the locale tag that accepts the given currency
+// symbols.
+//
+// Returns error when the locale is unknown, see LookupLocale.
+func NewParser(tag string, currencies ...string) (*Parser, error) {
+	loc, err := LookupLocale(tag)
+	if err != nil {
+		return nil, err
+	}
+	return &Parser{Locale: loc, Currencies: currencies}, nil
+}
+
+// NewFromLocaleString returns a new Decimal from a number formatted for the locale
+// tag. Unlike NewFromFormattedString it knows which character is the decimal mark,
+// so "1.234,56" in de-DE is 1234.56 rather than 1.23456.
+//
+// Example:
+//
+//	d1, err := NewFromLocaleString("1.234,56", "de-DE")
+//	d1.String() // output: "1234.56"
+//
+//	d2, err := NewFromLocaleString("(12.50)", "en-US")
+//	d2.String() // output: "-12.5"
+func NewFromLocaleString(value, tag string) (Decimal, error) {
+	p, err := NewParser(tag)
+	if err != nil {
+		return Decimal{}, err
+	}
+	return p.Parse(value)
+}
+
+// Parse returns the Decimal written in s.
+//
+// Returns a *ParseError for malformed input.
+//
+// Example:
+//
+//	p, err := NewParser("de-DE", "€")
+//	d, err := p.Parse("-1.234,50 €")
+//	d.String() // output: "-1234.5"
+func (p *Parser) Parse(s string) (Decimal, error) {
+	n, err := p.scan(s)
+	if err != nil {
+		return Decimal{}, err
+	}
+	return n.decimal(), nil
+}
+
+// ParseUnits returns the amount written in s as integer token units with the
+// given number of decimals, e.g. "1,5" with 18 decimals in de-DE is 1.5e18 wei.
+//
+// Returns a *ParseError for malformed input, and one wrapping ErrPrecisionLoss,
+// positioned at the first extra digit, when s has more than decimals fraction digits.
+//
+// Example:
+//
+//	p, err := NewParser("en-US", "$")
+//	units, err := p.ParseUnits("$1,234.56", 6)
+//	units.String() // output: "1234560000"
+func (p *Parser) ParseUnits(s string, decimals int32) (*big.Int, error) {
+	if decimals < 0 {
+		return nil, fmt.Errorf("cannot parse units with negative decimals %d", decimals)
+	}
+	n, err := p.scan(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if extra := len(n.fracPart) - int(decimals); extra > 0 && strings.Trim(n.fracPart[decimals:], "0") != "" {
+		return nil, &ParseError{
+			Input:  s,
+			Offset: n.fracOffset + int(decimals),
+			Msg:    fmt.Sprintf("more than %d fraction digits", decimals),
+			Err:    ErrPrecisionLoss,
+		}
+	}
+
+	return n.decimal().Shift(decimals).BigInt(), nil
+}
+
+// parsedNumber holds the validated pieces of a parsed number.
+type parsedNumber struct {
+	neg        bool
+	intPart    string
+	fracPart   string
+	fracOffset int
+}
+
+func (n parsedNumber) decimal() Decimal {
+	s := n.intPart
+	if n.fracPart != "" {
+		s += "." + n.fracPart
+	}
+	if n.neg {
+		s = "-" + s
+	}
+	// the digits have been validated, so this cannot fail
+	d, _ := NewFromString(s)
+	return d
+}
+
+// numberScanner walks the input one rune at a time, keeping byte offsets for errors.
+type numberScanner struct {
+	p   *Parser
+	s   string
+	pos int
+	end int
+}
+
+func (sc *numberScanner) fail(offset int, format string, args ...interface{}) error {
+	return &ParseError{Input: sc.s, Offset: offset, Msg: fmt.Sprintf(format, args...), Err: ErrInvalidInput}
+}
+
+func (sc *numberScanner) peek() (rune, int) {
+	if sc.pos >= sc.end {
+		return utf8.RuneError, 0
+	}
+	return utf8.DecodeRuneInString(sc.s[sc.pos:sc.end])
+}
+
+func (sc *numberScanner) skipSpaces() {
+	for {
+		r, size := sc.peek()
+		if size == 0 || !isSpaceSeparator(r) {
+			return
+		}
+		sc.pos += size
+	}
+}
+
+// sign consumes a minus or plus sign and reports which one it found.
+func (sc *numberScanner) sign() (found, neg bool) {
+	rest := sc.s[sc.pos:sc.end]
+	for _, minus := range []string{sc.p.Locale.Minus, "-", "\u2212"} {
+		if minus != "" && strings.HasPrefix(rest, minus) {
+			sc.pos += len(minus)
+			return true, true
+		}
+	}
+	if strings.HasPrefix(rest, "+") {
+		sc.pos++
+		return true, false
+	}
+	return false, false
+}
+
+// currency consumes the longest accepted currency symbol at the current position.
+func (sc *numberScanner) currency() bool {
+	rest := sc.s[sc.pos:sc.end]
+	best := 0
+	for _, c := range sc.p.Currencies {
+		if c != "" && len(c) > best && strings.HasPrefix(rest, c) {
+			best = len(c)
+		}
+	}
+	sc.pos += best
+	return best > 0
+}
+
+func (p *Parser) scan(s string) (parsedNumber, error) {
+	sc := &numberScanner{p: p, s: s, end: len(s)}
+	var n parsedNumber
+
+	// trim surrounding spaces
+	sc.skipSpaces()
+	for sc.end > sc.pos {
+		r, size := utf8.DecodeLastRuneInString(s[sc.pos:sc.end])
+		if !isSpaceSeparator(r) {
+			break
+		}
+		sc.end -= size
+	}
+	if sc.pos == sc.end {
+		return n, sc.fail(sc.pos, "empty input")
+	}
+
+	parens := false
+	if s[sc.pos] == '(' {
+		open := sc.pos
+		if s[sc.end-1] != ')' {
+			return n, sc.fail(sc.end, "missing closing parenthesis for '(' at offset %d", open)
+		}
+		parens = true
+		sc.pos++
+		sc.end--
+		sc.skipSpaces()
+	}
+
+	signOffset := sc.pos
+	hasSign, neg := sc.sign()
+	sc.skipSpaces()
+	hasCurrency := sc.currency()
+	if hasCurrency {
+		sc.skipSpaces()
+		if !hasSign {
+			signOffset = sc.pos
+			hasSign, neg = sc.sign()
+		}
+	}
+	if hasSign && parens {
+		return n, sc.fail(signOffset, "sign inside accounting parentheses")
+	}
+	n.neg = neg || parens
+
+	if err := sc.digits(&n); err != nil {
+		return n, err
+	}
+
+	if sc.pos < sc.end && !hasCurrency {
+		afterNumber := sc.pos
+		sc.skipSpaces()
+		if !sc.currency() {
+			sc.pos = afterNumber
+		}
+	}
+	if sc.pos < sc.end {
+		r, _ := sc.peek()
+		if r == ')' || r == '(' {
+			return n, sc.fail(sc.pos, "unbalanced parenthesis")
+		}
+		return n, sc.fail(sc.pos, "unexpected character %q", r)
+	}
+
+	if strings.Trim(n.intPart, "0") == "" && strings.Trim(n.fracPart, "0") == "" {
+		n.neg = false
+	}
+	return n, nil
+}
+
+// digits consumes the integer part, with optional group separators, and the
+// optional fraction, validating the group sizes against the locale.
+func (sc *numberScanner) digits(n *parsedNumber) error {
+	loc := sc.p.Locale
+	start := sc.pos
+
+	var intDigits strings.Builder
+	var groups []int
+	var sepOffsets []int
+	groupLen := 0
+
+	for sc.pos < sc.end {
+		r, size := sc.peek()
+		if isDigit(r) {
+			intDigits.WriteRune(r)
+			groupLen++
+			sc.pos += size
+			continue
+		}
+		if sepLen := loc.matchGroup(sc.s[sc.pos:sc.end]); sepLen > 0 {
+			next, _ := utf8.DecodeRuneInString(sc.s[sc.pos+sepLen : sc.end])
+			if !isDigit(next) || groupLen == 0 {
+				// a separator not between digits ends the number, e.g. the space before "€"
+				break
+			}
+			groups = append(groups, groupLen)
+			sepOffsets = append(sepOffsets, sc.pos)
+			groupLen = 0
+			sc.pos += sepLen
+			continue
+		}
+		break
+	}
+	groups = append(groups, groupLen)
+
+	fracOffset := -1
+	if loc.Decimal != "" && strings.HasPrefix(sc.s[sc.pos:sc.end], loc.Decimal) {
+		sc.pos += len(loc.Decimal)
+		fracOffset = sc.pos
+		for sc.pos < sc.end {
+			r, size := sc.peek()
+			if !isDigit(r) {
+				break
+			}
+			sc.pos += size
+		}
+		if sc.pos == fracOffset {
+			return sc.fail(sc.pos, "expected digit after decimal separator")
+		}
+	}
+
+	if intDigits.Len() == 0 {
+		if fracOffset < 0 {
+			if sc.pos < sc.end {
+				r, _ := sc.peek()
+				return sc.fail(sc.pos, "expected digit, found %q", r)
+			}
+			return sc.fail(sc.pos, "expected digit")
+		}
+		if len(groups) > 1 {
+			return sc.fail(start, "expected digit")
+		}
+	}
+
+	if err := sc.checkGroups(groups, sepOffsets); err != nil {
+		return err
+	}
+
+	n.intPart = intDigits.String()
+	if n.intPart == "" {
+		n.intPart = "0"
+	}
+	if fracOffset >= 0 {
+		n.fracPart = sc.s[fracOffset:sc.pos]
+		n.fracOffset = fracOffset
+	}
+	return nil
+}
+
+// checkGroups validates the sizes of the digit groups, listed from the left.
+func (sc *numberScanner) checkGroups(groups, sepOffsets []int) error {
+	if len(groups) == 1 {
+		return nil
+	}
+	loc := sc.p.Locale
+	size := loc.GroupSize
+	if size <= 0 {
+		return sc.fail(sepOffsets[0], "locale %s has no digit grouping", loc.Tag)
+	}
+	secondary := loc.SecondaryGroupSize
+	if secondary <= 0 {
+		secondary = size
+	}
+
+	last := len(groups) - 1
+	if groups[last] != size {
+		return sc.fail(sepOffsets[last-1], "group of %d digits after separator, expected %d", groups[last], size)
+	}
+	for i := 1; i < last; i++ {
+		if groups[i] != secondary {
+			return sc.fail(sepOffsets[i-1], "group of %d digits after separator, expected %d", groups[i], secondary)
+		}
+	}
+	if groups[0] > secondary {
+		return sc.fail(sepOffsets[0], "leading group of %d digits, expected at most %d", groups[0], secondary)
+	}
+	return nil
+}
+
+// matchGroup returns the byte length of the group separator at the start of s,
+// or 0. Space separators match any kind of space, and ’ also matches '.
+func (l Locale) matchGroup(s string) int {
+	if l.Group == "" {
+		return 0
+	}
+	if strings.HasPrefix(s, l.Group) {
+		return len(l.Group)
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	g, _ := utf8.DecodeRuneInString(l.Group)
+	switch {
+	case isSpaceSeparator(g) && isSpaceSeparator(r):
+		return size
+	case g == '’' && r == '\'':
+		return size
+	}
+	return 0
+}
+
+func isSpaceSeparator(r rune) bool {
+	return r == ' ' || r == '\u00a0' || r == '\u202f' || r == '\t'
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
@@ -0,0 +1,178 @@
+package safem
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParserParse(t *testing.T) {
+	parser := func(tag string, currencies ...string) *Parser {
+		p, err := NewParser(tag, currencies...)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return p
+	}
+
+	tests := []struct {
+		name     string
+		p        *Parser
+		input    string
+		expected string
+	}{
+		{"en-US plain", parser("en-US"), "1234.56", "1234.56"},
+		{"en-US grouped", parser("en-US"), "1,234,567.891", "1234567.891"},
+		{"en-US negative", parser("en-US"), "-1,234.5", "-1234.5"},
+		{"en-US plus", parser("en-US"), "+12", "12"},
+		{"en-US leading decimal", parser("en-US"), ".25", "0.25"},
+		{"en-US surrounding spaces", parser("en-US"), "  42 ", "42"},
+		{"en-US currency prefix", parser("en-US", "$"), "$1,234.50", "1234.5"},
+		{"en-US negative currency", parser("en-US", "$"), "-$12.50", "-12.5"},
+		{"en-US sign after currency", parser("en-US", "$"), "$-12.50", "-12.5"},
+		{"en-US currency code suffix", parser("en-US", "USD"), "5000 USD", "5000"},
+		{"longest currency wins", parser("en-US", "$", "US$"), "US$7", "7"},
+		{"accounting negative", parser("en-US"), "(12.50)", "-12.5"},
+		{"accounting with currency", parser("en-US", "$"), "($1,012.50)", "-1012.5"},
+		{"accounting zero", parser("en-US"), "(0.00)", "0"},
+		{"de-DE grouped", parser("de-DE"), "1.234,56", "1234.56"},
+		{"de-DE currency suffix", parser("de-DE", "€"), "-1.234,50 €", "-1234.5"},
+		{"de-DE single group", parser("de-DE"), "1.234", "1234"},
+		{"de-CH apostrophe", parser("de-CH", "CHF"), "CHF 1'234.50", "1234.5"},
+		{"de-CH typographic apostrophe", parser("de-CH"), "1’234’567", "1234567"},
+		{"fr-FR narrow space", parser("fr-FR", "€"), "1 234 567,89 €", "1234567.89"},
+		{"fr-FR plain space", parser("fr-FR", "€"), "1 234,5 €", "1234.5"},
+		{"sv-SE minus", parser("sv-SE"), "−1 234,5", "-1234.5"},
+		{"en-IN lakh grouping", parser("en-IN"), "12,34,56,789.5", "123456789.5"},
+		{"es-ES ungrouped thousands", parser("es-ES"), "1234,5", "1234.5"},
+		{"round trip with formatter", parser("pt-BR", "R$"), "R$ 1.234.567,89", "1234567.89"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := tt.p.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !d.Equal(RequireFromString(tt.expected)) {
+				t.Errorf("Expected %s, got %s", tt.expected, d.String())
+			}
+		})
+	}
+}
+
+func TestParserErrors(t *testing.T) {
+	en, _ := NewParser("en-US", "$")
+	de, _ := NewParser("de-DE", "€")
+	in, _ := NewParser("en-IN")
+
+	tests := []struct {
+		name   string
+		p      *Parser
+		input  string
+		offset int
+	}{
+		{"empty", en, "", 0},
+		{"only spaces", en, "   ", 3},
+		{"de-DE decimal in en-US", en, "1.234,56", 5},
+		{"en-US number in de-DE", de, "1,234.56", 5},
+		{"short group", de, "1.23,456", 1},
+		{"long group", en, "12,3456", 2},
+		{"long leading group", en, "1234,567", 4},
+		{"bad secondary group", in, "1,234,567", 1},
+		{"group in fraction", en, "1.234,5", 5},
+		{"unknown currency", en, "€12", 0},
+		{"two signs", en, "--1", 1},
+		{"missing digits", en, "$", 1},
+		{"missing fraction digits", en, "12.", 3},
+		{"missing closing parenthesis", en, "(12.50", 6},
+		{"unbalanced parenthesis", en, "12.50)", 5},
+		{"sign in parentheses", en, "(-12.50)", 1},
+		{"letters", en, "12abc", 2},
+		{"trailing separator", en, "1,234,", 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.p.Parse(tt.input)
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("Expected *ParseError, got %v", err)
+			}
+			if perr.Offset != tt.offset {
+				t.Errorf("Expected offset %d, got %d (%v)", tt.offset, perr.Offset, err)
+			}
+			if !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("Expected error to wrap ErrInvalidInput: %v", err)
+			}
+		})
+	}
+}
+
+func TestParserParseUnits(t *testing.T) {
+	p, _ := NewParser("de-DE", "ETH")
+
+	units, err := p.ParseUnits("1.234,5 ETH", 18)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if units.String() != "1234500000000000000000" {
+		t.Errorf("Expected %s, got %s", "1234500000000000000000", units.String())
+	}
+
+	units, err = p.ParseUnits("-0,100", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if units.String() != "-10" {
+		t.Errorf("Expected %s, got %s", "-10", units.String())
+	}
+
+	_, err = p.ParseUnits("0,1234567", 6)
+	var perr *ParseError
+	if !errors.As(err, &perr) || !errors.Is(err, ErrPrecisionLoss) {
+		t.Fatalf("Expected precision loss ParseError, got %v", err)
+	}
+	if perr.Offset != 8 {
+		t.Errorf("Expected offset %d, got %d", 8, perr.Offset)
+	}
+
+	if _, err := p.ParseUnits("1", -1); err == nil {
+		t.Errorf("Expected error for negative decimals")
+	}
+}
+
+func TestNewFromLocaleString(t *testing.T) {
+	d, err := NewFromLocaleString("1.234,56", "de-DE")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.String() != "1234.56" {
+		t.Errorf("Expected %s, got %s", "1234.56", d.String())
+	}
+
+	if _, err := NewFromLocaleString("1", "xx"); err == nil {
+		t.Errorf("Expected error for unknown locale")
+	}
+}
+
+func TestParserRoundTripsFormatter(t *testing.T) {
+	values := []string{"0", "-1", "1234567.89", "-0.5", "999999999999.99", "12"}
+	for _, tag := range LocaleTags() {
+		f := &Formatter{Locale: MustLookupLocale(tag), MinFractionDigits: 2, MaxFractionDigits: 2, Currency: "XYZ"}
+		p := &Parser{Locale: f.Locale, Currencies: []string{"XYZ"}}
+		for _, v := range values {
+			for _, accounting := range []bool{false, true} {
+				f.Accounting = accounting
+				s := f.Format(RequireFromString(v))
+				d, err := p.Parse(s)
+				if err != nil {
+					t.Errorf("%s: cannot parse %q: %v", tag, s, err)
+					continue
+				}
+				if !d.Equal(RequireFromString(v)) {
+					t.Errorf("%s: Expected %s, got %s from %q", tag, v, d.String(), s)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package margin
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/morpheum-labs/safem"
+)
+
+// Account is a cross-margin account: its positions share Balance as margin, so
+// the profit of one position supports the others. The Margin of the positions
+// is ignored, and their markets must have the same QuoteDecimals.
+type Account struct {
+	// Balance is the collateral in base units of the quote token, excluding
+	// unrealized PnL.
+	Balance *big.Int
+
+	Positions []Position
+}
+
+func (a *Account) validate() error {
+	if a.Balance == nil {
+		return fmt.Errorf("cannot compute margin: balance is nil: %w", safem.ErrInvalidInput)
+	}
+	for i := range a.Positions {
+		p := &a.Positions[i]
+		if err := p.validate(); err != nil {
+			return fmt.Errorf("position %d: %w", i, err)
+		}
+		if p.Market.QuoteDecimals != a.Positions[0].Market.QuoteDecimals {
+			return fmt.Errorf("cannot compute margin: position %d has %d quote decimals, position 0 has %d: %w",
+				i, p.Market.QuoteDecimals, a.Positions[0].Market.QuoteDecimals, safem.ErrUnitMismatch)
+		}
+	}
+	return nil
+}
+
+// MaintenanceMargin returns the sum of the maintenance margins of the positions.
+func (a *Account) MaintenanceMargin() (*big.Int, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	return a.sum(-1, (*Position).MaintenanceMargin)
+}
+
+// UnrealizedPnL returns the sum of the unrealized PnL of the positions.
+func (a *Account) UnrealizedPnL() (*big.Int, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	return a.sum(-1, (*Position).UnrealizedPnL)
+}
+
+// MarginRatio returns the maintenance margin of the account over its margin
+// balance, Balance + UnrealizedPnL, rounded up to RatioPrecision places. All
+// positions are liquidated when the ratio reaches 1. Returns ErrBankrupt when
+// the margin balance is not positive.
+func (a *Account) MarginRatio() (safem.Decimal, error) {
+	mm, err := a.MaintenanceMargin()
+	if err != nil {
+		return safem.Decimal{}, err
+	}
+	pnl, err := a.UnrealizedPnL()
+	if err != nil {
+		return safem.Decimal{}, err
+	}
+	return ratio(mm, pnl.Add(pnl, a.Balance))
+}
+
+// LiquidationPrice returns the mark price of position i at which the account is
+// liquidated, with the other positions at their current mark prices. It is the
+// isolated liquidation price of position i with a margin of
+//
+//	Balance + sum over j != i of (UnrealizedPnL[j] - MaintenanceMargin[j])
+//
+// rounded as Position.LiquidationPrice. Returns ErrBankrupt for a short when
+// the other positions' losses exceed what the short could ever earn.
+//
+// Example:
+//
+//	// 10000 USDT, long 1 BTC at 20000, short 10 ETH at 1500 marked at 1400
+//	a.LiquidationPrice(0) // output: 9106.43 ((20000 - 10930) / 0.996)
+func (a *Account) LiquidationPrice(i int) (safem.Decimal, error) {
+	available, err := a.available(i, true)
+	if err != nil {
+		return safem.Decimal{}, err
+	}
+	return a.Positions[i].liquidationPrice(available)
+}
+
+// BankruptcyPrice returns the mark price of position i at which the margin
+// balance of the account is zero, with the other positions at their current
+// mark prices, rounded as Position.BankruptcyPrice.
+func (a *Account) BankruptcyPrice(i int) (safem.Decimal, error) {
+	available, err := a.available(i, false)
+	if err != nil {
+		return safem.Decimal{}, err
+	}
+	return a.Positions[i].bankruptcyPrice(available)
+}
+
+// available returns the margin left to position i in whole quote tokens: the
+// balance plus the PnL of the other positions, less their maintenance margin
+// when maintenance is true.
+func (a *Account) available(i int, maintenance bool) (*big.Rat, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	if i < 0 || i >= len(a.Positions) {
+		return nil, fmt.Errorf("cannot compute margin: position %d of %d: %w", i, len(a.Positions), safem.ErrInvalidInput)
+	}
+	pnl, err := a.sum(i, (*Position).UnrealizedPnL)
+	if err != nil {
+		return nil, err
+	}
+	total := pnl.Add(pnl, a.Balance)
+	if maintenance {
+		mm, err := a.sum(i, (*Position).MaintenanceMargin)
+		if err != nil {
+			return nil, err
+		}
+		total.Sub(total, mm)
+	}
+	return a.Positions[i].Market.tokens(total).Rat(), nil
+}
+
+// sum returns the sum of f over the positions other than skip.
+func (a *Account) sum(skip int, f func(*Position) (*big.Int, error)) (*big.Int, error) {
+	total := new(big.Int)
+	for j := range a.Positions {
+		if j == skip {
+			continue
+		}
+		v, err := f(&a.Positions[j])
+		if err != nil {
+			return nil, fmt.Errorf("position %d: %w", j, err)
+		}
+		total.Add(total, v)
+	}
+	return total, nil
+}
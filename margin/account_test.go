@@ -0,0 +1,128 @@
+package margin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/morpheum-labs/safem"
+)
+
+// ethMarket has 0.5% maintenance up to 100000 USDT and 1% above.
+func ethMarket() *Market {
+	return &Market{
+		Tiers: []Tier{
+			{d("100000"), d("0.005"), d("75")},
+			{d("0"), d("0.01"), d("50")},
+		},
+		QuoteDecimals: 6,
+		PriceDecimals: 2,
+	}
+}
+
+// account holds 10000 USDT, a long of 1 BTC at 20000 and a short of 10 ETH at
+// 1500 marked at 1400.
+func account() *Account {
+	return &Account{
+		Balance: usdt("10000"),
+		Positions: []Position{
+			{Market: btcMarket(), Side: Long, Size: d("1"), EntryPrice: d("20000"), MarkPrice: d("20000")},
+			{Market: ethMarket(), Side: Short, Size: d("10"), EntryPrice: d("1500"), MarkPrice: d("1400")},
+		},
+	}
+}
+
+func TestAccount(t *testing.T) {
+	a := account()
+
+	// 20000 * 0.004 + 14000 * 0.005 = 80 + 70
+	mm, err := a.MaintenanceMargin()
+	if err != nil || mm.String() != "150000000" {
+		t.Errorf("MaintenanceMargin: Expected 150000000, got %v %v", mm, err)
+	}
+	// 0 + 10 * 100
+	pnl, err := a.UnrealizedPnL()
+	if err != nil || pnl.String() != "1000000000" {
+		t.Errorf("UnrealizedPnL: Expected 1000000000, got %v %v", pnl, err)
+	}
+	// 150 / 11000 = 0.0136363...
+	ratio, err := a.MarginRatio()
+	if err != nil || ratio.String() != "0.013636363636363637" {
+		t.Errorf("MarginRatio: Expected 0.013636363636363637, got %s %v", ratio, err)
+	}
+
+	tests := []struct {
+		i           int
+		liquidation string
+		bankruptcy  string
+	}{
+		// BTC with 10000 + 1000 - 70 = 10930: (20000 - 10930) / 0.996 = 9106.4257...
+		// and 20000 - 11000
+		{0, "9106.43", "9000"},
+		// ETH with 10000 + 0 - 80 = 9920: (15000 + 9920) / 10.05 = 2479.6019...
+		// and 1500 + 10000 / 10
+		{1, "2479.6", "2500"},
+	}
+	for _, tt := range tests {
+		liq, err := a.LiquidationPrice(tt.i)
+		if err != nil || liq.String() != tt.liquidation {
+			t.Errorf("LiquidationPrice(%d): Expected %s, got %s %v", tt.i, tt.liquidation, liq, err)
+		}
+		bankrupt, err := a.BankruptcyPrice(tt.i)
+		if err != nil || bankrupt.String() != tt.bankruptcy {
+			t.Errorf("BankruptcyPrice(%d): Expected %s, got %s %v", tt.i, tt.bankruptcy, bankrupt, err)
+		}
+	}
+
+	// the isolated long with the same margin is liquidated at the same price
+	isolated := a.Positions[0]
+	isolated.Margin = usdt("10930")
+	if liq, err := isolated.LiquidationPrice(); err != nil || liq.String() != "9106.43" {
+		t.Errorf("Expected 9106.43, got %s %v", liq, err)
+	}
+}
+
+func TestAccountErrors(t *testing.T) {
+	a := account()
+	a.Positions[1].Market.QuoteDecimals = 18
+	if _, err := a.MarginRatio(); !errors.Is(err, safem.ErrUnitMismatch) {
+		t.Errorf("Expected ErrUnitMismatch, got %v", err)
+	}
+
+	a = account()
+	for _, i := range []int{-1, 2} {
+		if _, err := a.LiquidationPrice(i); !errors.Is(err, safem.ErrInvalidInput) {
+			t.Errorf("LiquidationPrice(%d): Expected ErrInvalidInput, got %v", i, err)
+		}
+	}
+
+	a.Balance = nil
+	if _, err := a.MaintenanceMargin(); !errors.Is(err, safem.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput, got %v", err)
+	}
+
+	// the ETH short loses 10 * 2500 = 25000, more than the balance
+	a = account()
+	a.Positions[1].MarkPrice = d("4000")
+	if _, err := a.MarginRatio(); !errors.Is(err, ErrBankrupt) {
+		t.Errorf("Expected ErrBankrupt, got %v", err)
+	}
+	// BTC with 10000 - 25000 - 40000 * 0.005 = -15200: 35200 / 0.996 = 35341.3654...
+	if liq, err := a.LiquidationPrice(0); err != nil || liq.String() != "35341.37" {
+		t.Errorf("Expected 35341.37, got %s %v", liq, err)
+	}
+
+	// a short BTC whose other positions have lost more than it could earn
+	a = &Account{
+		Balance: usdt("100"),
+		Positions: []Position{
+			{Market: btcMarket(), Side: Short, Size: d("0.001"), EntryPrice: d("20000"), MarkPrice: d("20000")},
+			{Market: ethMarket(), Side: Long, Size: d("10"), EntryPrice: d("1500"), MarkPrice: d("1000")},
+		},
+	}
+	if _, err := a.LiquidationPrice(0); !errors.Is(err, ErrBankrupt) {
+		t.Errorf("Expected ErrBankrupt, got %v", err)
+	}
+	if _, err := a.BankruptcyPrice(0); !errors.Is(err, ErrBankrupt) {
+		t.Errorf("Expected ErrBankrupt, got %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+// Package margin computes the margin of perpetual futures positions on
+// safem.Decimal prices and token-unit amounts: initial and maintenance margin,
+// unrealized PnL, margin ratio, and liquidation and bankruptcy prices, for
+// isolated positions and for cross-margin accounts.
+//
+// Maintenance margin follows tiers by notional value, with the maintenance
+// amount of each tier derived so that the margin is continuous across tiers, as
+// on Binance futures. Amounts are *big.Int in base units of the market's quote
+// token, e.g. 6-decimal USDC units; sizes and prices are Decimal.
+//
+// Every result is rounded in the exchange's favour: margin requirements up,
+// PnL down, margin ratios up, and liquidation and bankruptcy prices towards the
+// mark price, so that a position is never liquidated later than exact
+// arithmetic would liquidate it.
+//
+// Example:
+//
+//	btc := &margin.Market{Tiers: tiers, QuoteDecimals: 6, PriceDecimals: 2}
+//	p := margin.Position{Market: btc, Side: margin.Long, Size: one, EntryPrice: entry, MarkPrice: mark, Margin: collateral}
+//	liq, _ := p.LiquidationPrice()
+package margin
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/morpheum-labs/safem"
+	"github.com/morpheum-labs/safem/internal/bigint"
+)
+
+var (
+	ErrLeverageTooHigh = errors.New("leverage above the maximum of the tier")
+	ErrBankrupt        = errors.New("margin balance not positive")
+)
+
+// RatioPrecision is the number of decimal places of margin ratios.
+const RatioPrecision = 18
+
+// Side is the direction of a position.
+type Side uint8
+
+const (
+	Long Side = iota
+	Short
+)
+
+// String returns "long" or "short".
+func (s Side) String() string {
+	switch s {
+	case Long:
+		return "long"
+	case Short:
+		return "short"
+	}
+	return fmt.Sprintf("Side(%d)", uint8(s))
+}
+
+// Tier is a maintenance margin tier: positions with a notional value up to
+// MaxNotional, and above the MaxNotional of the previous tier, need a
+// maintenance margin of MaintenanceRate of their notional, less the tier's
+// maintenance amount, and may use at most MaxLeverage.
+type Tier struct {
+	// MaxNotional is the highest notional of the tier in whole quote tokens. Zero
+	// on the last tier means no limit.
+	MaxNotional safem.Decimal
+
+	// MaintenanceRate is the maintenance margin rate, e.g. 0.004 for 0.4%.
+	MaintenanceRate safem.Decimal
+
+	// MaxLeverage is the highest leverage allowed in the tier, e.g. 125.
+	MaxLeverage safem.Decimal
+}
+
+// Market holds the margin parameters of a market.
+type Market struct {
+	// Tiers are the maintenance margin tiers in increasing order of MaxNotional.
+	Tiers []Tier
+
+	// QuoteDecimals are the decimals of the quote token in which margin, PnL and
+	// balances are held.
+	QuoteDecimals uint8
+
+	// PriceDecimals are the decimal places of liquidation and bankruptcy prices,
+	// e.g. 2 for a tick size of 0.01.
+	PriceDecimals int32
+}
+
+// tier returns the maintenance rate, maintenance amount and maximum leverage of
+// the tier of notional. The maintenance amount of tier i is
+//
+//	amount[i] = amount[i-1] + MaxNotional[i-1] * (rate[i] - rate[i-1])
+//
+// which makes notional * rate - amount continuous across tiers. Returns
+// ErrInvalidInput for invalid tiers and ErrTooLarge when notional is above the
+// last tier.
+func (m *Market) tier(notional safem.Decimal) (rate, amount, maxLeverage safem.Decimal, err error) {
+	if m == nil || len(m.Tiers) == 0 {
+		return rate, amount, maxLeverage, fmt.Errorf("cannot find tier: no tiers: %w", safem.ErrInvalidInput)
+	}
+	amount = safem.New(0, 0)
+	floor, prevRate := safem.New(0, 0), safem.New(0, 0)
+	for i, t := range m.Tiers {
+		last := i == len(m.Tiers)-1
+		if t.MaintenanceRate.Sign() < 0 || t.MaintenanceRate.Cmp(safem.New(1, 0)) >= 0 || t.MaintenanceRate.LessThan(prevRate) {
+			return rate, amount, maxLeverage, fmt.Errorf("cannot find tier: tier %d has rate %s: %w", i, t.MaintenanceRate, safem.ErrInvalidInput)
+		}
+		if t.MaxLeverage.Sign() <= 0 {
+			return rate, amount, maxLeverage, fmt.Errorf("cannot find tier: tier %d has max leverage %s: %w", i, t.MaxLeverage, safem.ErrInvalidInput)
+		}
+		unbounded := last && t.MaxNotional.IsZero()
+		if !unbounded && t.MaxNotional.Cmp(floor) <= 0 {
+			return rate, amount, maxLeverage, fmt.Errorf("cannot find tier: tier %d has max notional %s: %w", i, t.MaxNotional, safem.ErrInvalidInput)
+		}
+
+		amount = amount.Add(floor.Mul(t.MaintenanceRate.Sub(prevRate)))
+		if unbounded || notional.Cmp(t.MaxNotional) <= 0 {
+			return t.MaintenanceRate, amount, t.MaxLeverage, nil
+		}
+		floor, prevRate = t.MaxNotional, t.MaintenanceRate
+	}
+	return rate, amount, maxLeverage, fmt.Errorf("cannot find tier: notional %s above the last tier: %w", notional, safem.ErrTooLarge)
+}
+
+// MaintenanceAmounts returns the maintenance amount of each tier in whole quote
+// tokens, as published by exchanges next to the tiers.
+//
+// Example:
+//
+//	// 0.4% up to 50000, 0.5% up to 250000, 1% up to 1000000
+//	m.MaintenanceAmounts() // output: [0 50 1300]
+func (m *Market) MaintenanceAmounts() ([]safem.Decimal, error) {
+	if m == nil {
+		return nil, fmt.Errorf("cannot find tier: no market: %w", safem.ErrInvalidInput)
+	}
+	amounts := make([]safem.Decimal, len(m.Tiers))
+	for i, t := range m.Tiers {
+		// the tier of a notional equal to the previous tier's maximum is the previous one
+		notional := t.MaxNotional
+		if i == len(m.Tiers)-1 && notional.IsZero() && i > 0 {
+			notional = m.Tiers[i-1].MaxNotional.Add(safem.New(1, 0))
+		}
+		_, amount, _, err := m.tier(notional)
+		if err != nil {
+			return nil, err
+		}
+		amounts[i] = amount
+	}
+	return amounts, nil
+}
+
+// units returns d whole quote tokens in base units, rounded up or down.
+func (m *Market) units(d safem.Decimal, up bool) *big.Int {
+	v := d.Shift(int32(m.QuoteDecimals))
+	if up {
+		return v.Ceil().BigInt()
+	}
+	return v.Floor().BigInt()
+}
+
+// tokens returns base units of the quote token as whole tokens.
+func (m *Market) tokens(x *big.Int) safem.Decimal {
+	return safem.NewFromBigInt(x, -int32(m.QuoteDecimals))
+}
+
+// roundRat returns r rounded to places decimal places, up or down.
+func roundRat(r *big.Rat, places int32, up bool) safem.Decimal {
+	mode := big.ToNegativeInf
+	if up {
+		mode = big.ToPositiveInf
+	}
+	num, den := new(big.Int).Set(r.Num()), new(big.Int).Set(r.Denom())
+	if places >= 0 {
+		num.Mul(num, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(places)), nil))
+	} else {
+		den.Mul(den, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-places)), nil))
+	}
+	return safem.NewFromBigInt(bigint.QuoRound(num, den, mode), -places)
+}
+
+// ratio returns num / den rounded up to RatioPrecision places, or ErrBankrupt
+// when den is not positive.
+func ratio(num, den *big.Int) (safem.Decimal, error) {
+	if den.Sign() <= 0 {
+		return safem.Decimal{}, fmt.Errorf("cannot compute margin ratio: margin balance %s: %w", den, ErrBankrupt)
+	}
+	return roundRat(new(big.Rat).SetFrac(num, den), RatioPrecision, true), nil
+}
@@ -0,0 +1,126 @@
+package margin
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/morpheum-labs/safem"
+)
+
+func d(s string) safem.Decimal {
+	return safem.RequireFromString(s)
+}
+
+// btcMarket has Binance-style BTCUSDT tiers with maintenance amounts 0, 50, 1300
+// and 16300 USDT.
+func btcMarket() *Market {
+	return &Market{
+		Tiers: []Tier{
+			{d("50000"), d("0.004"), d("125")},
+			{d("250000"), d("0.005"), d("100")},
+			{d("1000000"), d("0.01"), d("50")},
+			{d("0"), d("0.025"), d("20")},
+		},
+		QuoteDecimals: 6,
+		PriceDecimals: 2,
+	}
+}
+
+func TestMaintenanceAmounts(t *testing.T) {
+	amounts, err := btcMarket().MaintenanceAmounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(amounts); got != "[0 50 1300 16300]" {
+		t.Errorf("Expected [0 50 1300 16300], got %s", got)
+	}
+
+	bounded := btcMarket()
+	bounded.Tiers = bounded.Tiers[:3]
+	amounts, err = bounded.MaintenanceAmounts()
+	if err != nil || fmt.Sprint(amounts) != "[0 50 1300]" {
+		t.Errorf("Expected [0 50 1300], got %v %v", amounts, err)
+	}
+}
+
+func TestTier(t *testing.T) {
+	tests := []struct {
+		notional                  string
+		rate, amount, maxLeverage string
+	}{
+		{"1", "0.004", "0", "125"},
+		{"50000", "0.004", "0", "125"},
+		{"50000.01", "0.005", "50", "100"},
+		{"300000", "0.01", "1300", "50"},
+		{"1000000", "0.01", "1300", "50"},
+		{"5000000", "0.025", "16300", "20"},
+	}
+	m := btcMarket()
+	for _, tt := range tests {
+		rate, amount, maxLeverage, err := m.tier(d(tt.notional))
+		if err != nil || rate.String() != tt.rate || amount.String() != tt.amount || maxLeverage.String() != tt.maxLeverage {
+			t.Errorf("tier(%s): Expected %s %s %s, got %s %s %s %v",
+				tt.notional, tt.rate, tt.amount, tt.maxLeverage, rate, amount, maxLeverage, err)
+		}
+	}
+
+	bounded := btcMarket()
+	bounded.Tiers = bounded.Tiers[:3]
+	if _, _, _, err := bounded.tier(d("1000000.01")); !errors.Is(err, safem.ErrTooLarge) {
+		t.Errorf("Expected ErrTooLarge, got %v", err)
+	}
+
+	invalid := []struct {
+		name  string
+		tiers []Tier
+	}{
+		{"no tiers", nil},
+		{"negative rate", []Tier{{d("0"), d("-0.01"), d("10")}}},
+		{"rate of 1", []Tier{{d("0"), d("1"), d("10")}}},
+		{"decreasing rate", []Tier{{d("100"), d("0.01"), d("10")}, {d("0"), d("0.005"), d("10")}}},
+		{"decreasing notional", []Tier{{d("100"), d("0.01"), d("10")}, {d("100"), d("0.02"), d("10")}}},
+		{"unbounded middle tier", []Tier{{d("0"), d("0.01"), d("10")}, {d("100"), d("0.02"), d("10")}}},
+		{"zero leverage", []Tier{{d("0"), d("0.01"), d("0")}}},
+	}
+	for _, tt := range invalid {
+		m := &Market{Tiers: tt.tiers, QuoteDecimals: 6}
+		if _, _, _, err := m.tier(d("1000")); !errors.Is(err, safem.ErrInvalidInput) {
+			t.Errorf("%s: Expected ErrInvalidInput, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestRoundRat(t *testing.T) {
+	tests := []struct {
+		num, den int64
+		places   int32
+		up       bool
+		expected string
+	}{
+		{1, 3, 2, true, "0.34"},
+		{1, 3, 2, false, "0.33"},
+		{-1, 3, 2, true, "-0.33"},
+		{-1, 3, 2, false, "-0.34"},
+		{2, 3, 0, true, "1"},
+		{-2, 3, 0, false, "-1"},
+		{1, 4, 2, true, "0.25"},
+		{-1, 4, 2, false, "-0.25"},
+		{0, 1, 2, true, "0"},
+		{1234, 1, -2, true, "1300"},
+		{-1234, 1, -2, true, "-1200"},
+	}
+	for _, tt := range tests {
+		got := roundRat(big.NewRat(tt.num, tt.den), tt.places, tt.up)
+		if got.String() != tt.expected {
+			t.Errorf("roundRat(%d/%d, %d, %v): Expected %s, got %s", tt.num, tt.den, tt.places, tt.up, tt.expected, got)
+		}
+	}
+}
+
+func TestSideString(t *testing.T) {
+	if Long.String() != "long" || Short.String() != "short" || Side(7).String() != "Side(7)" {
+		t.Errorf("Expected long short Side(7), got %s %s %s", Long, Short, Side(7))
+	}
+}
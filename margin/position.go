@@ -0,0 +1,244 @@
+package margin
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/morpheum-labs/safem"
+)
+
+// Position is a perpetual futures position. In an isolated position Margin is
+// the collateral allocated to it; in a cross-margin Account it is ignored.
+type Position struct {
+	Market *Market
+	Side   Side
+
+	// Size is the quantity of the base asset, e.g. 1.5 BTC. It is positive for
+	// both sides.
+	Size safem.Decimal
+
+	// EntryPrice and MarkPrice are in whole quote tokens per base asset.
+	EntryPrice safem.Decimal
+	MarkPrice  safem.Decimal
+
+	// Margin is the isolated margin in base units of the quote token.
+	Margin *big.Int
+}
+
+func (p *Position) validate() error {
+	if p.Market == nil {
+		return fmt.Errorf("cannot compute margin: no market: %w", safem.ErrInvalidInput)
+	}
+	if p.Side != Long && p.Side != Short {
+		return fmt.Errorf("cannot compute margin: side %s: %w", p.Side, safem.ErrInvalidInput)
+	}
+	if p.Size.Sign() <= 0 {
+		return fmt.Errorf("cannot compute margin: size %s: %w", p.Size, safem.ErrInvalidInput)
+	}
+	if p.EntryPrice.Sign() <= 0 || p.MarkPrice.Sign() <= 0 {
+		return fmt.Errorf("cannot compute margin: entry price %s, mark price %s: %w", p.EntryPrice, p.MarkPrice, safem.ErrInvalidInput)
+	}
+	return nil
+}
+
+func (p *Position) isolatedMargin() error {
+	if p.Margin == nil {
+		return fmt.Errorf("cannot compute margin: isolated margin is nil: %w", safem.ErrInvalidInput)
+	}
+	if p.Margin.Sign() < 0 {
+		return fmt.Errorf("cannot compute margin: isolated margin %s: %w", p.Margin, safem.ErrNegativeInput)
+	}
+	return nil
+}
+
+// Notional returns the value of the position at the mark price in whole quote
+// tokens, Size * MarkPrice.
+func (p *Position) Notional() safem.Decimal {
+	return p.Size.Mul(p.MarkPrice)
+}
+
+// InitialMargin returns the margin needed to hold the position at leverage,
+// Notional / leverage, rounded up to base units. Returns ErrLeverageTooHigh when
+// leverage is above the maximum of the position's tier.
+//
+// Example:
+//
+//	// 1 BTC at 20000 USDT with 10x leverage
+//	p.InitialMargin(safem.New(10, 0)) // output: 2000000000 (2000 USDT)
+func (p *Position) InitialMargin(leverage safem.Decimal) (*big.Int, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	if leverage.Cmp(safem.New(1, 0)) < 0 {
+		return nil, fmt.Errorf("cannot compute initial margin: leverage %s: %w", leverage, safem.ErrInvalidInput)
+	}
+	notional := p.Notional()
+	_, _, maxLeverage, err := p.Market.tier(notional)
+	if err != nil {
+		return nil, err
+	}
+	if leverage.GreaterThan(maxLeverage) {
+		return nil, fmt.Errorf("cannot compute initial margin: leverage %s, maximum %s: %w", leverage, maxLeverage, ErrLeverageTooHigh)
+	}
+	im := new(big.Rat).Quo(notional.Shift(int32(p.Market.QuoteDecimals)).Rat(), leverage.Rat())
+	return roundRat(im, 0, true).BigInt(), nil
+}
+
+// MaintenanceMargin returns the margin below which the position is liquidated,
+// Notional * rate - amount of the position's tier, rounded up to base units.
+func (p *Position) MaintenanceMargin() (*big.Int, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	notional := p.Notional()
+	rate, amount, _, err := p.Market.tier(notional)
+	if err != nil {
+		return nil, err
+	}
+	return p.Market.units(notional.Mul(rate).Sub(amount), true), nil
+}
+
+// UnrealizedPnL returns the profit or loss of closing the position at the mark
+// price, Size * (MarkPrice - EntryPrice) for a long and Size * (EntryPrice -
+// MarkPrice) for a short, rounded down to base units.
+//
+// Example:
+//
+//	// long 1 BTC from 20000 to 19000.0000005 USDT
+//	p.UnrealizedPnL() // output: -1000000000 (-1000 USDT, not -999.9999995)
+func (p *Position) UnrealizedPnL() (*big.Int, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	diff := p.MarkPrice.Sub(p.EntryPrice)
+	if p.Side == Short {
+		diff = diff.Neg()
+	}
+	return p.Market.units(p.Size.Mul(diff), false), nil
+}
+
+// MarginRatio returns the maintenance margin over the margin balance, Margin +
+// UnrealizedPnL, rounded up to RatioPrecision places. The position is
+// liquidated when the ratio reaches 1. Returns ErrBankrupt when the margin
+// balance is not positive.
+func (p *Position) MarginRatio() (safem.Decimal, error) {
+	if err := p.isolatedMargin(); err != nil {
+		return safem.Decimal{}, err
+	}
+	mm, err := p.MaintenanceMargin()
+	if err != nil {
+		return safem.Decimal{}, err
+	}
+	pnl, err := p.UnrealizedPnL()
+	if err != nil {
+		return safem.Decimal{}, err
+	}
+	return ratio(mm, pnl.Add(pnl, p.Margin))
+}
+
+// LiquidationPrice returns the mark price at which the isolated margin balance
+// equals the maintenance margin. For a long, with rate and amount of the tier
+// of the liquidation notional:
+//
+//	price = (Size * EntryPrice - Margin - amount) / (Size * (1 - rate))
+//
+// and for a short:
+//
+//	price = (Size * EntryPrice + Margin + amount) / (Size * (1 + rate))
+//
+// The price of a long is rounded up and that of a short down, to the market's
+// PriceDecimals. Zero means a long that cannot be liquidated because its margin
+// covers the whole position.
+//
+// Example:
+//
+//	// long 1 BTC at 20000 USDT with 2000 USDT margin, 0.4% maintenance rate
+//	p.LiquidationPrice() // output: 18072.29 (18000 / 0.996 = 18072.2891...)
+func (p *Position) LiquidationPrice() (safem.Decimal, error) {
+	if err := p.isolatedMargin(); err != nil {
+		return safem.Decimal{}, err
+	}
+	if err := p.validate(); err != nil {
+		return safem.Decimal{}, err
+	}
+	return p.liquidationPrice(p.Market.tokens(p.Margin).Rat())
+}
+
+// liquidationPrice returns the liquidation price of the position with a margin
+// balance of available at the entry price, in whole quote tokens.
+func (p *Position) liquidationPrice(available *big.Rat) (safem.Decimal, error) {
+	amounts, err := p.Market.MaintenanceAmounts()
+	if err != nil {
+		return safem.Decimal{}, err
+	}
+	size := p.Size.Rat()
+	sizeEntry := new(big.Rat).Mul(size, p.EntryPrice.Rat())
+	one := big.NewRat(1, 1)
+
+	// The tier depends on the liquidation price, so solve in each tier and keep
+	// the solution whose notional falls in it. Margin balance less maintenance
+	// margin is monotonic in the price, so exactly one tier has a solution.
+	floor := new(big.Rat)
+	for i, t := range p.Market.Tiers {
+		rate := t.MaintenanceRate.Rat()
+		var num, den *big.Rat
+		if p.Side == Long {
+			num = new(big.Rat).Sub(sizeEntry, available)
+			num.Sub(num, amounts[i].Rat())
+			den = new(big.Rat).Mul(size, new(big.Rat).Sub(one, rate))
+		} else {
+			num = new(big.Rat).Add(sizeEntry, available)
+			num.Add(num, amounts[i].Rat())
+			den = new(big.Rat).Mul(size, new(big.Rat).Add(one, rate))
+		}
+		price := num.Quo(num, den)
+		if price.Sign() <= 0 {
+			// a short whose cross margin balance is below -Size * EntryPrice
+			if p.Side == Short {
+				return safem.Decimal{}, fmt.Errorf("cannot compute liquidation price: %w", ErrBankrupt)
+			}
+			// a long in the first tier whose margin covers the position
+			return safem.New(0, 0), nil
+		}
+
+		notional := new(big.Rat).Mul(size, price)
+		unbounded := i == len(p.Market.Tiers)-1 && t.MaxNotional.IsZero()
+		if notional.Cmp(floor) > 0 && (unbounded || notional.Cmp(t.MaxNotional.Rat()) <= 0) {
+			return roundRat(price, p.Market.PriceDecimals, p.Side == Long), nil
+		}
+		floor = t.MaxNotional.Rat()
+	}
+	return safem.Decimal{}, fmt.Errorf("cannot compute liquidation price: notional above the last tier: %w", safem.ErrTooLarge)
+}
+
+// BankruptcyPrice returns the mark price at which the isolated margin balance
+// is zero, EntryPrice - Margin / Size for a long and EntryPrice + Margin / Size
+// for a short, rounded up for a long and down for a short to the market's
+// PriceDecimals. A long whose margin covers the whole position has a
+// bankruptcy price of zero.
+func (p *Position) BankruptcyPrice() (safem.Decimal, error) {
+	if err := p.isolatedMargin(); err != nil {
+		return safem.Decimal{}, err
+	}
+	if err := p.validate(); err != nil {
+		return safem.Decimal{}, err
+	}
+	return p.bankruptcyPrice(p.Market.tokens(p.Margin).Rat())
+}
+
+func (p *Position) bankruptcyPrice(available *big.Rat) (safem.Decimal, error) {
+	perUnit := new(big.Rat).Quo(available, p.Size.Rat())
+	price := p.EntryPrice.Rat()
+	if p.Side == Long {
+		price.Sub(price, perUnit)
+		if price.Sign() <= 0 {
+			return safem.New(0, 0), nil
+		}
+	} else {
+		price.Add(price, perUnit)
+		if price.Sign() <= 0 {
+			return safem.Decimal{}, fmt.Errorf("cannot compute bankruptcy price: %w", ErrBankrupt)
+		}
+	}
+	return roundRat(price, p.Market.PriceDecimals, p.Side == Long), nil
+}
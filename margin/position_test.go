@@ -0,0 +1,149 @@
+package margin
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/morpheum-labs/safem"
+)
+
+// usdt returns whole USDT in 6-decimal base units.
+func usdt(s string) *big.Int {
+	return d(s).Shift(6).BigInt()
+}
+
+func position(side Side, size, entry, mark, margin string) *Position {
+	return &Position{
+		Market:     btcMarket(),
+		Side:       side,
+		Size:       d(size),
+		EntryPrice: d(entry),
+		MarkPrice:  d(mark),
+		Margin:     usdt(margin),
+	}
+}
+
+func TestPositionMargin(t *testing.T) {
+	tests := []struct {
+		name        string
+		p           *Position
+		leverage    string
+		initial     string
+		maintenance string
+		pnl         string
+		ratio       string
+	}{
+		// 20000 / 10 = 2000; 20000 * 0.004 = 80; 80 / 2000
+		{"long at entry", position(Long, "1", "20000", "20000", "2000"), "10", "2000000000", "80000000", "0", "0.04"},
+		// 19000 * 0.004 = 76; 76 / (2000 - 1000)
+		{"long at a loss", position(Long, "1", "20000", "19000", "2000"), "10", "1900000000", "76000000", "-1000000000", "0.076"},
+		// 300000 * 0.01 - 1300 = 1700; 1700 / 30000
+		{"short in tier 2", position(Short, "10", "30000", "30000", "30000"), "10", "30000000000", "1700000000", "0", "0.056666666666666667"},
+		// 310000 * 0.01 - 1300 = 1800; 1800 / (30000 - 10000)
+		{"short at a loss", position(Short, "10", "30000", "31000", "30000"), "50", "6200000000", "1800000000", "-10000000000", "0.09"},
+		// 20.0003 / 3 = 6.66676666...; 20.0003 * 0.004 = 0.0800012; 0.001 * -0.0005 = -0.0000005
+		{"rounding", position(Long, "0.001", "20000.3005", "20000.3", "10"), "3", "6666767", "80002", "-1", "0.008000200800020081"},
+	}
+	for _, tt := range tests {
+		im, err := tt.p.InitialMargin(d(tt.leverage))
+		if err != nil || im.String() != tt.initial {
+			t.Errorf("%s: InitialMargin: Expected %s, got %v %v", tt.name, tt.initial, im, err)
+		}
+		mm, err := tt.p.MaintenanceMargin()
+		if err != nil || mm.String() != tt.maintenance {
+			t.Errorf("%s: MaintenanceMargin: Expected %s, got %v %v", tt.name, tt.maintenance, mm, err)
+		}
+		pnl, err := tt.p.UnrealizedPnL()
+		if err != nil || pnl.String() != tt.pnl {
+			t.Errorf("%s: UnrealizedPnL: Expected %s, got %v %v", tt.name, tt.pnl, pnl, err)
+		}
+		ratio, err := tt.p.MarginRatio()
+		if err != nil || ratio.String() != tt.ratio {
+			t.Errorf("%s: MarginRatio: Expected %s, got %s %v", tt.name, tt.ratio, ratio, err)
+		}
+	}
+}
+
+func TestPositionPrices(t *testing.T) {
+	tests := []struct {
+		name        string
+		p           *Position
+		liquidation string
+		bankruptcy  string
+	}{
+		// 18000 / 0.996 = 18072.2891...
+		{"long", position(Long, "1", "20000", "20000", "2000"), "18072.29", "18000"},
+		// (300000 + 30000 + 1300) / 10.1 = 32801.9801...
+		{"short", position(Short, "10", "30000", "30000", "30000"), "32801.98", "33000"},
+		// (20000 + 1000 + 0) / 1.004 = 20916.3346...; 21000 / 1
+		{"short in tier 0", position(Short, "1", "20000", "20000", "1000"), "20916.33", "21000"},
+		// tier 0 gives 50000 / 2.988 = 16733.33, a notional of 50200 outside it;
+		// tier 1 gives (50000 - 50) / 2.985 = 16733.6683...
+		{"long crossing tiers", position(Long, "3", "20000", "20000", "10000"), "16733.67", "16666.67"},
+		// 48000 / 2.988 = 16064.2570..., a notional of 48192 in tier 0
+		{"long in lower tier", position(Long, "3", "20000", "20000", "12000"), "16064.26", "16000"},
+		// margin covering the whole position
+		{"long fully collateralized", position(Long, "1", "20000", "20000", "20000"), "0", "0"},
+		{"long overcollateralized", position(Long, "1", "20000", "20000", "25000"), "0", "0"},
+		// margin below the maintenance margin at entry: 19990 / 0.996 = 20070.2811...
+		{"long under maintenance", position(Long, "1", "20000", "20000", "10"), "20070.29", "19990"},
+	}
+	for _, tt := range tests {
+		liq, err := tt.p.LiquidationPrice()
+		if err != nil || liq.String() != tt.liquidation {
+			t.Errorf("%s: LiquidationPrice: Expected %s, got %s %v", tt.name, tt.liquidation, liq, err)
+		}
+		bankrupt, err := tt.p.BankruptcyPrice()
+		if err != nil || bankrupt.String() != tt.bankruptcy {
+			t.Errorf("%s: BankruptcyPrice: Expected %s, got %s %v", tt.name, tt.bankruptcy, bankrupt, err)
+		}
+	}
+
+	// at the liquidation price the margin ratio reaches 1
+	p := position(Long, "1", "20000", "18072.29", "2000")
+	if ratio, err := p.MarginRatio(); err != nil || ratio.LessThan(d("0.9999")) || ratio.GreaterThan(d("1")) {
+		t.Errorf("Expected a margin ratio just below 1, got %s %v", ratio, err)
+	}
+}
+
+func TestPositionErrors(t *testing.T) {
+	p := position(Short, "10", "30000", "30000", "30000")
+	// 300000 is in tier 2, up to 50x
+	if _, err := p.InitialMargin(d("60")); !errors.Is(err, ErrLeverageTooHigh) {
+		t.Errorf("Expected ErrLeverageTooHigh, got %v", err)
+	}
+	if _, err := p.InitialMargin(d("0.5")); !errors.Is(err, safem.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput, got %v", err)
+	}
+
+	p = position(Long, "1", "20000", "18000", "2000")
+	if _, err := p.MarginRatio(); !errors.Is(err, ErrBankrupt) {
+		t.Errorf("Expected ErrBankrupt, got %v", err)
+	}
+
+	invalid := []struct {
+		name string
+		p    *Position
+		err  error
+	}{
+		{"no market", &Position{Side: Long, Size: d("1"), EntryPrice: d("1"), MarkPrice: d("1"), Margin: usdt("1")}, safem.ErrInvalidInput},
+		{"side", &Position{Market: btcMarket(), Side: 2, Size: d("1"), EntryPrice: d("1"), MarkPrice: d("1"), Margin: usdt("1")}, safem.ErrInvalidInput},
+		{"zero size", position(Long, "0", "20000", "20000", "2000"), safem.ErrInvalidInput},
+		{"negative size", position(Long, "-1", "20000", "20000", "2000"), safem.ErrInvalidInput},
+		{"zero price", position(Long, "1", "0", "20000", "2000"), safem.ErrInvalidInput},
+		{"negative margin", position(Long, "1", "20000", "20000", "-1"), safem.ErrNegativeInput},
+		{"nil margin", &Position{Market: btcMarket(), Side: Long, Size: d("1"), EntryPrice: d("1"), MarkPrice: d("1")}, safem.ErrInvalidInput},
+	}
+	for _, tt := range invalid {
+		if _, err := tt.p.LiquidationPrice(); !errors.Is(err, tt.err) {
+			t.Errorf("%s: LiquidationPrice: Expected %v, got %v", tt.name, tt.err, err)
+		}
+		if _, err := tt.p.BankruptcyPrice(); !errors.Is(err, tt.err) {
+			t.Errorf("%s: BankruptcyPrice: Expected %v, got %v", tt.name, tt.err, err)
+		}
+		if _, err := tt.p.MarginRatio(); !errors.Is(err, tt.err) {
+			t.Errorf("%s: MarginRatio: Expected %v, got %v", tt.name, tt.err, err)
+		}
+	}
+}
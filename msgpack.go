@@ -0,0 +1,196 @@
+package safem
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+
+	"github.com/morpheum-labs/safem/internal/bigint"
+)
+
+// MessagePack extension types used for Decimal and BigInt.
+const (
+	// MsgpackExtDecimal carries the canonical encoding of a Decimal, see
+	// MarshalCanonical.
+	MsgpackExtDecimal int8 = 1
+
+	// MsgpackExtBigInt carries a BigInt as big-endian two's complement using the
+	// minimum number of bytes; zero is empty.
+	MsgpackExtBigInt int8 = 2
+)
+
+// MessagePack format bytes.
+const (
+	msgpackNil    = 0xc0
+	msgpackExt8   = 0xc7
+	msgpackExt16  = 0xc8
+	msgpackExt32  = 0xc9
+	msgpackUint8  = 0xcc
+	msgpackUint16 = 0xcd
+	msgpackUint32 = 0xce
+	msgpackUint64 = 0xcf
+	msgpackInt8   = 0xd0
+	msgpackInt16  = 0xd1
+	msgpackInt32  = 0xd2
+	msgpackInt64  = 0xd3
+	msgpackFixExt = 0xd4 // 0xd4 to 0xd8 for 1, 2, 4, 8 and 16 bytes
+)
+
+// MarshalMsgpack implements the msgpack.Marshaler interface, writing the decimal
+// as an extension of type MsgpackExtDecimal. Trailing zeros are not kept, so 1.50
+// decodes as 1.5.
+func (d Decimal) MarshalMsgpack() ([]byte, error) {
+	return appendMsgpackExt(nil, MsgpackExtDecimal, d.MarshalCanonical()), nil
+}
+
+// UnmarshalMsgpack implements the msgpack.Unmarshaler interface. Besides the
+// MsgpackExtDecimal extension it accepts MessagePack integers.
+func (d *Decimal) UnmarshalMsgpack(data []byte) error {
+	if typ, payload, ok, err := readMsgpackExt(data); ok || err != nil {
+		if err == nil && typ != MsgpackExtDecimal {
+			err = fmt.Errorf("unexpected extension type %d", typ)
+		}
+		if err == nil {
+			err = d.UnmarshalCanonical(payload)
+		}
+		if err != nil {
+			return fmt.Errorf("cannot decode MessagePack decimal: %s", err)
+		}
+		return nil
+	}
+
+	v, err := readMsgpackInt(data)
+	if err != nil {
+		return fmt.Errorf("cannot decode MessagePack decimal: %s", err)
+	}
+	*d = Decimal{value: v}
+	return nil
+}
+
+// MarshalMsgpack implements the msgpack.Marshaler interface, writing the value as
+// an extension of type MsgpackExtBigInt. A nil value is written as nil.
+func (b BigInt) MarshalMsgpack() ([]byte, error) {
+	if b.Int == nil {
+		return []byte{msgpackNil}, nil
+	}
+	return appendMsgpackExt(nil, MsgpackExtBigInt, bigint.TwosComplement(b.Int)), nil
+}
+
+// UnmarshalMsgpack implements the msgpack.Unmarshaler interface. Besides the
+// MsgpackExtBigInt extension it accepts MessagePack integers and nil, which
+// leaves the value nil.
+func (b *BigInt) UnmarshalMsgpack(data []byte) error {
+	if len(data) == 1 && data[0] == msgpackNil {
+		b.Int = nil
+		return nil
+	}
+
+	if typ, payload, ok, err := readMsgpackExt(data); ok || err != nil {
+		if err == nil && typ != MsgpackExtBigInt {
+			err = fmt.Errorf("unexpected extension type %d", typ)
+		}
+		if err != nil {
+			return fmt.Errorf("cannot decode MessagePack integer: %s", err)
+		}
+		b.Int = bigint.FromTwosComplement(payload)
+		return nil
+	}
+
+	v, err := readMsgpackInt(data)
+	if err != nil {
+		return fmt.Errorf("cannot decode MessagePack integer: %s", err)
+	}
+	b.Int = v
+	return nil
+}
+
+// appendMsgpackExt appends an extension, using fixext when the payload size allows.
+func appendMsgpackExt(dst []byte, typ int8, payload []byte) []byte {
+	n := len(payload)
+	switch {
+	case n == 1 || n == 2 || n == 4 || n == 8 || n == 16:
+		dst = append(dst, msgpackFixExt+byte(bits.TrailingZeros(uint(n))), byte(typ))
+	case n <= math.MaxUint8:
+		dst = append(dst, msgpackExt8, byte(n), byte(typ))
+	case n <= math.MaxUint16:
+		dst = binary.BigEndian.AppendUint16(append(dst, msgpackExt16), uint16(n))
+		dst = append(dst, byte(typ))
+	default:
+		dst = binary.BigEndian.AppendUint32(append(dst, msgpackExt32), uint32(n))
+		dst = append(dst, byte(typ))
+	}
+	return append(dst, payload...)
+}
+
+// readMsgpackExt reads data as a single extension. ok is false when data does
+// not start with an extension format byte.
+func readMsgpackExt(data []byte) (typ int8, payload []byte, ok bool, err error) {
+	if len(data) == 0 {
+		return 0, nil, false, fmt.Errorf("empty data")
+	}
+
+	var size, header int
+	switch c := data[0]; {
+	case c >= msgpackFixExt && c <= msgpackFixExt+4:
+		size, header = 1<<(c-msgpackFixExt), 2
+	case c == msgpackExt8 && len(data) >= 3:
+		size, header = int(data[1]), 3
+	case c == msgpackExt16 && len(data) >= 4:
+		size, header = int(binary.BigEndian.Uint16(data[1:])), 4
+	case c == msgpackExt32 && len(data) >= 6:
+		size, header = int(binary.BigEndian.Uint32(data[1:])), 6
+	case c == msgpackExt8 || c == msgpackExt16 || c == msgpackExt32:
+		return 0, nil, true, fmt.Errorf("truncated extension header")
+	default:
+		return 0, nil, false, nil
+	}
+
+	if len(data) < header || len(data)-header != size {
+		return 0, nil, true, fmt.Errorf("extension payload of %d bytes, expected %d", len(data)-header, size)
+	}
+	return int8(data[header-1]), data[header:], true, nil
+}
+
+// readMsgpackInt reads data as a single MessagePack integer of any width.
+func readMsgpackInt(data []byte) (*big.Int, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty data")
+	}
+
+	c := data[0]
+	switch {
+	case c <= 0x7f:
+		return checkMsgpackLen(data, 1, big.NewInt(int64(c)))
+	case c >= 0xe0:
+		return checkMsgpackLen(data, 1, big.NewInt(int64(int8(c))))
+	}
+
+	if c < msgpackUint8 || c > msgpackInt64 {
+		return nil, fmt.Errorf("unexpected format byte 0x%02x", c)
+	}
+	// the uint and int formats each come in widths of 1, 2, 4 and 8 bytes
+	size := 1 << ((c - msgpackUint8) % 4)
+	if len(data) < 1+size {
+		return nil, fmt.Errorf("truncated integer")
+	}
+
+	var u uint64
+	for _, b := range data[1 : 1+size] {
+		u = u<<8 | uint64(b)
+	}
+	if c >= msgpackInt8 {
+		// sign-extend from the encoded width
+		shift := 64 - 8*uint(size)
+		return checkMsgpackLen(data, 1+size, big.NewInt(int64(u<<shift)>>shift))
+	}
+	return checkMsgpackLen(data, 1+size, new(big.Int).SetUint64(u))
+}
+
+func checkMsgpackLen(data []byte, n int, v *big.Int) (*big.Int, error) {
+	if len(data) != n {
+		return nil, fmt.Errorf("%d trailing bytes", len(data)-n)
+	}
+	return v, nil
+}
@@ -0,0 +1,159 @@
+package safem
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+func TestDecimalMarshalMsgpack(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"0", "d5010100"},
+		{"1.5", "d6010105010f"},
+		{"1.50", "d6010105010f"},
+		{"-12.5", "d6010106017d"},
+		{"123456789012345678901234567890.5", "c711010101010d0f951a9fa3a286c94f0e766c39"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			d := RequireFromString(tt.value)
+			data, err := d.MarshalMsgpack()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := hex.EncodeToString(data); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+
+			var back Decimal
+			if err := back.UnmarshalMsgpack(data); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !back.Equal(d) {
+				t.Errorf("Expected %s, got %s", d.String(), back.String())
+			}
+		})
+	}
+}
+
+func TestBigIntMarshalMsgpack(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"0", "c70002"},
+		{"1", "d40201"},
+		{"-1", "d402ff"},
+		{"128", "d5020080"},
+		{"-129", "d502ff7f"},
+		{"1000000000000000000", "d7020de0b6b3a7640000"},
+		{"-340282366920938463463374607431768211456", "c71102ff00000000000000000000000000000000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			x, _ := new(big.Int).SetString(tt.value, 10)
+			data, err := NewBigInt(x).MarshalMsgpack()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := hex.EncodeToString(data); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+
+			var back BigInt
+			if err := back.UnmarshalMsgpack(data); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if back.Int.Cmp(x) != 0 {
+				t.Errorf("Expected %s, got %s", x.String(), back.Int.String())
+			}
+		})
+	}
+}
+
+// The integer encodings below are the ones produced by github.com/vmihailenco/msgpack.
+func TestUnmarshalMsgpackIntegers(t *testing.T) {
+	tests := []struct {
+		data     string
+		expected string
+	}{
+		{"00", "0"},
+		{"7f", "127"},
+		{"e0", "-32"},
+		{"d0fb", "-5"},
+		{"cd012c", "300"},
+		{"d3ffffffffffffffdf", "-33"},
+		{"d3ffffff0000000000", "-1099511627776"},
+		{"cf8000000000000000", "9223372036854775808"},
+		{"cfffffffffffffffff", "18446744073709551615"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			data, _ := hex.DecodeString(tt.data)
+			var b BigInt
+			if err := b.UnmarshalMsgpack(data); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if b.Int.String() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, b.Int.String())
+			}
+			var d Decimal
+			if err := d.UnmarshalMsgpack(data); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if d.String() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, d.String())
+			}
+		})
+	}
+}
+
+func TestBigIntMsgpackNil(t *testing.T) {
+	data, err := BigInt{}.MarshalMsgpack()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hex.EncodeToString(data) != "c0" {
+		t.Errorf("Expected c0, got %x", data)
+	}
+
+	b := NewBigInt(big.NewInt(7))
+	if err := b.UnmarshalMsgpack(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.Int != nil {
+		t.Errorf("Expected nil, got %s", b.Int.String())
+	}
+}
+
+func TestUnmarshalMsgpackErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"empty", ""},
+		{"string", "a161"},
+		{"bigint extension", "d40201"},
+		{"truncated integer", "cd01"},
+		{"trailing bytes", "0101"},
+		{"short extension", "d60101"},
+		{"truncated extension header", "c7"},
+		{"non-canonical payload", "d5010200"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := hex.DecodeString(tt.data)
+			var d Decimal
+			if err := d.UnmarshalMsgpack(data); err == nil {
+				t.Errorf("Expected error, got %s", d.String())
+			}
+		})
+	}
+}
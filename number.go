@@ -1,7 +1,7 @@
 package safem
 
 import (
-	"errors"
+	"fmt"
 	"math"
 	"math/big"
 )
@@ -21,27 +21,16 @@ import (
 // - Slightly higher memory usage (168 B/op) compared to optimized versions
 // - Always uses big.Float for maximum precision
 //
-// Returns an error for invalid inputs (nil, negative) or precision issues.
+// Returns an error for invalid inputs (nil, negative) or precision issues, as a
+// *ConversionError. It is DefaultConversionPolicy.WeiToEther.
 func WeiToEther(wei *big.Int) (float64, error) {
-	if wei == nil {
-		return 0, errors.New("wei value is nil")
-	}
-	if wei.Sign() < 0 {
-		return 0, errors.New("negative wei value is invalid")
-	}
-
-	// Convert Wei to Ether by dividing by 10^18
-	weiFloat := new(big.Float).SetInt(wei)
-	etherFloat := new(big.Float).Quo(weiFloat, big.NewFloat(1e18))
-
-	// Convert to float64 and check accuracy
-	ether, accuracy := etherFloat.Float64()
-	// Allow Below accuracy for very small values (less than 1 Wei precision)
-	if accuracy == big.Above {
-		return 0, errors.New("precision loss during conversion to float64")
-	}
+	return DefaultConversionPolicy.WeiToEther(wei)
+}
 
-	return ether, nil
+// WeiToEtherResult is like WeiToEther but also reports the precision of the result.
+// It is DefaultConversionPolicy.WeiToEtherResult.
+func WeiToEtherResult(wei *big.Int) (ConversionResult, error) {
+	return DefaultConversionPolicy.WeiToEtherResult(wei)
 }
 
 var (
@@ -76,43 +65,15 @@ var (
 // - Threshold: 9.223372036854775807e18 Wei for fast path
 //
 // Uses fast path for small values and big.Float for large values to ensure precision.
+// It is OptimizedConversionPolicy.WeiToEther.
 func WeiToEtherOptimized(wei *big.Int) (float64, error) {
-	if wei == nil {
-		return 0, errors.New("wei value is nil")
-	}
-	if wei.Sign() < 0 {
-		return 0, errors.New("negative wei value is invalid")
-	}
-
-	// Fast path: Check if Wei is small enough to convert directly to float64
-	if wei.IsInt64() {
-		weiInt64 := wei.Int64()
-		// Check if division by 1e18 won't cause overflow
-		// math.MaxInt64 / 1e18 ≈ 9.223372036854775807
-		// So we need to ensure weiInt64 <= 9.223372036854775807 * 1e18
-		// This is approximately 9.223372036854775807e18
-		maxSafeInt64 := int64(9.223372036854775807e18)
-		if weiInt64 <= maxSafeInt64 {
-			result := float64(weiInt64) / 1e18
-			// Check if the result is too small for meaningful precision
-			// Allow zero and values >= 1e-17 (0.01 Wei precision)
-			if result != 0 && result < 1e-17 {
-				return 0, errors.New("precision loss during conversion to float64")
-			}
-			return result, nil
-		}
-	}
-
-	// Use big.Float for large values to ensure precision
-	weiFloat := new(big.Float).SetInt(wei)
-	etherFloat := new(big.Float).Quo(weiFloat, etherDivisor)
-	ether, accuracy := etherFloat.Float64()
-	// Allow Below accuracy for very small values (less than 1 Wei precision)
-	if accuracy == big.Above {
-		return 0, errors.New("precision loss during conversion to float64")
-	}
+	return OptimizedConversionPolicy.WeiToEther(wei)
+}
 
-	return ether, nil
+// WeiToEtherOptimizedResult is like WeiToEtherOptimized but also reports the precision of the result.
+// It is OptimizedConversionPolicy.WeiToEtherResult.
+func WeiToEtherOptimizedResult(wei *big.Int) (ConversionResult, error) {
+	return OptimizedConversionPolicy.WeiToEtherResult(wei)
 }
 
 // WeiToEtherSafe converts Wei to Ether with additional safety checks.
@@ -138,29 +99,15 @@ func WeiToEtherOptimized(wei *big.Int) (float64, error) {
 // - Suitable for regulatory and compliance requirements
 //
 // This version is more conservative and suitable for critical financial calculations.
+// It is SafeConversionPolicy.WeiToEther.
 func WeiToEtherSafe(wei *big.Int) (float64, error) {
-	if wei == nil {
-		return 0, errors.New("wei value is nil")
-	}
-	if wei.Sign() < 0 {
-		return 0, errors.New("negative wei value is invalid")
-	}
-
-	// Check if the value exceeds safe precision limits
-	if wei.Cmp(maxSafeWei) > 0 {
-		return 0, errors.New("wei value too large for precise float64 conversion")
-	}
-
-	// Always use big.Float for maximum precision
-	weiFloat := new(big.Float).SetInt(wei)
-	etherFloat := new(big.Float).Quo(weiFloat, etherDivisor)
-	ether, accuracy := etherFloat.Float64()
-	// Allow Below accuracy for very small values (less than 1 Wei precision)
-	if accuracy == big.Above {
-		return 0, errors.New("precision loss during conversion to float64")
-	}
+	return SafeConversionPolicy.WeiToEther(wei)
+}
 
-	return ether, nil
+// WeiToEtherSafeResult is like WeiToEtherSafe but also reports the precision of the result.
+// It is SafeConversionPolicy.WeiToEtherResult.
+func WeiToEtherSafeResult(wei *big.Int) (ConversionResult, error) {
+	return SafeConversionPolicy.WeiToEtherResult(wei)
 }
 
 // EtherToWei converts Ether (float64) to Wei (big.Int).
@@ -185,13 +132,16 @@ func WeiToEtherSafe(wei *big.Int) (float64, error) {
 // - Returns error for significant precision loss
 // - Handles edge cases like very small Ether values
 //
-// Returns an error for invalid inputs (negative, NaN, Inf) or precision issues.
+// Returns ErrNegativeInput for negative values, ErrInvalidInput for NaN and Inf,
+// and ErrPrecisionLoss when half a Wei or more would be lost.
+// The float is read as its binary value, so 1.005 is 1004999999999999872 Wei;
+// FloatConversionV2.EtherToWei reads it as 1.005.
 func EtherToWei(ether float64) (*big.Int, error) {
 	if math.IsNaN(ether) || math.IsInf(ether, 0) {
-		return nil, errors.New("invalid ether value: NaN or Inf")
+		return nil, fmt.Errorf("cannot convert %g ether to wei: %w", ether, ErrInvalidInput)
 	}
 	if ether < 0 {
-		return nil, errors.New("negative ether value is invalid")
+		return nil, fmt.Errorf("cannot convert %g ether to wei: %w", ether, ErrNegativeInput)
 	}
 
 	// Convert to big.Float and multiply by 10^18
@@ -208,7 +158,7 @@ func EtherToWei(ether float64) (*big.Int, error) {
 		// For very small remainders (less than 1 Wei), we can ignore them
 		remainderWei := new(big.Float).Mul(remainder, big.NewFloat(1e18))
 		if remainderWei.Cmp(big.NewFloat(0.5)) >= 0 {
-			return nil, errors.New("precision loss during conversion to Wei")
+			return nil, fmt.Errorf("cannot convert %g ether to wei: %w", ether, ErrPrecisionLoss)
 		}
 	}
 
@@ -1,6 +1,7 @@
 package safem
 
 import (
+	"errors"
 	"math"
 	"math/big"
 	"testing"
@@ -125,27 +126,28 @@ func TestEtherToWei(t *testing.T) {
 		name     string
 		ether    float64
 		expected *big.Int
-		hasError bool
+		err      error
 	}{
-		{"Zero Ether", 0.0, big.NewInt(0), false},
-		{"1 Ether", 1.0, new(big.Int).Mul(big.NewInt(1), big.NewInt(1e18)), false},
-		{"0.5 Ether", 0.5, new(big.Int).Mul(big.NewInt(5), big.NewInt(1e17)), false},
+		{"Zero Ether", 0.0, big.NewInt(0), nil},
+		{"1 Ether", 1.0, new(big.Int).Mul(big.NewInt(1), big.NewInt(1e18)), nil},
+		{"0.5 Ether", 0.5, new(big.Int).Mul(big.NewInt(5), big.NewInt(1e17)), nil},
 		// Large Ether values may have floating-point precision issues
 		// We'll test with a smaller value that should work reliably
-		{"Small Ether", 0.000000000000123456, big.NewInt(123456), false},
-		{"1 Wei in Ether", 0.000000000000000001, big.NewInt(1), false},
-		{"Negative Ether", -1.0, nil, true},
-		{"NaN", math.NaN(), nil, true},
-		{"Positive Inf", math.Inf(1), nil, true},
-		{"Negative Inf", math.Inf(-1), nil, true},
+		{"Small Ether", 0.000000000000123456, big.NewInt(123456), nil},
+		{"1 Wei in Ether", 0.000000000000000001, big.NewInt(1), nil},
+		{"Negative Ether", -1.0, nil, ErrNegativeInput},
+		{"NaN", math.NaN(), nil, ErrInvalidInput},
+		{"Positive Inf", math.Inf(1), nil, ErrInvalidInput},
+		{"Negative Inf", math.Inf(-1), nil, ErrInvalidInput},
+		{"Half Wei", 1.5e-18, nil, ErrPrecisionLoss},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			result, err := EtherToWei(test.ether)
-			if test.hasError {
-				if err == nil {
-					t.Errorf("Expected error but got none")
+			if test.err != nil {
+				if !errors.Is(err, test.err) {
+					t.Errorf("Expected %v, got %v", test.err, err)
 				}
 			} else {
 				if err != nil {
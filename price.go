@@ -0,0 +1,192 @@
+package safem
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/morpheum-labs/safem/internal/bigint"
+)
+
+// Token is a token by symbol and number of decimals, e.g. {"USDC", 6}.
+type Token struct {
+	Symbol   string
+	Decimals uint8
+}
+
+// PriceSource provides prices between tokens, e.g. an oracle or a cache of
+// quotes.
+type PriceSource interface {
+	// Price returns the price of one whole base token in whole quote tokens,
+	// e.g. 2000 for base "ETH" and quote "USDC". It returns an error wrapping
+	// ErrPriceNotFound when it has no price for the pair.
+	Price(base, quote string) (Decimal, error)
+}
+
+// MemoryPriceSource is a PriceSource backed by a map, for tests and for prices
+// pushed by another component. It is safe for concurrent use.
+//
+// Example:
+//
+//	prices := NewMemoryPriceSource()
+//	prices.Set("ETH", "USDC", RequireFromString("2000"))
+//	prices.Price("ETH", "USDC") // output: 2000
+type MemoryPriceSource struct {
+	mu     sync.RWMutex
+	prices map[[2]string]Decimal
+}
+
+// NewMemoryPriceSource returns an empty MemoryPriceSource.
+func NewMemoryPriceSource() *MemoryPriceSource {
+	return &MemoryPriceSource{prices: make(map[[2]string]Decimal)}
+}
+
+// Set sets the price of one base token in quote tokens. Returns
+// ErrNegativeInput for a negative price.
+func (s *MemoryPriceSource) Set(base, quote string, price Decimal) error {
+	if price.Sign() < 0 {
+		return fmt.Errorf("cannot set price of %s in %s to %s: %w", base, quote, price, ErrNegativeInput)
+	}
+	s.mu.Lock()
+	s.prices[[2]string{base, quote}] = price
+	s.mu.Unlock()
+	return nil
+}
+
+// Price returns the price set for base in quote. It does not derive prices from
+// the inverse pair; ConvertRoute does.
+func (s *MemoryPriceSource) Price(base, quote string) (Decimal, error) {
+	s.mu.RLock()
+	price, ok := s.prices[[2]string{base, quote}]
+	s.mu.RUnlock()
+	if !ok {
+		return Decimal{}, fmt.Errorf("cannot price %s in %s: %w", base, quote, ErrPriceNotFound)
+	}
+	return price, nil
+}
+
+// Convert converts amount base units of a token with fromDecimals to base units
+// of a token with toDecimals at price, the number of whole to tokens per whole
+// from token scaled by 10^priceDecimals. An oracle answer such as Chainlink's
+// 200012345678 with 8 decimals is passed as is; a Decimal price such as
+// 2000.12345678 is passed with priceDecimals 0.
+//
+// The result is computed exactly and rounded once with rounding, so
+// big.ToZero never credits more than the amount is worth.
+//
+// PURPOSE: Value an amount of one token in another without float64
+// USAGE: Collateral valuation, fee conversion, cross-token quotes
+// CRITICAL: Returns ErrNegativeInput for a negative price; amount may be negative
+//
+// Example:
+//
+//	// 1.5 ETH in USDC at 2000.12345678 USD per ETH with 8 decimals
+//	Convert(big.NewInt(1_500000000000000000), 18, 6, NewFromInt(200012345678), 8, big.ToZero)
+//	// output: 3000185185 (3000.185185 USDC)
+func Convert(amount *big.Int, fromDecimals, toDecimals uint8, price Decimal, priceDecimals uint8, rounding big.RoundingMode) (*big.Int, error) {
+	if amount == nil {
+		return nil, fmt.Errorf("cannot convert: amount is nil: %w", ErrInvalidInput)
+	}
+	if price.Sign() < 0 {
+		return nil, fmt.Errorf("cannot convert at price %s: %w", price, ErrNegativeInput)
+	}
+	rate := price.Shift(-int32(priceDecimals)).Rat()
+	return convertAtRate(amount, fromDecimals, toDecimals, rate, rounding), nil
+}
+
+// ConvertInverse is like Convert but with an inverted price: price is the
+// number of whole from tokens per whole to token, e.g. the ETH/USD price when
+// converting USD to ETH. Returns ErrDivisionByZero when price is zero.
+//
+// Example:
+//
+//	// 1 USDC in ETH at 3000 USDC per ETH
+//	ConvertInverse(big.NewInt(1_000000), 6, 18, NewFromInt(3000), 0, big.ToZero)
+//	// output: 333333333333333
+func ConvertInverse(amount *big.Int, fromDecimals, toDecimals uint8, price Decimal, priceDecimals uint8, rounding big.RoundingMode) (*big.Int, error) {
+	if amount == nil {
+		return nil, fmt.Errorf("cannot convert: amount is nil: %w", ErrInvalidInput)
+	}
+	if price.Sign() < 0 {
+		return nil, fmt.Errorf("cannot convert at inverse price %s: %w", price, ErrNegativeInput)
+	}
+	if price.Sign() == 0 {
+		return nil, fmt.Errorf("cannot convert at inverse price 0: %w", ErrDivisionByZero)
+	}
+	rate := new(big.Rat).Inv(price.Shift(-int32(priceDecimals)).Rat())
+	return convertAtRate(amount, fromDecimals, toDecimals, rate, rounding), nil
+}
+
+// ConvertRoute converts amount base units of route[0] to base units of the
+// last token of the route, through every token in between, e.g. WBTC → ETH →
+// USDC. Each hop uses source's price of the pair, or the inverse of the price of
+// the reversed pair when source has none.
+//
+// Prices are multiplied exactly and the result is rounded once, so a route
+// loses no more precision than a single conversion. Returns ErrInvalidInput for
+// a route of fewer than two tokens, ErrPriceNotFound when a hop has no price in
+// either direction and ErrDivisionByZero when an inverted price is zero.
+//
+// Example:
+//
+//	route := []Token{{"WBTC", 8}, {"ETH", 18}, {"USDC", 6}}
+//	ConvertRoute(prices, big.NewInt(10_000000), route, big.ToZero) // 0.1 WBTC in USDC
+func ConvertRoute(source PriceSource, amount *big.Int, route []Token, rounding big.RoundingMode) (*big.Int, error) {
+	if amount == nil {
+		return nil, fmt.Errorf("cannot convert: amount is nil: %w", ErrInvalidInput)
+	}
+	if len(route) < 2 {
+		return nil, fmt.Errorf("cannot convert along a route of %d tokens: %w", len(route), ErrInvalidInput)
+	}
+
+	rate := big.NewRat(1, 1)
+	for i := 1; i < len(route); i++ {
+		hop, err := hopRate(source, route[i-1].Symbol, route[i].Symbol)
+		if err != nil {
+			return nil, err
+		}
+		rate.Mul(rate, hop)
+	}
+	return convertAtRate(amount, route[0].Decimals, route[len(route)-1].Decimals, rate, rounding), nil
+}
+
+// hopRate returns the price of base in quote from source, inverting the price of
+// quote in base when source has no price for base in quote.
+func hopRate(source PriceSource, base, quote string) (*big.Rat, error) {
+	price, err := source.Price(base, quote)
+	if err == nil {
+		if price.Sign() < 0 {
+			return nil, fmt.Errorf("cannot convert at price %s of %s in %s: %w", price, base, quote, ErrNegativeInput)
+		}
+		return price.Rat(), nil
+	}
+	if !errors.Is(err, ErrPriceNotFound) {
+		return nil, err
+	}
+
+	inverse, err := source.Price(quote, base)
+	if err != nil {
+		return nil, err
+	}
+	if inverse.Sign() < 0 {
+		return nil, fmt.Errorf("cannot convert at price %s of %s in %s: %w", inverse, quote, base, ErrNegativeInput)
+	}
+	if inverse.Sign() == 0 {
+		return nil, fmt.Errorf("cannot invert price 0 of %s in %s: %w", quote, base, ErrDivisionByZero)
+	}
+	return new(big.Rat).Inv(inverse.Rat()), nil
+}
+
+// convertAtRate returns amount * 10^(toDecimals - fromDecimals) * rate rounded to
+// an integer with mode.
+func convertAtRate(amount *big.Int, fromDecimals, toDecimals uint8, rate *big.Rat, mode big.RoundingMode) *big.Int {
+	v := new(big.Rat).SetInt(amount)
+	v.Mul(v, rate)
+	if shift := int64(toDecimals) - int64(fromDecimals); shift > 0 {
+		v.Mul(v, new(big.Rat).SetInt(new(big.Int).Exp(tenInt, big.NewInt(shift), nil)))
+	} else if shift < 0 {
+		v.Quo(v, new(big.Rat).SetInt(new(big.Int).Exp(tenInt, big.NewInt(-shift), nil)))
+	}
+	return bigint.QuoRound(v.Num(), v.Denom(), mode)
+}
@@ -0,0 +1,177 @@
+package safem
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		amount                   string
+		fromDecimals, toDecimals uint8
+		price                    string
+		priceDecimals            uint8
+		mode                     big.RoundingMode
+		expected                 string
+	}{
+		// 1000 USDC in ETH at 0.0005 ETH per USDC
+		{"1000000000", 6, 18, "0.0005", 0, big.ToZero, "500000000000000000"},
+		// 1.5 ETH in USDC at 2000.12345678 with 8 decimals: 3000.18518517 USDC
+		{"1500000000000000000", 18, 6, "200012345678", 8, big.ToZero, "3000185185"},
+		{"1500000000000000000", 18, 6, "200012345678", 8, big.AwayFromZero, "3000185186"},
+		{"1500000000000000000", 18, 6, "200012345678", 8, big.ToNearestEven, "3000185185"},
+		{"1500000000000000000", 18, 6, "2000.12345678", 0, big.ToPositiveInf, "3000185186"},
+		{"-1500000000000000000", 18, 6, "200012345678", 8, big.ToZero, "-3000185185"},
+		{"-1500000000000000000", 18, 6, "200012345678", 8, big.ToNegativeInf, "-3000185186"},
+		{"-1500000000000000000", 18, 6, "200012345678", 8, big.ToPositiveInf, "-3000185185"},
+		// halfway cases
+		{"5", 1, 0, "1", 0, big.ToNearestEven, "0"},
+		{"5", 1, 0, "1", 0, big.ToNearestAway, "1"},
+		{"15", 1, 0, "1", 0, big.ToNearestEven, "2"},
+		{"-5", 1, 0, "1", 0, big.ToNearestAway, "-1"},
+		// digits a float64 cannot hold: 123456789123456789123456789 * 1.000000000000000001
+		{"123456789123456789123456789", 18, 18, "1.000000000000000001", 0, big.ToZero, "123456789123456789246913578"},
+		{"123456789", 0, 0, "0", 0, big.ToZero, "0"},
+	}
+	for _, tt := range tests {
+		amount, _ := new(big.Int).SetString(tt.amount, 10)
+		got, err := Convert(amount, tt.fromDecimals, tt.toDecimals, RequireFromString(tt.price), tt.priceDecimals, tt.mode)
+		if err != nil || got.String() != tt.expected {
+			t.Errorf("Convert(%s, %d, %d, %s, %d, %v): Expected %s, got %v %v",
+				tt.amount, tt.fromDecimals, tt.toDecimals, tt.price, tt.priceDecimals, tt.mode, tt.expected, got, err)
+		}
+	}
+
+	if _, err := Convert(nil, 6, 18, NewFromInt(1), 0, big.ToZero); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput, got %v", err)
+	}
+	if _, err := Convert(big.NewInt(1), 6, 18, NewFromInt(-1), 0, big.ToZero); !errors.Is(err, ErrNegativeInput) {
+		t.Errorf("Expected ErrNegativeInput, got %v", err)
+	}
+}
+
+func TestConvertInverse(t *testing.T) {
+	tests := []struct {
+		amount   int64
+		price    string
+		mode     big.RoundingMode
+		expected string
+	}{
+		// 1 USDC in ETH at 3000 USDC per ETH: 0.000333... ETH
+		{1000000, "3000", big.ToZero, "333333333333333"},
+		{1000000, "3000", big.AwayFromZero, "333333333333334"},
+		{1000000, "3000", big.ToNearestEven, "333333333333333"},
+		{1000000, "2000", big.ToZero, "500000000000000"},
+		{-1000000, "3000", big.ToNegativeInf, "-333333333333334"},
+	}
+	for _, tt := range tests {
+		got, err := ConvertInverse(big.NewInt(tt.amount), 6, 18, RequireFromString(tt.price), 0, tt.mode)
+		if err != nil || got.String() != tt.expected {
+			t.Errorf("ConvertInverse(%d, %s, %v): Expected %s, got %v %v", tt.amount, tt.price, tt.mode, tt.expected, got, err)
+		}
+	}
+
+	// 300000000000 with 8 decimals is 3000
+	got, err := ConvertInverse(big.NewInt(1000000), 6, 18, NewFromInt(300000000000), 8, big.ToZero)
+	if err != nil || got.String() != "333333333333333" {
+		t.Errorf("Expected 333333333333333, got %v %v", got, err)
+	}
+	if _, err := ConvertInverse(big.NewInt(1), 6, 18, NewFromInt(0), 0, big.ToZero); !errors.Is(err, ErrDivisionByZero) {
+		t.Errorf("Expected ErrDivisionByZero, got %v", err)
+	}
+}
+
+func testPrices(t *testing.T) *MemoryPriceSource {
+	prices := NewMemoryPriceSource()
+	for _, p := range []struct{ base, quote, price string }{
+		{"WBTC", "ETH", "16.5"},
+		{"ETH", "USDC", "2000"},
+		{"DAI", "USDC", "1.0001"},
+		{"FREE", "USDC", "0"},
+	} {
+		if err := prices.Set(p.base, p.quote, RequireFromString(p.price)); err != nil {
+			t.Fatalf("Set(%s, %s): %v", p.base, p.quote, err)
+		}
+	}
+	return prices
+}
+
+func TestConvertRoute(t *testing.T) {
+	prices := testPrices(t)
+	wbtc, eth, usdc, dai := Token{"WBTC", 8}, Token{"ETH", 18}, Token{"USDC", 6}, Token{"DAI", 18}
+
+	tests := []struct {
+		amount   string
+		route    []Token
+		mode     big.RoundingMode
+		expected string
+	}{
+		// 0.1 WBTC * 16.5 * 2000 = 3300 USDC
+		{"10000000", []Token{wbtc, eth, usdc}, big.ToZero, "3300000000"},
+		// 3300 USDC back to WBTC through inverted prices
+		{"3300000000", []Token{usdc, eth, wbtc}, big.ToZero, "10000000"},
+		// 1 ETH * 2000 / 1.0001 = 1999.8000199980001999800019998... DAI
+		{"1000000000000000000", []Token{eth, usdc, dai}, big.ToZero, "1999800019998000199980"},
+		{"1000000000000000000", []Token{eth, usdc, dai}, big.AwayFromZero, "1999800019998000199981"},
+		// the intermediate token is not rounded to USDC's 6 decimals
+		{"1", []Token{eth, usdc, dai}, big.ToZero, "1999"},
+		{"1", []Token{eth, usdc}, big.ToZero, "0"},
+	}
+	for _, tt := range tests {
+		amount, _ := new(big.Int).SetString(tt.amount, 10)
+		got, err := ConvertRoute(prices, amount, tt.route, tt.mode)
+		if err != nil || got.String() != tt.expected {
+			t.Errorf("ConvertRoute(%s, %v, %v): Expected %s, got %v %v", tt.amount, tt.route, tt.mode, tt.expected, got, err)
+		}
+	}
+
+	errTests := []struct {
+		route []Token
+		err   error
+	}{
+		{[]Token{eth}, ErrInvalidInput},
+		{[]Token{eth, {"DOGE", 8}}, ErrPriceNotFound},
+		{[]Token{usdc, {"FREE", 18}}, ErrDivisionByZero},
+	}
+	for _, tt := range errTests {
+		if _, err := ConvertRoute(prices, big.NewInt(1), tt.route, big.ToZero); !errors.Is(err, tt.err) {
+			t.Errorf("ConvertRoute(%v): Expected %v, got %v", tt.route, tt.err, err)
+		}
+	}
+	if _, err := ConvertRoute(prices, nil, []Token{eth, usdc}, big.ToZero); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestMemoryPriceSource(t *testing.T) {
+	prices := NewMemoryPriceSource()
+	if _, err := prices.Price("ETH", "USDC"); !errors.Is(err, ErrPriceNotFound) {
+		t.Errorf("Expected ErrPriceNotFound, got %v", err)
+	}
+	if err := prices.Set("ETH", "USDC", NewFromInt(-1)); !errors.Is(err, ErrNegativeInput) {
+		t.Errorf("Expected ErrNegativeInput, got %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 100 {
+				prices.Set("ETH", "USDC", NewFromInt(int64(2000+i*j)))
+				prices.Price("ETH", "USDC")
+			}
+		}()
+	}
+	wg.Wait()
+
+	prices.Set("ETH", "USDC", NewFromInt(2000))
+	if p, err := prices.Price("ETH", "USDC"); err != nil || p.String() != "2000" {
+		t.Errorf("Expected 2000, got %s %v", p, err)
+	}
+	if _, err := prices.Price("USDC", "ETH"); !errors.Is(err, ErrPriceNotFound) {
+		t.Errorf("Expected ErrPriceNotFound for the inverse pair, got %v", err)
+	}
+}
@@ -91,35 +91,59 @@ import (
 	"strings"
 )
 
-// Precomputed powers of 10 for common decimals to avoid repeated Exp calls
-// CRITICAL: These are pre-computed at package initialization for performance
-// Bases 14 and 18 are most commonly used in blockchain operations
+// Precomputed powers of 10 for every uint8 number of decimals to avoid repeated Exp calls
+// CRITICAL: These are pre-computed at package initialization and only read afterwards,
+// so conversions are safe to run concurrently with any decimals
 var (
-	pow10Cache = map[int64]*big.Int{
-		14: new(big.Int).Exp(big.NewInt(10), big.NewInt(14), nil), // Common for stablecoins
-		18: new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil), // Ethereum Wei precision
+	pow10Cache      = newPow10Cache()
+	pow10FloatCache = newPow10FloatCache(pow10Cache)
+)
+
+func newPow10Cache() []*big.Int {
+	c := make([]*big.Int, math.MaxUint8+1)
+	for y := range c {
+		c[y] = new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(y)), nil)
 	}
-	pow10FloatCache = map[int64]*big.Float{
-		14: new(big.Float).SetInt(pow10Cache[14]),
-		18: new(big.Float).SetInt(pow10Cache[18]),
+	return c
+}
+
+func newPow10FloatCache(ints []*big.Int) []*big.Float {
+	c := make([]*big.Float, len(ints))
+	for y, k := range ints {
+		c[y] = new(big.Float).SetInt(k)
 	}
-)
+	return c
+}
+
+// pow10Int returns 10^y as a big.Int, from the cache when possible.
+// The result may be shared and must not be modified.
+func pow10Int(y int64) *big.Int {
+	if y >= 0 && y < int64(len(pow10Cache)) {
+		return pow10Cache[y]
+	}
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(y), nil)
+}
 
 // Error definitions for consistent error handling across the package
 // CRITICAL: These errors should be handled by callers to prevent panics
 var (
-	ErrNegativeInput = errors.New("negative input not allowed")
-	ErrTooLarge      = errors.New("value too large for uint64")
-	ErrPrecisionLoss = errors.New("precision loss in conversion")
-	ErrInvalidString = errors.New("invalid string for big.Int")
-	ErrInvalidInput  = errors.New("invalid input value")
+	ErrNegativeInput  = errors.New("negative input not allowed")
+	ErrTooLarge       = errors.New("value too large for uint64")
+	ErrPrecisionLoss  = errors.New("precision loss in conversion")
+	ErrInvalidString  = errors.New("invalid string for big.Int")
+	ErrInvalidInput   = errors.New("invalid input value")
+	ErrDivisionByZero = errors.New("division by zero")
+	ErrNoInverse      = errors.New("no modular inverse")
+	ErrUnitMismatch   = errors.New("units of different chains")
+	ErrPriceNotFound  = errors.New("price not found")
 )
 
 // BigInt2Float converts a big.Int to float64 with specified decimal places
 //
 // PURPOSE: Safe conversion from token units to human-readable amounts
 // USAGE: Converting Wei to ETH, token amounts to display values
-// CRITICAL: Returns error for precision loss or negative inputs
+// CRITICAL: Returns 0 and an error for precision loss or negative inputs; use
+// BigInt2FloatResult to keep the rejected value
 // PERFORMANCE: Optimized to avoid string conversions
 //
 // WHEN TO USE vs number.go:
@@ -133,6 +157,9 @@ var (
 //   - Performance is critical (trading engine, order processing)
 //   - Working exclusively with Ethereum
 //
+// ✅ Use Convert() instead when valuing one token in another at a price; it
+// never goes through float64.
+//
 // Example:
 //
 //	wei := big.NewInt(1500000000000000000) // 1.5 ETH in Wei
@@ -141,6 +168,37 @@ var (
 //	usdc := big.NewInt(1500000) // 1.5 USDC (6 decimals)
 //	usdcFloat, err := BigInt2Float(usdc, 6) // Returns 1.5, nil
 func BigInt2Float(i *big.Int, decimal uint8) (float64, error) {
+	ff, err := bigInt2Float(i, decimal)
+	if err != nil {
+		return 0, err
+	}
+	return ff, nil
+}
+
+// BigInt2FloatResult is like BigInt2Float but also reports the precision of the
+// result, so callers can tell harmless rounding from material drift.
+//
+// PURPOSE: Reconciliation and audit of float64 amounts
+// USAGE: Logging the precision lost per record
+// CRITICAL: On ErrPrecisionLoss the result still describes the rejected float64
+//
+// Example:
+//
+//	r, err := BigInt2FloatResult(big.NewInt(1), 18) // ErrPrecisionLoss
+//	r.Value    // 1e-18
+//	r.Accuracy // big.Above
+//	r.RelErr   // 0.0000000000000000715424240546219245085
+func BigInt2FloatResult(i *big.Int, decimal uint8) (ConversionResult, error) {
+	ff, err := bigInt2Float(i, decimal)
+	if err != nil && err != ErrPrecisionLoss {
+		return ConversionResult{}, err
+	}
+	return newConversionResult(ff, NewFromBigInt(i, -int32(decimal))), err
+}
+
+// bigInt2Float returns the float64 of BigInt2Float, together with ErrPrecisionLoss
+// when it is rejected.
+func bigInt2Float(i *big.Int, decimal uint8) (float64, error) {
 	if i == nil {
 		return 0, ErrInvalidInput
 	}
@@ -148,12 +206,14 @@ func BigInt2Float(i *big.Int, decimal uint8) (float64, error) {
 		return 0, ErrNegativeInput
 	}
 
-	f := BigInt2BigFloat(i, decimal)
+	s := GetScratch()
+	defer s.Release()
+	f := s.GetFloat().Quo(s.GetFloat().SetInt(i), pow10Float(int64(decimal)))
 	ff, acc := f.Float64()
 
 	// Allow Below accuracy for tiny values, but reject Above (precision loss)
 	if acc == big.Above {
-		return 0, ErrPrecisionLoss
+		return ff, ErrPrecisionLoss
 	}
 
 	return ff, nil
@@ -175,19 +235,21 @@ func BigInt2BigFloat(i *big.Int, decimal uint8) *big.Float {
 		return new(big.Float).SetFloat64(0)
 	}
 
+	s := GetScratch()
+	defer s.Release()
+
 	// Use direct big.Float conversion instead of string parsing
-	fi := new(big.Float).SetInt(i)
+	fi := s.GetFloat().SetInt(i)
+	return new(big.Float).Quo(fi, pow10Float(int64(decimal)))
+}
 
-	// Get cached divisor or compute on-the-fly
-	div, ok := pow10FloatCache[int64(decimal)]
-	if !ok {
-		// Compute and cache for future use
-		divInt := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimal)), nil)
-		div = new(big.Float).SetInt(divInt)
-		pow10FloatCache[int64(decimal)] = div
+// pow10Float returns 10^y as a big.Float, from the cache when possible.
+// The result may be shared and must not be modified.
+func pow10Float(y int64) *big.Float {
+	if y >= 0 && y < int64(len(pow10FloatCache)) {
+		return pow10FloatCache[y]
 	}
-
-	return new(big.Float).Quo(fi, div)
+	return new(big.Float).SetInt(pow10Int(y))
 }
 
 // BigFloatFromBigInt converts big.Int to big.Float directly
@@ -306,28 +368,27 @@ func FloatToBigIntBaseX(val float64, y int64) *big.Int {
 		return big.NewInt(0)
 	}
 
-	bigval := new(big.Float).SetFloat64(val)
+	s := GetScratch()
+	defer s.Release()
 
-	// Get cached multiplier or compute on-the-fly
-	k, ok := pow10Cache[y]
-	if !ok {
-		k = new(big.Int).Exp(big.NewInt(10), big.NewInt(y), nil)
-		pow10Cache[y] = k
-	}
+	bigval := s.GetFloat().SetFloat64(val)
+
+	k := pow10Int(y)
 
-	coin := new(big.Float).SetInt(k)
+	coin := s.GetFloat().SetInt(k)
 	bigval.Mul(bigval, coin)
 
 	result := new(big.Int)
 	bigval.Int(result)
 
 	// Validate precision by reconverting and comparing (only log significant losses)
-	check := new(big.Float).Quo(new(big.Float).SetInt(result), coin)
-	if check.Cmp(new(big.Float).SetFloat64(val)) != 0 {
+	fval := s.GetFloat().SetFloat64(val)
+	check := s.GetFloat().Quo(s.GetFloat().SetInt(result), coin)
+	if check.Cmp(fval) != 0 {
 		// Only log if the difference is significant (> 0.1%)
-		diff := new(big.Float).Sub(check, new(big.Float).SetFloat64(val))
+		diff := s.GetFloat().Sub(check, fval)
 		diff.Abs(diff)
-		if diff.Cmp(new(big.Float).Mul(new(big.Float).SetFloat64(val), big.NewFloat(0.001))) > 0 {
+		if diff.Cmp(s.GetFloat().Mul(fval, big.NewFloat(0.001))) > 0 {
 			//	metrics.Warnf("Significant precision loss in FloatToBigIntBaseX: input=%f, y=%d", val, y)
 		}
 	}
@@ -335,6 +396,30 @@ func FloatToBigIntBaseX(val float64, y int64) *big.Int {
 	return result
 }
 
+// FloatToBigIntBaseXResult is like FloatToBigIntBaseX but also reports the
+// precision lost, comparing val with the returned amount scaled by 10^-y.
+//
+// PURPOSE: Reconciliation and audit of float64 inputs
+// USAGE: Logging the precision lost per record
+// CRITICAL: Returns error instead of zero for negative, NaN and Inf inputs
+//
+// Example:
+//
+//	wei, r, err := FloatToBigIntBaseXResult(0.1, 18)
+//	wei        // 100000000000000000
+//	r.Accuracy // big.Above
+//	r.AbsErr   // 0.0000000000000000055511151231257827021181583404541015625
+func FloatToBigIntBaseXResult(val float64, y int64) (*big.Int, ConversionResult, error) {
+	if math.IsNaN(val) || math.IsInf(val, 0) || y < 0 || y > math.MaxInt32 {
+		return nil, ConversionResult{}, ErrInvalidInput
+	}
+	if val < 0 {
+		return nil, ConversionResult{}, ErrNegativeInput
+	}
+	result := FloatToBigIntBaseX(val, y)
+	return result, newConversionResult(val, NewFromBigInt(result, -int32(y))), nil
+}
+
 // FloatToBigIntBaseXPercent converts float64 to big.Int with specified base and divides by 100
 //
 // PURPOSE: Percentage-based conversions
@@ -370,14 +455,17 @@ func FloatToBigIntBaseXPercent(f float64, y int64) *big.Int {
 //	ethAmount := BigIntBaseX(amount, 18) // ETH conversion
 //	usdcAmount := BigIntBaseX(amount, 6)  // USDC conversion
 //	usdtAmount := BigIntBaseX(amount, 14) // USDT conversion
+//
+// BigIntBaseX reads f as its binary value, so 1.005 becomes 1004999999999999872
+// at base 18; FloatConversionV2.BigIntBaseX reads it as 1.005.
 func BigIntBaseX(f float64, y int64) *big.Int {
 	if f < 0 {
 		return big.NewInt(0)
 	}
 
 	// Fast path for small y (<=14) and small f that fits in int64
-	if y <= 14 && f < math.MaxInt64/float64(pow10Cache[y].Int64()) {
-		k := pow10Cache[y].Int64()
+	if y >= 0 && y <= 14 && f < math.MaxInt64/float64(pow10Int(y).Int64()) {
+		k := pow10Int(y).Int64()
 		return big.NewInt(int64(f * float64(k)))
 	}
 
@@ -417,12 +505,7 @@ func UnBaseX(f *big.Int, y int64) *big.Int {
 		return big.NewInt(0)
 	}
 
-	// Get cached divisor or compute on-the-fly
-	k, ok := pow10Cache[y]
-	if !ok {
-		k = new(big.Int).Exp(big.NewInt(10), big.NewInt(y), nil)
-		pow10Cache[y] = k
-	}
+	k := pow10Int(y)
 
 	return new(big.Int).Div(f, k)
 }
@@ -463,17 +546,11 @@ func UnBaseXFloatString(f *big.Int, y int64, show_dec int) string {
 		show_dec = 100
 	}
 
-	flo := new(big.Float).SetInt(f)
+	s := GetScratch()
+	defer s.Release()
 
-	// Get cached divisor or compute on-the-fly
-	div, ok := pow10FloatCache[y]
-	if !ok {
-		divInt := new(big.Int).Exp(big.NewInt(10), big.NewInt(y), nil)
-		div = new(big.Float).SetInt(divInt)
-		pow10FloatCache[y] = div
-	}
-
-	flo.Quo(flo, div)
+	flo := s.GetFloat().SetInt(f)
+	flo.Quo(flo, pow10Float(y))
 	str := flo.Text('f', show_dec)
 
 	// Clean up trailing zeros and ensure proper decimal format
@@ -615,15 +692,21 @@ func UnBase14(f *big.Int) *big.Int {
 //	stateAmount := 5.42323
 //	adjustedAmount := ProcessFloatToDecimalAdjustment(18, stateAmount)
 //	// Returns 5423230000000000000 (5.42323 * 10^18)
+//
+// The float is read as its binary value; see FloatConversionV2 for the shortest
+// decimal representation.
 func ProcessFloatToDecimalAdjustment(decimal64b int, state_amt float64) *big.Int {
 	if state_amt < 0 {
 		return big.NewInt(0)
 	}
 
+	s := GetScratch()
+	defer s.Release()
+
 	// Use native big.Float instead of shopspring/decimal for better performance
-	f := new(big.Float).SetFloat64(state_amt)
-	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimal64b)), nil)
-	powF := new(big.Float).SetInt(pow)
+	f := s.GetFloat().SetFloat64(state_amt)
+	pow := s.Get().Exp(tenInt, s.Get().SetInt64(int64(decimal64b)), nil)
+	powF := s.GetFloat().SetInt(pow)
 	f.Mul(f, powF)
 
 	result := new(big.Int)
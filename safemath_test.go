@@ -72,6 +72,54 @@ func TestBigInt2Float(t *testing.T) {
 	}
 }
 
+func TestBigInt2FloatResult(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    *big.Int
+		decimal  uint8
+		value    float64
+		accuracy big.Accuracy
+		absErr   string
+		relErr   string
+		err      error
+	}{
+		{"exact", big.NewInt(15), 1, 1.5, big.Exact, "0", "0", nil},
+		{"zero", big.NewInt(0), 18, 0, big.Exact, "0", "0", nil},
+		{"rounded down", big.NewInt(1<<53 + 1), 0, 1 << 53, big.Below, "1", "0.00000000000000011102230246251564172", nil},
+		{"rounded up", big.NewInt(1), 18, 1e-18, big.Above,
+			"0.00000000000000000000000000000000007154242405462192450852805618492324772617063644020163337700068950653076171875",
+			"0.0000000000000000715424240546219245085", ErrPrecisionLoss},
+		{"overflow", new(big.Int).Exp(big.NewInt(10), big.NewInt(400), nil), 0, math.Inf(1), big.Above, "0", "0", ErrPrecisionLoss},
+		{"negative", big.NewInt(-1), 0, 0, big.Exact, "0", "0", ErrNegativeInput},
+		{"nil", nil, 0, 0, big.Exact, "0", "0", ErrInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := BigInt2FloatResult(tt.input, tt.decimal)
+			if err != tt.err {
+				t.Errorf("Expected error %v, got %v", tt.err, err)
+			}
+			if r.Value != tt.value || r.Accuracy != tt.accuracy {
+				t.Errorf("Expected %g %s, got %g %s", tt.value, tt.accuracy, r.Value, r.Accuracy)
+			}
+			if r.AbsErr.String() != tt.absErr {
+				t.Errorf("Expected AbsErr %s, got %s", tt.absErr, r.AbsErr.String())
+			}
+			if r.RelErr.String() != tt.relErr {
+				t.Errorf("Expected RelErr %s, got %s", tt.relErr, r.RelErr.String())
+			}
+			if r.Exact != (tt.err == nil && tt.accuracy == big.Exact) {
+				t.Errorf("Expected Exact %v, got %v", !r.Exact, r.Exact)
+			}
+
+			if value, err := BigInt2Float(tt.input, tt.decimal); err == nil && value != r.Value {
+				t.Errorf("Expected BigInt2Float %g, got %g", r.Value, value)
+			}
+		})
+	}
+}
+
 func TestBigInt2BigFloat(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -197,6 +245,12 @@ func TestBigIntBaseX(t *testing.T) {
 			base:     18,
 			expected: func() *big.Int { v, _ := new(big.Int).SetString("1234567890000000000", 10); return v }(),
 		},
+		{
+			name:     "uncommon base 6",
+			input:    1.5,
+			base:     6,
+			expected: big.NewInt(1500000),
+		},
 		{
 			name:     "zero",
 			input:    0.0,
@@ -258,6 +312,47 @@ func TestFloatToBigIntBaseX(t *testing.T) {
 	}
 }
 
+func TestFloatToBigIntBaseXResult(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    float64
+		base     int64
+		expected string
+		accuracy big.Accuracy
+		absErr   string
+		err      error
+	}{
+		{"exact", 1.5, 18, "1500000000000000000", big.Exact, "0", nil},
+		{"float above", 0.1, 18, "100000000000000000", big.Above, "0.0000000000000000055511151231257827021181583404541015625", nil},
+		{"float below", 1.23456789, 18, "1234567890000000000", big.Below, "0.0000000000000001099061819331836886703968048095703125", nil},
+		{"negative", -1, 18, "", big.Exact, "0", ErrNegativeInput},
+		{"NaN", math.NaN(), 18, "", big.Exact, "0", ErrInvalidInput},
+		{"Inf", math.Inf(1), 18, "", big.Exact, "0", ErrInvalidInput},
+		{"negative base", 1, -1, "", big.Exact, "0", ErrInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, r, err := FloatToBigIntBaseXResult(tt.input, tt.base)
+			if err != tt.err {
+				t.Fatalf("Expected error %v, got %v", tt.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if result.String() != tt.expected || r.Accuracy != tt.accuracy || r.AbsErr.String() != tt.absErr {
+				t.Errorf("Expected %s %s %s, got %s %s %s", tt.expected, tt.accuracy, tt.absErr, result, r.Accuracy, r.AbsErr)
+			}
+			if r.Exact != (tt.accuracy == big.Exact) {
+				t.Errorf("Expected Exact %v, got %v", !r.Exact, r.Exact)
+			}
+			if plain := FloatToBigIntBaseX(tt.input, tt.base); plain.Cmp(result) != 0 {
+				t.Errorf("Expected FloatToBigIntBaseX %s, got %s", result, plain)
+			}
+		})
+	}
+}
+
 func TestUnBaseX(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -430,6 +525,7 @@ func TestAPRCalculations(t *testing.T) {
 func BenchmarkBigInt2Float(b *testing.B) {
 	input := new(big.Int).Exp(big.NewInt(10), big.NewInt(20), nil)
 
+	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		BigInt2Float(input, 18)
@@ -444,6 +540,7 @@ func BenchmarkBigIntBaseX(b *testing.B) {
 }
 
 func BenchmarkFloatToBigIntBaseX(b *testing.B) {
+	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		FloatToBigIntBaseX(123.456, 18)
@@ -451,6 +548,7 @@ func BenchmarkFloatToBigIntBaseX(b *testing.B) {
 }
 
 func BenchmarkProcessFloatToDecimalAdjustment(b *testing.B) {
+	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		ProcessFloatToDecimalAdjustment(18, 5.42323)
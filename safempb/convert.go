@@ -0,0 +1,57 @@
+// Package safempb holds the Protocol Buffers messages for safem types and the
+// conversions between them and safem.Decimal and safem.BigInt.
+//
+// The message definitions live in proto/safem/v1 at the root of the repository.
+// decimal.pb.go is generated from them with protoc-gen-go:
+//
+//	protoc -I proto --go_out=. --go_opt=module=github.com/morpheum-labs/safem safem/v1/decimal.proto
+//
+// Conversions for google.type.Decimal are in the googledecimal subpackage.
+package safempb
+
+import (
+	"fmt"
+
+	"github.com/morpheum-labs/safem"
+	"github.com/morpheum-labs/safem/internal/bigint"
+)
+
+// FromDecimal returns the message for d. The exponent is kept as it is, so 1.50
+// and 1.5 produce different messages that convert back to equal decimals.
+//
+// Example:
+//
+//	m := FromDecimal(safem.RequireFromString("-12.5"))
+//	m.GetCoefficient() // output: [0x83]
+//	m.GetExponent()    // output: -1
+func FromDecimal(d safem.Decimal) *Decimal {
+	return &Decimal{
+		Coefficient: bigint.TwosComplement(d.Coefficient()),
+		Exponent:    d.Exponent(),
+	}
+}
+
+// ToDecimal returns the decimal held by x. A nil message is zero.
+func (x *Decimal) ToDecimal() safem.Decimal {
+	return safem.NewFromBigInt(bigint.FromTwosComplement(x.GetCoefficient()), x.GetExponent())
+}
+
+// FromBigInt returns the message for an integer, with exponent 0. A nil BigInt, or
+// one holding a nil value, is zero.
+func FromBigInt(b *safem.BigInt) *Decimal {
+	if b == nil || b.Int == nil {
+		return &Decimal{}
+	}
+	return &Decimal{Coefficient: bigint.TwosComplement(b.Int)}
+}
+
+// ToBigInt returns the integer held by x.
+//
+// Returns error when x has a nonzero fractional part.
+func (x *Decimal) ToBigInt() (*safem.BigInt, error) {
+	d := x.ToDecimal()
+	if !d.IsInteger() {
+		return nil, fmt.Errorf("cannot convert %s to BigInt: value has a fractional part", d.String())
+	}
+	return safem.NewBigInt(d.BigInt()), nil
+}
@@ -0,0 +1,89 @@
+package safempb
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/morpheum-labs/safem"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestDecimalMessageRoundTrip(t *testing.T) {
+	values := []string{"0", "1.50", "-12.5", "1234.5", "-0.000001", "115792089237316195423570985008687907853269984665640564039457.584007913129639935", "1e30"}
+
+	for _, v := range values {
+		t.Run(v, func(t *testing.T) {
+			d := safem.RequireFromString(v)
+			data, err := proto.Marshal(FromDecimal(d))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var m Decimal
+			if err := proto.Unmarshal(data, &m); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := m.ToDecimal()
+			if !got.Equal(d) || got.Exponent() != d.Exponent() {
+				t.Errorf("Expected %s (exp %d), got %s (exp %d)", d.String(), d.Exponent(), got.String(), got.Exponent())
+			}
+		})
+	}
+}
+
+func TestDecimalMessageWireFormat(t *testing.T) {
+	data, err := proto.Marshal(FromDecimal(safem.RequireFromString("1234.5")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// field 1: 12345 = 0x3039; field 2: zigzag(-1) = 1
+	expected := []byte{0x0a, 0x02, 0x30, 0x39, 0x10, 0x01}
+	if !bytes.Equal(data, expected) {
+		t.Errorf("Expected %x, got %x", expected, data)
+	}
+
+	data, _ = proto.Marshal(FromDecimal(safem.New(0, 0)))
+	if len(data) != 0 {
+		t.Errorf("Expected zero to encode to no bytes, got %x", data)
+	}
+}
+
+func TestNilDecimalMessage(t *testing.T) {
+	var m *Decimal
+	if got := m.ToDecimal(); !got.IsZero() {
+		t.Errorf("Expected 0, got %s", got.String())
+	}
+}
+
+func TestBigIntMessage(t *testing.T) {
+	wei, _ := new(big.Int).SetString("-1500000000000000000", 10)
+	m := FromBigInt(safem.NewBigInt(wei))
+	if m.GetExponent() != 0 {
+		t.Errorf("Expected exponent 0, got %d", m.GetExponent())
+	}
+
+	b, err := m.ToBigInt()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.Int.Cmp(wei) != 0 {
+		t.Errorf("Expected %s, got %s", wei.String(), b.Int.String())
+	}
+
+	if got, err := FromDecimal(safem.New(15, 3)).ToBigInt(); err != nil || got.Int.String() != "15000" {
+		t.Errorf("Expected 15000, got %v (%v)", got, err)
+	}
+	if got, err := FromDecimal(safem.RequireFromString("2.000")).ToBigInt(); err != nil || got.Int.String() != "2" {
+		t.Errorf("Expected 2, got %v (%v)", got, err)
+	}
+	if _, err := FromDecimal(safem.RequireFromString("2.5")).ToBigInt(); err == nil {
+		t.Errorf("Expected error for fractional value")
+	}
+	if m := FromBigInt(nil); len(m.GetCoefficient()) != 0 {
+		t.Errorf("Expected zero message for nil BigInt")
+	}
+	if m := FromBigInt(&safem.BigInt{}); len(m.GetCoefficient()) != 0 {
+		t.Errorf("Expected zero message for empty BigInt")
+	}
+}
@@ -0,0 +1,140 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: safem/v1/decimal.proto
+
+package safempb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Decimal is an exact decimal number with the value coefficient * 10^exponent.
+//
+// It is the compact alternative to google.type.Decimal, which carries the value as
+// a string: 1234.5 takes 6 bytes instead of 8, and large integers such as token
+// amounts in base units take less than half the bytes of their decimal string.
+type Decimal struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Big-endian two's-complement coefficient using the minimum number of bytes, as
+	// produced by java.math.BigInteger.toByteArray. Empty means zero.
+	Coefficient []byte `protobuf:"bytes,1,opt,name=coefficient,proto3" json:"coefficient,omitempty"`
+	// Power of ten the coefficient is scaled by; negative for fractional values.
+	// Zigzag encoded, so small negative exponents take a single byte.
+	Exponent      int32 `protobuf:"zigzag32,2,opt,name=exponent,proto3" json:"exponent,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Decimal) Reset() {
+	*x = Decimal{}
+	mi := &file_safem_v1_decimal_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Decimal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Decimal) ProtoMessage() {}
+
+func (x *Decimal) ProtoReflect() protoreflect.Message {
+	mi := &file_safem_v1_decimal_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Decimal.ProtoReflect.Descriptor instead.
+func (*Decimal) Descriptor() ([]byte, []int) {
+	return file_safem_v1_decimal_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Decimal) GetCoefficient() []byte {
+	if x != nil {
+		return x.Coefficient
+	}
+	return nil
+}
+
+func (x *Decimal) GetExponent() int32 {
+	if x != nil {
+		return x.Exponent
+	}
+	return 0
+}
+
+var File_safem_v1_decimal_proto protoreflect.FileDescriptor
+
+const file_safem_v1_decimal_proto_rawDesc = "" +
+	"\n" +
+	"\x16safem/v1/decimal.proto\x12\bsafem.v1\"G\n" +
+	"\aDecimal\x12 \n" +
+	"\vcoefficient\x18\x01 \x01(\fR\vcoefficient\x12\x1a\n" +
+	"\bexponent\x18\x02 \x01(\x11R\bexponentB0Z.github.com/morpheum-labs/safem/safempb;safempbb\x06proto3"
+
+var (
+	file_safem_v1_decimal_proto_rawDescOnce sync.Once
+	file_safem_v1_decimal_proto_rawDescData []byte
+)
+
+func file_safem_v1_decimal_proto_rawDescGZIP() []byte {
+	file_safem_v1_decimal_proto_rawDescOnce.Do(func() {
+		file_safem_v1_decimal_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_safem_v1_decimal_proto_rawDesc), len(file_safem_v1_decimal_proto_rawDesc)))
+	})
+	return file_safem_v1_decimal_proto_rawDescData
+}
+
+var file_safem_v1_decimal_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_safem_v1_decimal_proto_goTypes = []any{
+	(*Decimal)(nil), // 0: safem.v1.Decimal
+}
+var file_safem_v1_decimal_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_safem_v1_decimal_proto_init() }
+func file_safem_v1_decimal_proto_init() {
+	if File_safem_v1_decimal_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_safem_v1_decimal_proto_rawDesc), len(file_safem_v1_decimal_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_safem_v1_decimal_proto_goTypes,
+		DependencyIndexes: file_safem_v1_decimal_proto_depIdxs,
+		MessageInfos:      file_safem_v1_decimal_proto_msgTypes,
+	}.Build()
+	File_safem_v1_decimal_proto = out.File
+	file_safem_v1_decimal_proto_goTypes = nil
+	file_safem_v1_decimal_proto_depIdxs = nil
+}
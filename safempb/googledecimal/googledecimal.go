@@ -0,0 +1,42 @@
+// Package googledecimal converts between safem.Decimal and google.type.Decimal,
+// the message from googleapis/type/decimal.proto.
+//
+// It lives apart from safempb so that only programs using google.type.Decimal
+// depend on the googleapis packages.
+//
+// Example:
+//
+//	msg := googledecimal.FromDecimal(safem.RequireFromString("-12.5"))
+//	msg.GetValue() // output: "-12.5"
+//	d, err := googledecimal.ToDecimal(msg)
+package googledecimal
+
+import (
+	"fmt"
+
+	"github.com/morpheum-labs/safem"
+	"google.golang.org/genproto/googleapis/type/decimal"
+)
+
+// FromDecimal returns the message for d. The value is already in the normalized
+// form the message documents: no '+' sign, no exponent and a leading 0 before
+// the decimal point.
+func FromDecimal(d safem.Decimal) *decimal.Decimal {
+	return &decimal.Decimal{Value: d.String()}
+}
+
+// ToDecimal returns the decimal held by m, whose value may use scientific
+// notation such as "2.5e-3". A nil message, or one with an empty value, is zero.
+//
+// Returns error for values that are not decimal numbers, including NaN and Infinity.
+func ToDecimal(m *decimal.Decimal) (safem.Decimal, error) {
+	value := m.GetValue()
+	if value == "" {
+		return safem.New(0, 0), nil
+	}
+	d, err := safem.NewFromString(value)
+	if err != nil {
+		return safem.Decimal{}, fmt.Errorf("cannot convert google.type.Decimal %q: %s", value, err)
+	}
+	return d, nil
+}
@@ -0,0 +1,46 @@
+package googledecimal
+
+import (
+	"testing"
+
+	"google.golang.org/genproto/googleapis/type/decimal"
+)
+
+func TestGoogleDecimal(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"", "0"},
+		{"0", "0"},
+		{"2.5", "2.5"},
+		{"+2.5", "2.5"},
+		{".5", "0.5"},
+		{"5.", "5"},
+		{"-2.5e8", "-250000000"},
+		{"2.5E-3", "0.0025"},
+		{"1.000", "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			d, err := ToDecimal(&decimal.Decimal{Value: tt.value})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := FromDecimal(d).GetValue(); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	if d, err := ToDecimal(nil); err != nil || !d.IsZero() {
+		t.Errorf("Expected 0 for nil message, got %s %v", d, err)
+	}
+
+	for _, invalid := range []string{"NaN", "Infinity", "1,000", " 1", "1e", "0x10", "--1"} {
+		if _, err := ToDecimal(&decimal.Decimal{Value: invalid}); err == nil {
+			t.Errorf("Expected error for %q", invalid)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package safem
+
+import (
+	"math/big"
+	"sync"
+)
+
+// Scratch is an arena of temporary big.Int and big.Float values for hot loops.
+// Values handed out by Get and GetFloat belong to the Scratch: after Release they
+// are reused by other goroutines, so they must never be returned to callers,
+// stored, or referenced once Release has been called. Compute results into freshly
+// allocated values.
+//
+// A Scratch is not safe for concurrent use. Obtain one per goroutine with
+// GetScratch and Release it when done; a released Scratch must not be used again.
+//
+// Example:
+//
+//	s := GetScratch()
+//	defer s.Release()
+//	t := s.Get().Mul(amount, price)
+//	return new(big.Int).Quo(t, scale)
+type Scratch struct {
+	ints    []*big.Int
+	floats  []*big.Float
+	nInts   int
+	nFloats int
+}
+
+// maxScratchBits bounds the size of a value kept for reuse, so that one huge
+// computation does not pin its memory in the pool.
+const maxScratchBits = 1 << 16
+
+var scratchPool = sync.Pool{
+	New: func() interface{} { return new(Scratch) },
+}
+
+// GetScratch returns a Scratch from the pool.
+func GetScratch() *Scratch {
+	return scratchPool.Get().(*Scratch)
+}
+
+// Get returns a zero *big.Int that is valid until Release.
+func (s *Scratch) Get() *big.Int {
+	if s.nInts == len(s.ints) {
+		s.ints = append(s.ints, new(big.Int))
+	}
+	x := s.ints[s.nInts]
+	s.nInts++
+	return x.SetInt64(0)
+}
+
+// GetFloat returns a zero *big.Float that is valid until Release. Like
+// new(big.Float), it has precision 0 and rounds to nearest even, so the first
+// Set call picks the precision.
+func (s *Scratch) GetFloat() *big.Float {
+	if s.nFloats == len(s.floats) {
+		s.floats = append(s.floats, new(big.Float))
+	}
+	f := s.floats[s.nFloats]
+	s.nFloats++
+	// SetInt64 clears a stored ±Inf, which SetPrec(0) alone keeps, and gives +0;
+	// neither call frees the mantissa buffer
+	return f.SetMode(big.ToNearestEven).SetInt64(0).SetPrec(0)
+}
+
+// Release returns the Scratch and every value it handed out to the pool.
+func (s *Scratch) Release() {
+	for i, x := range s.ints[:s.nInts] {
+		if x.BitLen() > maxScratchBits {
+			s.ints[i] = new(big.Int)
+		}
+	}
+	for i, f := range s.floats[:s.nFloats] {
+		if f.Prec() > maxScratchBits {
+			s.floats[i] = new(big.Float)
+		}
+	}
+	s.nInts, s.nFloats = 0, 0
+	scratchPool.Put(s)
+}
@@ -0,0 +1,248 @@
+package safem
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+)
+
+func TestScratchGetReturnsZeroDistinctValues(t *testing.T) {
+	s := GetScratch()
+	a := s.Get().SetInt64(5)
+	b := s.Get()
+	if a == b {
+		t.Fatalf("Expected distinct values before Release")
+	}
+	if b.Sign() != 0 {
+		t.Errorf("Expected 0, got %s", b.String())
+	}
+	f := s.GetFloat().SetFloat64(-1.5)
+	g := s.GetFloat()
+	if f == g {
+		t.Fatalf("Expected distinct floats before Release")
+	}
+	s.Release()
+
+	// reused values come back in the same state as new ones
+	s = GetScratch()
+	defer s.Release()
+	for i := 0; i < 4; i++ {
+		if x := s.Get(); x.Sign() != 0 {
+			t.Errorf("Expected 0, got %s", x.String())
+		}
+		f := s.GetFloat()
+		if f.Sign() != 0 || f.Signbit() || f.Prec() != 0 || f.Mode() != big.ToNearestEven {
+			t.Errorf("Expected a zero float like new(big.Float), got %s prec %d mode %s", f.String(), f.Prec(), f.Mode())
+		}
+	}
+}
+
+func TestScratchGetFloatClearsInf(t *testing.T) {
+	for _, sign := range []bool{false, true} {
+		s := GetScratch()
+		s.GetFloat().SetInf(sign)
+		s.Release()
+
+		s = GetScratch()
+		if f := s.GetFloat(); f.Sign() != 0 || f.IsInf() || f.Signbit() || f.Prec() != 0 {
+			t.Errorf("Expected a zero float after Inf, got %s prec %d", f.String(), f.Prec())
+		}
+		s.Release()
+	}
+}
+
+func TestScratchReleaseDropsLargeValues(t *testing.T) {
+	s := GetScratch()
+	x := s.Get().Lsh(oneInt, maxScratchBits+1)
+	s.Release()
+
+	for _, v := range s.ints {
+		if v == x {
+			t.Errorf("Expected a value above maxScratchBits not to be kept")
+		}
+	}
+}
+
+// Each goroutine computes into scratch values and copies the result out before
+// Release. Run with -race: shared memory between goroutines or between a result and
+// a released scratch value is reported as a race.
+func TestScratchConcurrentNoAliasing(t *testing.T) {
+	const workers, rounds = 16, 200
+
+	results := make([][]*big.Int, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for r := 0; r < rounds; r++ {
+				s := GetScratch()
+				a := s.Get().SetInt64(int64(w*rounds + r))
+				b := s.Get().Mul(a, a)
+				results[w] = append(results[w], new(big.Int).Add(b, a))
+				// scribble over every scratch value before handing them back
+				a.SetInt64(-1)
+				b.SetInt64(-1)
+				s.Release()
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	for w := range results {
+		for r, got := range results[w] {
+			n := int64(w*rounds + r)
+			if got.Int64() != n*n+n {
+				t.Fatalf("Expected %d, got %s", n*n+n, got.String())
+			}
+		}
+	}
+}
+
+func TestSafemathConcurrentScratch(t *testing.T) {
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for w := 0; w < 16; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for r := 0; r < 100; r++ {
+				// each worker walks its own decimals, most of them outside 14 and 18
+				decimals := uint8([]int{6, 8, 18, 27, 40}[(w+r)%5])
+				wei := new(big.Int).Mul(big.NewInt(int64(w*100+r)), new(big.Int).Exp(tenInt, big.NewInt(int64(decimals)), nil))
+				if f, err := BigInt2Float(wei, decimals); err != nil || f != float64(w*100+r) {
+					errs <- fmt.Errorf("BigInt2Float(%d): expected %d, got %v %v", decimals, w*100+r, f, err)
+					return
+				}
+				// float64 amounts are only exact up to 18 decimals here
+				if decimals <= 18 {
+					if got := FloatToBigIntBaseX(float64(w*100+r), int64(decimals)); got.Cmp(wei) != 0 {
+						errs <- fmt.Errorf("FloatToBigIntBaseX(%d): expected %s, got %s", decimals, wei.String(), got.String())
+						return
+					}
+					if got := BigIntBaseX(float64(w*100+r), int64(decimals)); got.Cmp(wei) != 0 {
+						errs <- fmt.Errorf("BigIntBaseX(%d): expected %s, got %s", decimals, wei.String(), got.String())
+						return
+					}
+				}
+				if got := UnBaseX(wei, int64(decimals)); got.Int64() != int64(w*100+r) {
+					errs <- fmt.Errorf("UnBaseX(%d): expected %d, got %s", decimals, w*100+r, got.String())
+					return
+				}
+				if got := UnBaseXFloatString(wei, int64(decimals), 2); got != fmt.Sprintf("%d.0", w*100+r) {
+					errs <- fmt.Errorf("UnBaseXFloatString(%d): expected %d.0, got %s", decimals, w*100+r, got)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+// Decoded BigInts own their Int: values decoded concurrently must be unaffected
+// by later decodes and by scratch use on other goroutines.
+func TestBigIntUnmarshalJSONOwnsMemory(t *testing.T) {
+	const workers, rounds = 8, 100
+
+	decoded := make([][]BigInt, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for r := 0; r < rounds; r++ {
+				var b BigInt
+				data := []byte(fmt.Sprintf(`"%d000000000000000000000"`, w*rounds+r+1))
+				if err := json.Unmarshal(data, &b); err != nil {
+					t.Error(err)
+					return
+				}
+				decoded[w] = append(decoded[w], b)
+
+				s := GetScratch()
+				s.Get().SetInt64(-1)
+				s.Release()
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	seen := make(map[*big.Int]bool)
+	for w := range decoded {
+		for r, b := range decoded[w] {
+			expected := fmt.Sprintf("%d000000000000000000000", w*rounds+r+1)
+			if b.Int.String() != expected {
+				t.Fatalf("Expected %s, got %s", expected, b.Int.String())
+			}
+			if seen[b.Int] {
+				t.Fatalf("Expected every decoded value to own its Int")
+			}
+			seen[b.Int] = true
+		}
+	}
+}
+
+// mulDivNew and mulDivScratch compute a*b/c + a*c/b, the shape of a price
+// conversion, with heap temporaries and with scratch temporaries.
+func mulDivNew(a, b, c *big.Int) *big.Int {
+	t1 := new(big.Int).Mul(a, b)
+	t1.Quo(t1, c)
+	t2 := new(big.Int).Mul(a, c)
+	t2.Quo(t2, b)
+	return new(big.Int).Add(t1, t2)
+}
+
+func mulDivScratch(a, b, c *big.Int) *big.Int {
+	s := GetScratch()
+	defer s.Release()
+	t1 := s.Get().Mul(a, b)
+	t1.Quo(t1, c)
+	t2 := s.Get().Mul(a, c)
+	t2.Quo(t2, b)
+	return new(big.Int).Add(t1, t2)
+}
+
+func TestMulDivScratchMatchesNew(t *testing.T) {
+	a, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	b := big.NewInt(3000)
+	c, _ := new(big.Int).SetString("1000000000000000000", 10)
+	if got, expected := mulDivScratch(a, b, c), mulDivNew(a, b, c); got.Cmp(expected) != 0 {
+		t.Errorf("Expected %s, got %s", expected.String(), got.String())
+	}
+}
+
+func BenchmarkMulDivNew(b *testing.B) {
+	x, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	y := big.NewInt(3000)
+	z, _ := new(big.Int).SetString("1000000000000000000", 10)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		mulDivNew(x, y, z)
+	}
+}
+
+func BenchmarkMulDivScratch(b *testing.B) {
+	x, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	y := big.NewInt(3000)
+	z, _ := new(big.Int).SetString("1000000000000000000", 10)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		mulDivScratch(x, y, z)
+	}
+}
+
+func BenchmarkScratchGetRelease(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s := GetScratch()
+		s.Get().SetInt64(int64(i))
+		s.GetFloat().SetInt64(int64(i))
+		s.Release()
+	}
+}
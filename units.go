@@ -0,0 +1,237 @@
+package safem
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Unit is a denomination of a chain's native currency. Every unit is
+// 10^Exponent base units of its chain: wei on Ethereum, lamports on Solana,
+// satoshis on Bitcoin and uatom on the Cosmos Hub.
+//
+// Conversions between units are exact and go through Decimal or *big.Int. Units of
+// different chains cannot be converted into each other.
+//
+// Example:
+//
+//	wei, _ := ParseAmount("1.5", Gwei)           // 1500000000
+//	eth := FormatAmount(wei, Ether)              // "0.0000000015"
+//	gwei, _ := ConvertString("0.1", Ether, Gwei) // "100000000"
+type Unit uint8
+
+const (
+	Wei Unit = iota
+	Kwei
+	Mwei
+	Gwei
+	Szabo
+	Finney
+	Ether
+	Lamport
+	SOL
+	Satoshi
+	BTC
+	Uatom
+	ATOM
+)
+
+var unitInfo = [...]struct {
+	name     string
+	exponent int32
+	base     Unit
+	aliases  []string
+}{
+	Wei:     {"wei", 0, Wei, nil},
+	Kwei:    {"kwei", 3, Wei, []string{"babbage"}},
+	Mwei:    {"mwei", 6, Wei, []string{"lovelace"}},
+	Gwei:    {"gwei", 9, Wei, []string{"shannon"}},
+	Szabo:   {"szabo", 12, Wei, []string{"microether"}},
+	Finney:  {"finney", 15, Wei, []string{"milliether"}},
+	Ether:   {"ether", 18, Wei, []string{"eth"}},
+	Lamport: {"lamport", 0, Lamport, []string{"lamports"}},
+	SOL:     {"sol", 9, Lamport, nil},
+	Satoshi: {"satoshi", 0, Satoshi, []string{"satoshis", "sat", "sats"}},
+	BTC:     {"btc", 8, Satoshi, nil},
+	Uatom:   {"uatom", 0, Uatom, nil},
+	ATOM:    {"atom", 6, Uatom, nil},
+}
+
+// String returns the lower-case name of the unit, e.g. "gwei".
+func (u Unit) String() string {
+	if !u.valid() {
+		return "Unit(" + strconv.Itoa(int(u)) + ")"
+	}
+	return unitInfo[u].name
+}
+
+// Exponent returns the power of ten of base units in one u, e.g. 9 for Gwei.
+func (u Unit) Exponent() int32 {
+	if !u.valid() {
+		return 0
+	}
+	return unitInfo[u].exponent
+}
+
+// Base returns the smallest unit of u's chain, e.g. Wei for Ether.
+func (u Unit) Base() Unit {
+	if !u.valid() {
+		return u
+	}
+	return unitInfo[u].base
+}
+
+func (u Unit) valid() bool {
+	return int(u) < len(unitInfo)
+}
+
+// ParseUnit returns the unit with the given name or alias, ignoring case.
+//
+// Example:
+//
+//	ParseUnit("Gwei")    // Gwei
+//	ParseUnit("shannon") // Gwei
+//	ParseUnit("sats")    // Satoshi
+func ParseUnit(s string) (Unit, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	for u, info := range unitInfo {
+		if info.name == name {
+			return Unit(u), nil
+		}
+		for _, alias := range info.aliases {
+			if alias == name {
+				return Unit(u), nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("cannot parse unit %q: %w", s, ErrInvalidInput)
+}
+
+// checkUnits returns the power of ten that converts an amount in from to an
+// amount in to.
+func checkUnits(from, to Unit) (int32, error) {
+	if !from.valid() || !to.valid() {
+		return 0, fmt.Errorf("cannot convert %s to %s: %w", from, to, ErrInvalidInput)
+	}
+	if from.Base() != to.Base() {
+		return 0, fmt.Errorf("cannot convert %s to %s: %w", from, to, ErrUnitMismatch)
+	}
+	return from.Exponent() - to.Exponent(), nil
+}
+
+// ConvertUnit converts amount from one unit to another of the same chain. The
+// result is exact.
+//
+// Example:
+//
+//	ConvertUnit(RequireFromString("21"), Gwei, Ether) // 0.000000021
+func ConvertUnit(amount Decimal, from, to Unit) (Decimal, error) {
+	shift, err := checkUnits(from, to)
+	if err != nil {
+		return Decimal{}, err
+	}
+	return amount.Shift(shift), nil
+}
+
+// ConvertString converts a decimal string from one unit to another of the same
+// chain and returns the result as a decimal string without an exponent.
+//
+// Example:
+//
+//	ConvertString("1.5", Ether, Gwei)  // "1500000000"
+//	ConvertString("250", Satoshi, BTC) // "0.0000025"
+func ConvertString(amount string, from, to Unit) (string, error) {
+	d, err := NewFromString(amount)
+	if err != nil {
+		return "", fmt.Errorf("cannot convert %q: %w", amount, ErrInvalidString)
+	}
+	v, err := ConvertUnit(d, from, to)
+	if err != nil {
+		return "", err
+	}
+	return v.String(), nil
+}
+
+// ParseAmount parses a decimal string in unit and returns the amount in base
+// units of that chain. Returns ErrPrecisionLoss when the amount is not a whole
+// number of base units.
+//
+// Example:
+//
+//	ParseAmount("0.1", Ether)   // 100000000000000000
+//	ParseAmount("1.5", Satoshi) // ErrPrecisionLoss
+func ParseAmount(amount string, unit Unit) (*big.Int, error) {
+	d, err := NewFromString(amount)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse %q: %w", amount, ErrInvalidString)
+	}
+	v, err := ConvertUnit(d, unit, unit.Base())
+	if err != nil {
+		return nil, err
+	}
+	if !v.IsInteger() {
+		return nil, fmt.Errorf("cannot parse %q %s: %w: below one %s", amount, unit, ErrPrecisionLoss, unit.Base())
+	}
+	return v.BigInt(), nil
+}
+
+// FormatAmount returns an amount of base units as a decimal string in unit.
+// Trailing zeros are not written.
+//
+// Example:
+//
+//	FormatAmount(big.NewInt(21000000000000), Ether) // "0.000021"
+func FormatAmount(amount *big.Int, unit Unit) string {
+	return FromBaseUnits(amount, unit).String()
+}
+
+// FromBaseUnits returns an amount of base units as an exact Decimal in unit. A
+// nil amount is zero.
+func FromBaseUnits(amount *big.Int, unit Unit) Decimal {
+	if amount == nil {
+		return New(0, 0)
+	}
+	return NewFromBigInt(amount, -unit.Exponent())
+}
+
+// UnitToFloat64Lossy returns an amount of base units as a float64 in unit. When
+// the float64 is not exactly the amount, the nearest float64 is returned together
+// with ErrPrecisionLoss; callers that accept rounding may ignore that error.
+//
+// Example:
+//
+//	UnitToFloat64Lossy(big.NewInt(1500000000), Gwei) // 1.5, nil
+//	UnitToFloat64Lossy(big.NewInt(1), Ether)         // 1e-18, ErrPrecisionLoss
+func UnitToFloat64Lossy(amount *big.Int, unit Unit) (float64, error) {
+	if amount == nil {
+		return 0, ErrInvalidInput
+	}
+	f, exact := FromBaseUnits(amount, unit).Float64()
+	if !exact {
+		return f, ErrPrecisionLoss
+	}
+	return f, nil
+}
+
+// UnitFromFloat64Lossy converts a float64 amount in unit to base units. The
+// float64 is read as its shortest decimal representation, so 0.1 Ether is exactly
+// 100000000000000000 wei. When that representation has digits below one base
+// unit, the amount truncated towards zero is returned together with
+// ErrPrecisionLoss.
+//
+// Returns ErrInvalidInput for NaN and infinities.
+func UnitFromFloat64Lossy(amount float64, unit Unit) (*big.Int, error) {
+	if math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return nil, ErrInvalidInput
+	}
+	v, err := ConvertUnit(shortestFromFloat64(amount), unit, unit.Base())
+	if err != nil {
+		return nil, err
+	}
+	if !v.IsInteger() {
+		return v.Truncate(0).BigInt(), ErrPrecisionLoss
+	}
+	return v.BigInt(), nil
+}
@@ -0,0 +1,203 @@
+package safem
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestUnitExponents(t *testing.T) {
+	tests := []struct {
+		unit     Unit
+		name     string
+		exponent int32
+		base     Unit
+	}{
+		{Wei, "wei", 0, Wei},
+		{Kwei, "kwei", 3, Wei},
+		{Mwei, "mwei", 6, Wei},
+		{Gwei, "gwei", 9, Wei},
+		{Szabo, "szabo", 12, Wei},
+		{Finney, "finney", 15, Wei},
+		{Ether, "ether", 18, Wei},
+		{Lamport, "lamport", 0, Lamport},
+		{SOL, "sol", 9, Lamport},
+		{Satoshi, "satoshi", 0, Satoshi},
+		{BTC, "btc", 8, Satoshi},
+		{Uatom, "uatom", 0, Uatom},
+		{ATOM, "atom", 6, Uatom},
+	}
+
+	for _, tt := range tests {
+		if tt.unit.String() != tt.name {
+			t.Errorf("Expected %s, got %s", tt.name, tt.unit.String())
+		}
+		if tt.unit.Exponent() != tt.exponent {
+			t.Errorf("%s: Expected exponent %d, got %d", tt.name, tt.exponent, tt.unit.Exponent())
+		}
+		if tt.unit.Base() != tt.base {
+			t.Errorf("%s: Expected base %s, got %s", tt.name, tt.base, tt.unit.Base())
+		}
+		if u, err := ParseUnit(tt.name); err != nil || u != tt.unit {
+			t.Errorf("ParseUnit(%s): Expected %s, got %s %v", tt.name, tt.unit, u, err)
+		}
+	}
+
+	if Unit(200).String() != "Unit(200)" {
+		t.Errorf("Expected Unit(200), got %s", Unit(200).String())
+	}
+}
+
+func TestParseUnitAliases(t *testing.T) {
+	tests := map[string]Unit{
+		"ETH":      Ether,
+		" Gwei ":   Gwei,
+		"shannon":  Gwei,
+		"lamports": Lamport,
+		"sats":     Satoshi,
+		"SOL":      SOL,
+	}
+	for s, expected := range tests {
+		if u, err := ParseUnit(s); err != nil || u != expected {
+			t.Errorf("ParseUnit(%q): Expected %s, got %s %v", s, expected, u, err)
+		}
+	}
+	if _, err := ParseUnit("doge"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestConvertString(t *testing.T) {
+	tests := []struct {
+		amount   string
+		from, to Unit
+		expected string
+	}{
+		{"1", Ether, Wei, "1000000000000000000"},
+		{"0.1", Ether, Wei, "100000000000000000"},
+		{"1.5", Ether, Gwei, "1500000000"},
+		{"21", Gwei, Ether, "0.000000021"},
+		{"1", Wei, Ether, "0.000000000000000001"},
+		{"3", Finney, Szabo, "3000"},
+		{"-2.5", Szabo, Gwei, "-2500"},
+		{"115792089237316195423570985008687907853269984665640564039457584007913129639935", Wei, Ether,
+			"115792089237316195423570985008687907853269984665640564039457.584007913129639935"},
+		{"250", Satoshi, BTC, "0.0000025"},
+		{"21000000", BTC, Satoshi, "2100000000000000"},
+		{"0.000000001", SOL, Lamport, "1"},
+		{"1.25", ATOM, Uatom, "1250000"},
+		{"1e3", Gwei, Kwei, "1000000000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.amount+" "+tt.from.String()+" to "+tt.to.String(), func(t *testing.T) {
+			got, err := ConvertString(tt.amount, tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestConvertUnitErrors(t *testing.T) {
+	if _, err := ConvertString("1", Ether, Lamport); !errors.Is(err, ErrUnitMismatch) {
+		t.Errorf("Expected ErrUnitMismatch, got %v", err)
+	}
+	if _, err := ConvertString("1", BTC, ATOM); !errors.Is(err, ErrUnitMismatch) {
+		t.Errorf("Expected ErrUnitMismatch, got %v", err)
+	}
+	if _, err := ConvertString("1", Unit(99), Wei); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput, got %v", err)
+	}
+	if _, err := ConvertString("1,5", Ether, Wei); !errors.Is(err, ErrInvalidString) {
+		t.Errorf("Expected ErrInvalidString, got %v", err)
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		amount   string
+		unit     Unit
+		expected string
+		err      error
+	}{
+		{"1.5", Gwei, "1500000000", nil},
+		{"0.000000000000000001", Ether, "1", nil},
+		{"0.0000000000000000015", Ether, "", ErrPrecisionLoss},
+		{"1.5", Satoshi, "", ErrPrecisionLoss},
+		{"0.00000001", BTC, "1", nil},
+		{"2.000", Wei, "2", nil},
+		{"abc", Wei, "", ErrInvalidString},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAmount(tt.amount, tt.unit)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("ParseAmount(%s, %s): Expected error %v, got %v", tt.amount, tt.unit, tt.err, err)
+			continue
+		}
+		if err == nil && got.String() != tt.expected {
+			t.Errorf("ParseAmount(%s, %s): Expected %s, got %s", tt.amount, tt.unit, tt.expected, got.String())
+		}
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		amount   *big.Int
+		unit     Unit
+		expected string
+	}{
+		{big.NewInt(21000000000000), Ether, "0.000021"},
+		{big.NewInt(1500000000), Gwei, "1.5"},
+		{big.NewInt(-1), Finney, "-0.000000000000001"},
+		{big.NewInt(123456789), BTC, "1.23456789"},
+		{nil, Ether, "0"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatAmount(tt.amount, tt.unit); got != tt.expected {
+			t.Errorf("Expected %s, got %s", tt.expected, got)
+		}
+	}
+}
+
+func TestUnitFloat64Lossy(t *testing.T) {
+	f, err := UnitToFloat64Lossy(big.NewInt(1500000000), Gwei)
+	if err != nil || f != 1.5 {
+		t.Errorf("Expected 1.5, got %v %v", f, err)
+	}
+	f, err = UnitToFloat64Lossy(big.NewInt(1), Ether)
+	if !errors.Is(err, ErrPrecisionLoss) || f != 1e-18 {
+		t.Errorf("Expected 1e-18 with ErrPrecisionLoss, got %v %v", f, err)
+	}
+	if _, err := UnitToFloat64Lossy(nil, Ether); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput, got %v", err)
+	}
+
+	tests := []struct {
+		amount   float64
+		unit     Unit
+		expected string
+		err      error
+	}{
+		{0.1, Ether, "100000000000000000", nil},
+		{1.1, Ether, "1100000000000000000", nil},
+		{30.5, Gwei, "30500000000", nil},
+		{1e-19, Ether, "0", ErrPrecisionLoss},
+		{1.5, Satoshi, "1", ErrPrecisionLoss},
+		{-0.25, SOL, "-250000000", nil},
+	}
+	for _, tt := range tests {
+		got, err := UnitFromFloat64Lossy(tt.amount, tt.unit)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("UnitFromFloat64Lossy(%v, %s): Expected error %v, got %v", tt.amount, tt.unit, tt.err, err)
+		}
+		if got == nil || got.String() != tt.expected {
+			t.Errorf("UnitFromFloat64Lossy(%v, %s): Expected %s, got %v", tt.amount, tt.unit, tt.expected, got)
+		}
+	}
+}